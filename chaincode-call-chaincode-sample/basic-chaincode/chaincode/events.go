@@ -0,0 +1,171 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+	"github.com/hyperledger/fabric-contract-api-go/v2/metadata"
+)
+
+// changeSetEvent is the name of the single chaincode event set by every transaction that changes the ledger
+const changeSetEvent = "ChangeSet"
+
+// Types of Change
+const (
+	changeCreated = "created"
+	changeUpdated = "updated"
+	changeDeleted = "deleted"
+)
+
+// changeSetDescription documents the event emitted by the contract in its metadata
+const changeSetDescription = `Manages assets, users, their tokens and shares.
+Fabric keeps one chaincode event per transaction, so every transaction that changes the ledger
+sets a single "ChangeSet" event whose payload is a JSON object with
+tx_id (string), the transaction;
+invoker_msp (string), the MSP ID of the invoking client;
+changes (array), one entry per record changed by the transaction, with
+type ("created", "updated" or "deleted"), entity (e.g. "Asset", "User", "Nft", "Share"),
+id (the key attributes of the record joined with "/"),
+before (the record before the transaction, omitted when created) and
+after (the record after the transaction, omitted when deleted);
+events (array), the domain events of the transaction in order, each with
+name (e.g. "Transfer", "Approval", "ShareTransfer", "TransferOffer") and payload.
+Fabric only keeps the event of the chaincode the client invoked, so functions called from another
+chaincode, such as TransferAsset and Approve called by the marketplace of invoker-chaincode,
+emit no ChangeSet; listen to the events of the calling chaincode for those transactions.`
+
+// Change is a record created, updated or deleted by a transaction
+type Change struct {
+	Type   string          `json:"type"`
+	Entity string          `json:"entity"`
+	ID     string          `json:"id"`
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// Event is a named domain event of a transaction, such as a token Transfer
+type Event struct {
+	Name    string          `json:"name"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// ChangeSet is the payload of the "ChangeSet" event, batching every change and event of a transaction
+type ChangeSet struct {
+	TxID       string    `json:"tx_id"`
+	InvokerMSP string    `json:"invoker_msp"`
+	Changes    []*Change `json:"changes"`
+	Events     []*Event  `json:"events"`
+}
+
+// TransactionContext is the transaction context of SmartContract.
+// It collects the changes and events of the transaction, which are emitted together once it succeeds.
+type TransactionContext struct {
+	contractapi.TransactionContext
+	changes []*Change
+	events  []*Event
+}
+
+// recordChange adds the change of a record from before to after, either of which is nil if the record
+// does not exist. Changes of a record already changed by the transaction are merged into one change
+// from its original to its latest value, as GetState does not return the writes of the transaction.
+func (ctx *TransactionContext) recordChange(entity string, attributes []string, before []byte, after []byte) {
+	id := strings.Join(attributes, "/")
+	for _, change := range ctx.changes {
+		if change.Entity == entity && change.ID == id {
+			change.After = after
+			change.Type = changeType(change.Before, after)
+			return
+		}
+	}
+
+	ctx.changes = append(ctx.changes, &Change{Type: changeType(before, after), Entity: entity, ID: id, Before: before, After: after})
+}
+
+// changeType classifies the change of a record from before to after, empty if it exists in neither
+func changeType(before []byte, after []byte) string {
+	switch {
+	case before == nil && after == nil:
+		return ""
+	case before == nil:
+		return changeCreated
+	case after == nil:
+		return changeDeleted
+	default:
+		return changeUpdated
+	}
+}
+
+// recordChange adds a change to the change set of the transaction
+func recordChange(ctx contractapi.TransactionContextInterface, entity string, attributes []string, before []byte, after []byte) {
+	if txCtx, ok := ctx.(*TransactionContext); ok {
+		txCtx.recordChange(entity, attributes, before, after)
+	}
+}
+
+// emitEvent marshals payload and adds it to the events of the transaction,
+// which are set as part of its change set once the transaction succeeds
+func emitEvent(ctx contractapi.TransactionContextInterface, name string, payload interface{}) error {
+	eventJSON, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	if txCtx, ok := ctx.(*TransactionContext); ok {
+		txCtx.events = append(txCtx.events, &Event{Name: name, Payload: eventJSON})
+	}
+
+	return nil
+}
+
+// emitChangeSet sets the change set of a transaction as its chaincode event.
+// Transactions that change nothing, such as queries, set no event.
+func emitChangeSet(ctx *TransactionContext) error {
+	changeSet := ChangeSet{TxID: ctx.GetStub().GetTxID(), Changes: []*Change{}, Events: ctx.events}
+	for _, change := range ctx.changes {
+		// A record created and deleted by the same transaction leaves no change
+		if change.Type != "" {
+			changeSet.Changes = append(changeSet.Changes, change)
+		}
+	}
+	if len(changeSet.Changes) == 0 && len(changeSet.Events) == 0 {
+		return nil
+	}
+	if changeSet.Events == nil {
+		changeSet.Events = []*Event{}
+	}
+
+	invokerMSP, err := getClientMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	changeSet.InvokerMSP = invokerMSP
+
+	changeSetJSON, err := json.Marshal(changeSet)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	err = ctx.GetStub().SetEvent(changeSetEvent, changeSetJSON)
+	if err != nil {
+		return fmt.Errorf("failed to set event: %v", err)
+	}
+
+	return nil
+}
+
+// GetTransactionContextHandler returns the context collecting the change set of each transaction
+func (s *SmartContract) GetTransactionContextHandler() contractapi.SettableTransactionContextInterface {
+	return new(TransactionContext)
+}
+
+// GetAfterTransaction returns the hook emitting the change set of each successful transaction
+func (s *SmartContract) GetAfterTransaction() interface{} {
+	return emitChangeSet
+}
+
+// GetInfo returns the contract information, documenting the event it emits
+func (s *SmartContract) GetInfo() metadata.InfoMetadata {
+	return metadata.InfoMetadata{Title: chaincodeName, Description: changeSetDescription}
+}
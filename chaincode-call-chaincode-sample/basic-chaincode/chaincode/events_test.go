@@ -0,0 +1,145 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// changeSetOf returns the change set emitted by the last transaction, nil if it set no event
+func changeSetOf(t *testing.T, stub *mockStub) *ChangeSet {
+	t.Helper()
+
+	if stub.event == nil {
+		return nil
+	}
+	if stub.event.name != changeSetEvent {
+		t.Fatalf("got event %s, want %s", stub.event.name, changeSetEvent)
+	}
+
+	var changeSet ChangeSet
+	err := json.Unmarshal(stub.event.payload, &changeSet)
+	if err != nil {
+		t.Fatalf("failed to unmarshal change set: %v", err)
+	}
+	return &changeSet
+}
+
+// eventNames returns the names of the domain events of the last transaction, joined with ","
+func eventNames(t *testing.T, stub *mockStub) string {
+	t.Helper()
+
+	changeSet := changeSetOf(t, stub)
+	if changeSet == nil {
+		return ""
+	}
+
+	var names []string
+	for _, event := range changeSet.Events {
+		names = append(names, event.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+// changesOf returns the changes of the last transaction as "type entity id", joined with ","
+func changesOf(t *testing.T, stub *mockStub) string {
+	t.Helper()
+
+	changeSet := changeSetOf(t, stub)
+	if changeSet == nil {
+		return ""
+	}
+
+	var changes []string
+	for _, change := range changeSet.Changes {
+		changes = append(changes, change.Type+" "+change.Entity+" "+change.ID)
+	}
+	return strings.Join(changes, ",")
+}
+
+func TestChangeSet(t *testing.T) {
+	contract := &SmartContract{}
+	alice := newIdentity("alice", "Org1MSP")
+	bob := newIdentity("bob", "Org2MSP")
+	stub := newMockStub()
+
+	mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.CreateAsset(ctx, "asset1", "blue", 5, 300)
+	})
+	changeSet := changeSetOf(t, stub)
+	if changeSet.TxID != stub.GetTxID() || changeSet.InvokerMSP != alice.mspID {
+		t.Fatalf("got change set of %s by %s, want %s by %s", changeSet.TxID, changeSet.InvokerMSP, stub.GetTxID(), alice.mspID)
+	}
+	if changes := changesOf(t, stub); changes != "created Asset asset1,created Nft asset1" {
+		t.Fatalf("got changes %q", changes)
+	}
+	if changeSet.Changes[0].Before != nil || changeSet.Changes[0].After == nil {
+		t.Fatalf("got a created change from %s to %s", changeSet.Changes[0].Before, changeSet.Changes[0].After)
+	}
+
+	// A transfer emits the Transfer event and the changes of the asset and its token in one event
+	mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.TransferAsset(ctx, "asset1", bob.mspID, bob.id)
+		return err
+	})
+	if changes := changesOf(t, stub); changes != "updated Nft asset1,updated Asset asset1" {
+		t.Fatalf("got changes %q", changes)
+	}
+	if names := eventNames(t, stub); names != "Transfer" {
+		t.Fatalf("got events %q, want Transfer", names)
+	}
+
+	mustInvoke(t, stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.DeleteAsset(ctx, "asset1")
+	})
+	changeSet = changeSetOf(t, stub)
+	if changes := changesOf(t, stub); changes != "deleted Asset asset1,deleted Nft asset1" {
+		t.Fatalf("got changes %q", changes)
+	}
+	if changeSet.Changes[0].Before == nil || changeSet.Changes[0].After != nil {
+		t.Fatalf("got a deleted change from %s to %s", changeSet.Changes[0].Before, changeSet.Changes[0].After)
+	}
+
+	// Queries change nothing and set no event
+	mustInvoke(t, stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.GetAllAssets(ctx)
+		return err
+	})
+	if stub.event != nil {
+		t.Fatalf("got event %s from a query", stub.event.name)
+	}
+}
+
+func TestChangeSetMergesChangesOfARecord(t *testing.T) {
+	alice := newIdentity("alice", "Org1MSP")
+	stub := newMockStub()
+
+	// Saving a record twice yields one change from its original to its latest value
+	mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		err := users.Save(ctx, &User{ID: "user1", DetailsHash: "hash1"})
+		if err != nil {
+			return err
+		}
+		return users.Save(ctx, &User{ID: "user1", DetailsHash: "hash2"})
+	})
+	changeSet := changeSetOf(t, stub)
+	if changes := changesOf(t, stub); changes != "created User user1" {
+		t.Fatalf("got changes %q", changes)
+	}
+	if !strings.Contains(string(changeSet.Changes[0].After), "hash2") {
+		t.Fatalf("got change to %s, want the latest value", changeSet.Changes[0].After)
+	}
+
+	// A record created and deleted by the same transaction leaves no change
+	ctx := &TransactionContext{}
+	ctx.recordChange("User", []string{"user2"}, nil, []byte(`{"id":"user2"}`))
+	ctx.recordChange("User", []string{"user2"}, nil, nil)
+	if len(ctx.changes) != 1 {
+		t.Fatalf("got %d changes, want the changes of user2 merged into one", len(ctx.changes))
+	}
+	if ctx.changes[0].Type != "" {
+		t.Fatalf("got a change of type %q, want none", ctx.changes[0].Type)
+	}
+}
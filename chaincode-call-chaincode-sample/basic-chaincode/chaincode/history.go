@@ -0,0 +1,143 @@
+package chaincode
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+	"github.com/hyperledger/fabric-protos-go-apiv2/ledger/queryresult"
+)
+
+// FieldChange is a field whose value differs between two consecutive versions of an entity.
+// From is empty for a field that was set for the first time and To is empty for a deleted entity.
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// revision is one version of an entity as recorded by the history database
+type revision[T Entity] struct {
+	TxID      string
+	Timestamp string
+	IsDelete  bool
+	Value     *T
+	Changes   []FieldChange
+}
+
+// History returns every version of the entity with the given key attributes, oldest first,
+// each with the fields changed since the version before it.
+// Deleted versions have a nil Value. The peers must keep the history database enabled.
+// Versions written under legacy keys before MigrateKeys are not included.
+func (r *Repository[T]) History(ctx contractapi.TransactionContextInterface, attributes ...string) ([]*revision[T], error) {
+	key, err := r.key(ctx, attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsIterator, err := ctx.GetStub().GetHistoryForKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history of %s: %v", key, err)
+	}
+	defer resultsIterator.Close()
+
+	// The history database returns the newest version first
+	var modifications []*queryresult.KeyModification
+	for resultsIterator.HasNext() {
+		modification, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		modifications = append([]*queryresult.KeyModification{modification}, modifications...)
+	}
+
+	var revisions []*revision[T]
+	var previous *T
+	for _, modification := range modifications {
+		var value *T
+		if !modification.IsDelete {
+			value = new(T)
+			err = json.Unmarshal(modification.Value, value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to unmarshal %s data of transaction %s: %v", r.table, modification.TxId, err)
+			}
+		}
+
+		changes, err := diffFields(previous, value)
+		if err != nil {
+			return nil, err
+		}
+
+		revisions = append(revisions, &revision[T]{
+			TxID:      modification.TxId,
+			Timestamp: modification.Timestamp.AsTime().UTC().Format(time.RFC3339Nano),
+			IsDelete:  modification.IsDelete,
+			Value:     value,
+			Changes:   changes,
+		})
+		previous = value
+	}
+
+	return revisions, nil
+}
+
+// diffFields compares the JSON fields of two versions of an entity, either of which may be nil
+func diffFields[T any](before *T, after *T) ([]FieldChange, error) {
+	beforeFields, err := jsonFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := jsonFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool)
+	for name := range beforeFields {
+		names[name] = true
+	}
+	for name := range afterFields {
+		names[name] = true
+	}
+
+	changes := []FieldChange{}
+	for name := range names {
+		if beforeFields[name] != afterFields[name] {
+			changes = append(changes, FieldChange{Field: name, From: beforeFields[name], To: afterFields[name]})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+
+	return changes, nil
+}
+
+// jsonFields returns the JSON fields of entity formatted as strings, or no fields if entity is nil
+func jsonFields[T any](entity *T) (map[string]string, error) {
+	fields := make(map[string]string)
+	if entity == nil {
+		return fields, nil
+	}
+
+	entityJSON, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+
+	// Numbers are kept as written, as float64 would format 1000000 as "1e+06"
+	decoder := json.NewDecoder(bytes.NewReader(entityJSON))
+	decoder.UseNumber()
+
+	var values map[string]interface{}
+	err = decoder.Decode(&values)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range values {
+		fields[name] = fmt.Sprint(value)
+	}
+
+	return fields, nil
+}
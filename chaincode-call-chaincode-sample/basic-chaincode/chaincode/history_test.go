@@ -0,0 +1,81 @@
+package chaincode
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+func TestAssetHistory(t *testing.T) {
+	contract := &SmartContract{}
+	alice := newIdentity("alice", "Org1MSP")
+	bob := newIdentity("bob", "Org2MSP")
+	stub := newMockStub()
+
+	mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.CreateAsset(ctx, "asset1", "blue", 5, 300)
+	})
+	mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.UpdateAsset(ctx, "asset1", "red", 5, 1000000)
+	})
+	mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.TransferAsset(ctx, "asset1", bob.mspID, bob.id)
+		return err
+	})
+	mustInvoke(t, stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.DeleteAsset(ctx, "asset1")
+	})
+
+	var history []*AssetVersion
+	mustInvoke(t, stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		var err error
+		history, err = contract.GetAssetHistory(ctx, "asset1")
+		return err
+	})
+	if len(history) != 4 {
+		t.Fatalf("got %d versions, want 4", len(history))
+	}
+
+	// Versions come oldest first, each with the fields changed since the one before
+	created := history[0]
+	if created.TxID != "tx1" || created.Value == nil || len(created.Changes) != 6 {
+		t.Fatalf("got first version %+v, want the creation with every field set", created)
+	}
+	updated := fmt.Sprint(history[1].Changes)
+	if updated != "[{appraised_value 300 1000000} {color blue red}]" {
+		t.Fatalf("got changes %s of the update", updated)
+	}
+	transferred := history[2].Changes
+	if len(transferred) != 2 || transferred[0].To != bob.id || transferred[1].To != bob.mspID {
+		t.Fatalf("got changes %v of the transfer, want the owner and its MSP only", transferred)
+	}
+	deleted := history[3]
+	if !deleted.IsDelete || deleted.Value != nil || len(deleted.Changes) != 6 || deleted.Changes[0].To != "" {
+		t.Fatalf("got last version %+v, want the deletion clearing every field", deleted)
+	}
+}
+
+func TestUserHistory(t *testing.T) {
+	contract := &SmartContract{}
+	alice := newIdentity("alice", "Org1MSP")
+	stub := newMockStub()
+
+	mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		return users.Save(ctx, &User{ID: "user1", DetailsHash: "hash1"})
+	})
+	mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		return users.Update(ctx, &User{ID: "user1", DetailsHash: "hash2"})
+	})
+
+	mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		history, err := contract.GetUserHistory(ctx, "user1")
+		if err != nil {
+			return err
+		}
+		if len(history) != 2 || fmt.Sprint(history[1].Changes) != "[{details_hash hash1 hash2}]" {
+			t.Fatalf("got history %v, want the creation and the hash change", history)
+		}
+		return nil
+	})
+}
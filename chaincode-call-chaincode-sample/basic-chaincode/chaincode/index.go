@@ -0,0 +1,184 @@
+package chaincode
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// Secondary indexes of assets, stored as composite keys with an empty value
+const (
+	ownerIndex = "owner~id"
+	colorIndex = "color~id"
+)
+
+// indexValue is stored under index keys; the key itself carries the data
+var indexValue = []byte{0x00}
+
+// assetIndexKeys returns the index keys pointing at asset
+func assetIndexKeys(ctx contractapi.TransactionContextInterface, asset *Asset) ([]string, error) {
+	ownerKey, err := ctx.GetStub().CreateCompositeKey(ownerIndex, []string{asset.Owner, asset.ID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create owner index key: %v", err)
+	}
+
+	colorKey, err := ctx.GetStub().CreateCompositeKey(colorIndex, []string{asset.Color, asset.ID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create color index key: %v", err)
+	}
+
+	return []string{ownerKey, colorKey}, nil
+}
+
+// saveAsset stores asset and moves its index entries from previous, the stored version of the asset
+// or nil if the asset is new
+func saveAsset(ctx contractapi.TransactionContextInterface, asset *Asset, previous *Asset) error {
+	err := assets.Save(ctx, asset)
+	if err != nil {
+		return err
+	}
+
+	newKeys, err := assetIndexKeys(ctx, asset)
+	if err != nil {
+		return err
+	}
+
+	if previous != nil {
+		oldKeys, err := assetIndexKeys(ctx, previous)
+		if err != nil {
+			return err
+		}
+		for i, oldKey := range oldKeys {
+			if oldKey == newKeys[i] {
+				continue
+			}
+			err = ctx.GetStub().DelState(oldKey)
+			if err != nil {
+				return fmt.Errorf("failed to delete index entry: %v", err)
+			}
+		}
+	}
+
+	for _, newKey := range newKeys {
+		err = ctx.GetStub().PutState(newKey, indexValue)
+		if err != nil {
+			return fmt.Errorf("failed to put index entry into world state: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// deleteAsset removes asset and its index entries from the world state
+func deleteAsset(ctx contractapi.TransactionContextInterface, asset *Asset) error {
+	err := assets.Delete(ctx, asset.ID)
+	if err != nil {
+		return err
+	}
+
+	keys, err := assetIndexKeys(ctx, asset)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		err = ctx.GetStub().DelState(key)
+		if err != nil {
+			return fmt.Errorf("failed to delete index entry: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// assetMigrator moves assets off their legacy keys through saveAsset, so that migrated assets are indexed
+type assetMigrator struct{}
+
+func (assetMigrator) migrateLegacyKeys(ctx contractapi.TransactionContextInterface, limit int) (int, bool, error) {
+	return assets.moveLegacyKeys(ctx, limit, func(ctx contractapi.TransactionContextInterface, asset *Asset) error {
+		return saveAsset(ctx, asset, nil)
+	})
+}
+
+// queryAssetsByIndex returns the assets whose indexed field equals value
+func queryAssetsByIndex(ctx contractapi.TransactionContextInterface, index string, value string) ([]*Asset, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(index, []string{value})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	var indexed []*Asset
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split index key: %v", err)
+		}
+
+		asset, err := assets.Read(ctx, attributes[1])
+		if err != nil {
+			return nil, err
+		}
+		indexed = append(indexed, asset)
+	}
+
+	return indexed, nil
+}
+
+// deleteIndex removes every entry of index
+func deleteIndex(ctx contractapi.TransactionContextInterface, index string) error {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(index, []string{})
+	if err != nil {
+		return err
+	}
+	defer resultsIterator.Close()
+
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return err
+		}
+
+		err = ctx.GetStub().DelState(queryResponse.Key)
+		if err != nil {
+			return fmt.Errorf("failed to delete index entry: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// rebuildIndexes drops both asset indexes and recreates them from the stored assets
+func rebuildIndexes(ctx contractapi.TransactionContextInterface) (int, error) {
+	for _, index := range []string{ownerIndex, colorIndex} {
+		err := deleteIndex(ctx, index)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	allAssets, err := assets.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// Writing the index keys after deleting them keeps them, as the last write of a key wins
+	for _, asset := range allAssets {
+		keys, err := assetIndexKeys(ctx, asset)
+		if err != nil {
+			return 0, err
+		}
+		for _, key := range keys {
+			err = ctx.GetStub().PutState(key, indexValue)
+			if err != nil {
+				return 0, fmt.Errorf("failed to put index entry into world state: %v", err)
+			}
+		}
+	}
+
+	return len(allAssets), nil
+}
@@ -0,0 +1,107 @@
+package chaincode
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// assetIDs returns the IDs of the assets found through index for value
+func assetIDs(t *testing.T, stub *mockStub, index string, value string) string {
+	t.Helper()
+
+	var ids []string
+	mustInvoke(t, stub, newIdentity("reader", "Org2MSP"), func(ctx contractapi.TransactionContextInterface) error {
+		indexed, err := queryAssetsByIndex(ctx, index, value)
+		for _, asset := range indexed {
+			ids = append(ids, asset.ID)
+		}
+		return err
+	})
+	return fmt.Sprint(ids)
+}
+
+func TestAssetIndexes(t *testing.T) {
+	contract := &SmartContract{}
+	alice := newIdentity("alice", "Org1MSP")
+	bob := newIdentity("bob", "Org2MSP")
+	stub := newMockStub()
+
+	mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.CreateAsset(ctx, "asset1", "blue", 5, 300)
+	})
+	mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.CreateAsset(ctx, "asset2", "red", 5, 300)
+	})
+	if ids := assetIDs(t, stub, ownerIndex, alice.id); ids != "[asset1 asset2]" {
+		t.Fatalf("got assets %s of alice, want [asset1 asset2]", ids)
+	}
+
+	// Transfers and updates move the index entries with the asset
+	mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.TransferAsset(ctx, "asset1", bob.mspID, bob.id)
+		return err
+	})
+	mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.UpdateAsset(ctx, "asset2", "blue", 5, 300)
+	})
+	if ids := assetIDs(t, stub, ownerIndex, alice.id); ids != "[asset2]" {
+		t.Fatalf("got assets %s of alice, want [asset2]", ids)
+	}
+	if ids := assetIDs(t, stub, ownerIndex, bob.id); ids != "[asset1]" {
+		t.Fatalf("got assets %s of bob, want [asset1]", ids)
+	}
+	if ids := assetIDs(t, stub, colorIndex, "blue"); ids != "[asset1 asset2]" {
+		t.Fatalf("got blue assets %s, want [asset1 asset2]", ids)
+	}
+	if ids := assetIDs(t, stub, colorIndex, "red"); ids != "[]" {
+		t.Fatalf("got red assets %s, want none", ids)
+	}
+
+	mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.DeleteAsset(ctx, "asset2")
+	})
+	if ids := assetIDs(t, stub, colorIndex, "blue"); ids != "[asset1]" {
+		t.Fatalf("got blue assets %s after deleting asset2, want [asset1]", ids)
+	}
+}
+
+func TestRebuildIndexes(t *testing.T) {
+	contract := &SmartContract{}
+	admin := newIdentity("admin", adminMSPID)
+	stub := newMockStub()
+
+	// Assets saved without their index entries, as before indexes existed
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		for _, asset := range []*Asset{{ID: "asset1", Color: "blue", Owner: "Tomoko"}, {ID: "asset2", Color: "red", Owner: "Tomoko"}} {
+			if err := assets.Save(ctx, asset); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if ids := assetIDs(t, stub, ownerIndex, "Tomoko"); ids != "[]" {
+		t.Fatalf("got indexed assets %s before rebuilding, want none", ids)
+	}
+
+	err := invoke(stub, newIdentity("bob", "Org2MSP"), func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.RebuildIndexes(ctx)
+		return err
+	})
+	assertError(t, err, "not authorized")
+
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		indexed, err := contract.RebuildIndexes(ctx)
+		if indexed != 2 {
+			t.Fatalf("got %d assets indexed, want 2", indexed)
+		}
+		return err
+	})
+	if ids := assetIDs(t, stub, ownerIndex, "Tomoko"); ids != "[asset1 asset2]" {
+		t.Fatalf("got assets %s of Tomoko, want [asset1 asset2]", ids)
+	}
+	if ids := assetIDs(t, stub, colorIndex, "red"); ids != "[asset2]" {
+		t.Fatalf("got red assets %s, want [asset2]", ids)
+	}
+}
@@ -0,0 +1,222 @@
+package chaincode
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// userSaltTransientKey is the key of the transient map entry carrying the secret
+// MigrateUserDetails derives the salt of every migrated user from
+const userSaltTransientKey = "salt"
+
+// MigrationProgress reports the outcome of one MigrateKeys or MigrateUserDetails batch.
+// Bookmark is where the next MigrateUserDetails batch resumes; MigrateKeys leaves it empty.
+type MigrationProgress struct {
+	Migrated int    `json:"migrated"`
+	Done     bool   `json:"done"`
+	Bookmark string `json:"bookmark"`
+}
+
+// legacyKeyMigrator is implemented by every Repository
+type legacyKeyMigrator interface {
+	migrateLegacyKeys(ctx contractapi.TransactionContextInterface, limit int) (int, bool, error)
+}
+
+// migrators lists the repositories whose legacy keys MigrateKeys rewrites, in order.
+// Users are left to migrateUserDetails, which also moves their personal details out of the world state.
+var migrators = []legacyKeyMigrator{assetMigrator{}, nfts, operatorApprovals, fractions, shares}
+
+// migrateKeys rewrites at most batchSize legacy "<Table>||<ID>" keys to composite keys
+func migrateKeys(ctx contractapi.TransactionContextInterface, batchSize int) (*MigrationProgress, error) {
+	progress := &MigrationProgress{}
+	for _, migrator := range migrators {
+		migrated, remaining, err := migrator.migrateLegacyKeys(ctx, batchSize-progress.Migrated)
+		if err != nil {
+			return nil, err
+		}
+		progress.Migrated += migrated
+		if remaining {
+			return progress, nil
+		}
+	}
+
+	progress.Done = true
+	return progress, nil
+}
+
+// transientSaltSecret returns the secret passed in the transient map under "salt"
+func transientSaltSecret(ctx contractapi.TransactionContextInterface) ([]byte, error) {
+	transientMap, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transient data: %v", err)
+	}
+
+	secret, ok := transientMap[userSaltTransientKey]
+	if !ok {
+		return nil, fmt.Errorf("the salt secret must be passed in the transient map under %q", userSaltTransientKey)
+	}
+	if len(secret) < 16 {
+		return nil, fmt.Errorf("the salt secret must be at least 16 bytes long")
+	}
+
+	return secret, nil
+}
+
+// userSalt derives the salt of the details of user id from secret, so that every endorsing peer derives the same salt
+func userSalt(secret []byte, id string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(id))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// migrateUserDetails moves the personal details of the users on one page of at most batchSize users,
+// starting at bookmark, to the private data collection and rewrites their public record as {id, details_hash}.
+// Users under legacy "User||<ID>" keys are moved to composite keys first, at most batchSize per call,
+// before the first page is read. It returns the bookmark of the next page; an empty bookmark starts
+// at the first user.
+func migrateUserDetails(ctx contractapi.TransactionContextInterface, batchSize int32, bookmark string) (*MigrationProgress, error) {
+	secret, err := transientSaltSecret(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := &MigrationProgress{}
+	if bookmark == "" {
+		err = migrateLegacyUsers(ctx, secret, batchSize, progress)
+		if err != nil {
+			return nil, err
+		}
+		// The page is read by the next call, once the moved users are committed under their composite keys
+		if progress.Migrated > 0 {
+			return progress, nil
+		}
+	}
+
+	resultsIterator, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(users.table, []string{}, batchSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		record, err := decodeLegacyUser(queryResponse.Key, queryResponse.Value)
+		if err != nil {
+			return nil, err
+		}
+		// Users created or migrated since their details were kept private carry no name
+		if record.Name == nil {
+			continue
+		}
+
+		err = migrateUser(ctx, queryResponse.Key, record, secret)
+		if err != nil {
+			return nil, err
+		}
+		progress.Migrated++
+	}
+
+	// LevelDB returns an empty bookmark after the last page, CouchDB a short last page
+	progress.Bookmark = metadata.Bookmark
+	progress.Done = metadata.Bookmark == "" || metadata.FetchedRecordsCount < batchSize
+	return progress, nil
+}
+
+// migrateLegacyUsers moves at most limit users stored under legacy "User||<ID>" keys to composite keys,
+// moving their personal details to the private data collection. Moved keys are deleted, so the next call
+// resumes where this one stopped.
+func migrateLegacyUsers(ctx contractapi.TransactionContextInterface, secret []byte, limit int32, progress *MigrationProgress) error {
+	// "}" follows "|" so the range covers every key starting with "User||"
+	resultsIterator, err := ctx.GetStub().GetStateByRange(users.table+"||", users.table+"|}")
+	if err != nil {
+		return err
+	}
+	defer resultsIterator.Close()
+
+	for resultsIterator.HasNext() && progress.Migrated < int(limit) {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return err
+		}
+
+		record, err := decodeLegacyUser(queryResponse.Key, queryResponse.Value)
+		if err != nil {
+			return err
+		}
+
+		err = migrateUser(ctx, queryResponse.Key, record, secret)
+		if err != nil {
+			return err
+		}
+		progress.Migrated++
+	}
+
+	return nil
+}
+
+// decodeLegacyUser unmarshals the user record stored at key
+func decodeLegacyUser(key string, value []byte) (*legacyUser, error) {
+	var record legacyUser
+	err := json.Unmarshal(value, &record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal user data at key %s: %v", key, err)
+	}
+
+	return &record, nil
+}
+
+// migrateUser moves the personal details of the user stored at key to the private data collection
+// and stores its public record, carrying their hash, under its composite key
+func migrateUser(ctx contractapi.TransactionContextInterface, key string, record *legacyUser, secret []byte) error {
+	details := &UserDetails{ID: record.ID, Age: record.Age, Sex: record.Sex, Salt: userSalt(secret, record.ID)}
+	if record.Name != nil {
+		details.Name = *record.Name
+	}
+	hash, err := putUserDetails(ctx, details)
+	if err != nil {
+		return err
+	}
+
+	user := &User{ID: record.ID, DetailsHash: hash}
+	userJSON, err := json.Marshal(user)
+	if err != nil {
+		return fmt.Errorf("failed to marshal user: %v", err)
+	}
+
+	userKey, err := users.key(ctx, user.KeyAttributes())
+	if err != nil {
+		return err
+	}
+
+	// The change set must not repeat the personal details, so the record before the
+	// migration is reported without them, and a user moved from a legacy key as created
+	var previousJSON []byte
+	if key == userKey {
+		previousJSON, err = json.Marshal(&User{ID: record.ID})
+		if err != nil {
+			return fmt.Errorf("failed to marshal user: %v", err)
+		}
+	} else {
+		err = ctx.GetStub().DelState(key)
+		if err != nil {
+			return fmt.Errorf("failed to delete legacy key %s: %v", key, err)
+		}
+	}
+
+	err = ctx.GetStub().PutState(userKey, userJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put user into world state: %v", err)
+	}
+
+	recordChange(ctx, users.table, user.KeyAttributes(), previousJSON, userJSON)
+	return nil
+}
@@ -0,0 +1,150 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// putJSON stores value at key as the former chaincode did, bypassing the repositories
+func putJSON(t *testing.T, stub *mockStub, key string, value interface{}) {
+	t.Helper()
+
+	valueJSON, err := json.Marshal(value)
+	if err != nil {
+		t.Fatalf("failed to marshal %v: %v", value, err)
+	}
+	stub.state[key] = valueJSON
+}
+
+func TestMigrateKeys(t *testing.T) {
+	contract := &SmartContract{}
+	admin := newIdentity("admin", "Org1MSP")
+	stub := newMockStub()
+	for i := 1; i <= 5; i++ {
+		id := fmt.Sprintf("asset%d", i)
+		putJSON(t, stub, "Asset||"+id, Asset{ID: id, Color: "blue", Size: i, AppraisedValue: 100 * i})
+	}
+	for i := 1; i <= 2; i++ {
+		id := fmt.Sprintf("asset%d", i)
+		putJSON(t, stub, "Nft||"+id, Nft{ID: id, Owner: "owner"})
+	}
+	putJSON(t, stub, "User||user1", map[string]interface{}{"id": "user1", "name": "Tomoko", "age": 30, "sex": "F"})
+
+	err := invoke(stub, newIdentity("bob", "Org2MSP"), func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.MigrateKeys(ctx, 3)
+		return err
+	})
+	assertError(t, err, "not authorized")
+
+	// Each batch resumes where the previous one stopped, moving on to the next table once one is done
+	for i, want := range []MigrationProgress{{Migrated: 3}, {Migrated: 3}, {Migrated: 1, Done: true}, {Migrated: 0, Done: true}} {
+		mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+			progress, err := contract.MigrateKeys(ctx, 3)
+			if err == nil && *progress != want {
+				t.Fatalf("batch %d: MigrateKeys returned %+v, want %+v", i+1, *progress, want)
+			}
+			return err
+		})
+	}
+
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		for i := 1; i <= 5; i++ {
+			id := fmt.Sprintf("asset%d", i)
+			asset, err := assets.Read(ctx, id)
+			if err != nil {
+				return err
+			}
+			if asset.AppraisedValue != 100*i {
+				t.Fatalf("migrated %s has appraised value %d, want %d", id, asset.AppraisedValue, 100*i)
+			}
+			if stub.state["Asset||"+id] != nil {
+				t.Fatalf("legacy key of %s was not deleted", id)
+			}
+		}
+
+		_, err := nfts.Read(ctx, "asset2")
+		return err
+	})
+	// Users are left to MigrateUserDetails, so that their details never reach a new public key
+	if stub.state["User||user1"] == nil {
+		t.Fatal("MigrateKeys moved a user")
+	}
+	// Migrated assets are indexed as if they had just been created
+	if ids := assetIDs(t, stub, colorIndex, "blue"); ids != "[asset1 asset2 asset3 asset4 asset5]" {
+		t.Fatalf("got blue assets %s after migrating, want all five", ids)
+	}
+}
+
+func TestMigrateUserDetails(t *testing.T) {
+	contract := &SmartContract{}
+	admin := newIdentity("admin", "Org1MSP")
+	stub := newMockStub()
+	putJSON(t, stub, "User||user1", map[string]interface{}{"id": "user1", "name": "Tomoko", "age": 30, "sex": "F"})
+	putJSON(t, stub, "\x00User\x00user2\x00", map[string]interface{}{"id": "user2", "name": "Brad", "age": 42, "sex": "M"})
+	putJSON(t, stub, "\x00User\x00user3\x00", User{ID: "user3", DetailsHash: "hash"})
+	putJSON(t, stub, "\x00User\x00user4\x00", map[string]interface{}{"id": "user4", "name": "Max", "age": 27, "sex": "M"})
+
+	migrate := func(batchSize int32, bookmark string) (*MigrationProgress, error) {
+		var progress *MigrationProgress
+		err := invoke(stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+			var err error
+			progress, err = contract.MigrateUserDetails(ctx, batchSize, bookmark)
+			return err
+		})
+		return progress, err
+	}
+
+	_, err := migrate(2, "")
+	assertError(t, err, "salt secret")
+
+	stub.transient = map[string][]byte{userSaltTransientKey: []byte("0123456789abcdef")}
+	err = invoke(stub, newIdentity("bob", "Org2MSP"), func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.MigrateUserDetails(ctx, 2, "")
+		return err
+	})
+	assertUnauthorized(t, err)
+
+	// Legacy keys are moved first, then each call reads one page of users starting at the bookmark
+	bookmark := ""
+	for i, want := range []MigrationProgress{{Migrated: 1}, {Migrated: 1, Bookmark: "\x00User\x00user3\x00"}, {Migrated: 1, Done: true}} {
+		progress, err := migrate(2, bookmark)
+		if err != nil {
+			t.Fatalf("batch %d: MigrateUserDetails failed: %v", i+1, err)
+		}
+		if *progress != want {
+			t.Fatalf("batch %d: MigrateUserDetails returned %+v, want %+v", i+1, *progress, want)
+		}
+		bookmark = progress.Bookmark
+	}
+
+	if stub.state["User||user1"] != nil {
+		t.Fatal("legacy key of user1 was not deleted")
+	}
+	for _, id := range []string{"user1", "user2", "user4"} {
+		key, _ := stub.CreateCompositeKey("User", []string{id})
+		var public map[string]interface{}
+		err = json.Unmarshal(stub.state[key], &public)
+		if err != nil {
+			t.Fatalf("failed to unmarshal public record of %s: %v", id, err)
+		}
+		if _, found := public["name"]; found || len(public) != 2 {
+			t.Fatalf("public record of %s is %v, want only id and details_hash", id, public)
+		}
+
+		_, details := readUser(t, stub, id)
+		hash, err := hashUserDetails(details)
+		if err != nil {
+			t.Fatalf("failed to hash details of %s: %v", id, err)
+		}
+		if public["details_hash"] != hash || len(details.Salt) < 16 {
+			t.Fatalf("details of %s with salt %q do not match hash %v", id, details.Salt, public["details_hash"])
+		}
+	}
+
+	if user3, _ := readUser(t, stub, "user3"); user3.DetailsHash != "hash" {
+		t.Fatalf("user3 without public details was changed to %+v", user3)
+	}
+}
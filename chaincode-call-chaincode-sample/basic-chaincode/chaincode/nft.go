@@ -0,0 +1,235 @@
+package chaincode
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// chaincodeAccountPrefix marks an approved account that is a chaincode rather than a client,
+// e.g. "chaincode::invoker"
+const chaincodeAccountPrefix = "chaincode::"
+
+// Nft is the non-fungible token representing ownership of the asset with the same ID
+type Nft struct {
+	ID       string `json:"id"`
+	Owner    string `json:"owner"`
+	Approved string `json:"approved"`
+}
+
+// OperatorApproval records that Operator may manage every token of Owner
+type OperatorApproval struct {
+	Owner    string `json:"owner"`
+	Operator string `json:"operator"`
+	Approved bool   `json:"approved"`
+}
+
+// Transfer is emitted when a token is minted, transferred or burned.
+// From is empty for mints and To is empty for burns.
+type Transfer struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	TokenID string `json:"token_id"`
+}
+
+// Approval is emitted when the approved account of a token changes
+type Approval struct {
+	Owner    string `json:"owner"`
+	Approved string `json:"approved"`
+	TokenID  string `json:"token_id"`
+}
+
+// ApprovalForAll is emitted when an operator is granted or denied access to all tokens of an owner
+type ApprovalForAll struct {
+	Owner    string `json:"owner"`
+	Operator string `json:"operator"`
+	Approved bool   `json:"approved"`
+}
+
+// KeyAttributes returns the attributes the token is stored under, the ID of its asset
+func (n Nft) KeyAttributes() []string {
+	return []string{n.ID}
+}
+
+// KeyAttributes returns the attributes the approval is stored under, its owner and operator
+func (o OperatorApproval) KeyAttributes() []string {
+	return []string{o.Owner, o.Operator}
+}
+
+// nfts stores asset tokens under ("Nft", id) composite keys
+var nfts = NewRepository[Nft]()
+
+// operatorApprovals stores operator approvals under ("OperatorApproval", owner, operator) composite keys
+var operatorApprovals = NewRepository[OperatorApproval]()
+
+// isApprovedForAll reports whether operator may manage every token of owner
+func isApprovedForAll(ctx contractapi.TransactionContextInterface, owner string, operator string) (bool, error) {
+	return operatorApprovals.Exists(ctx, owner, operator)
+}
+
+// setApprovalForAll grants or revokes operator's approval over the tokens of owner
+func setApprovalForAll(ctx contractapi.TransactionContextInterface, owner string, operator string, approved bool) error {
+	if approved {
+		return operatorApprovals.Save(ctx, &OperatorApproval{Owner: owner, Operator: operator, Approved: true})
+	}
+
+	exists, err := operatorApprovals.Exists(ctx, owner, operator)
+	if err != nil || !exists {
+		return err
+	}
+
+	return operatorApprovals.Delete(ctx, owner, operator)
+}
+
+// mintNft creates the token of an asset and assigns it to owner
+func mintNft(ctx contractapi.TransactionContextInterface, id string, owner string) error {
+	exists, err := nfts.Exists(ctx, id)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("asset %s is already tokenized", id)
+	}
+
+	return nfts.Save(ctx, &Nft{ID: id, Owner: owner})
+}
+
+// isAssetOwner reports whether the client is the identity owning the token of an asset,
+// matching both its client ID and the MSP ID of the owner, or is an asset admin.
+// An asset without an owner MSP ID, such as one tokenized before identities were bound,
+// can only be moved by an asset admin, who binds it with TransferAsset.
+func isAssetOwner(ctx contractapi.TransactionContextInterface, asset *Asset, nft *Nft) (bool, error) {
+	admin, err := isAssetAdmin(ctx)
+	if err != nil || admin {
+		return admin, err
+	}
+
+	clientID, err := getClientAccountID(ctx)
+	if err != nil {
+		return false, err
+	}
+	clientMSPID, err := getClientMSPID(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return asset.OwnerMSP != "" && clientID == nft.Owner && asset.OwnerMSP == clientMSPID, nil
+}
+
+// checkAssetOwner returns an error unless the client owns the asset or is an asset admin,
+// together with the token of the asset, nil if it is not tokenized.
+// Assets that are not tokenized have no owner identity, so only an asset admin may change them.
+func checkAssetOwner(ctx contractapi.TransactionContextInterface, asset *Asset) (*Nft, error) {
+	tokenized, err := nfts.Exists(ctx, asset.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !tokenized {
+		admin, err := isAssetAdmin(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !admin {
+			return nil, errUnauthorized("asset %s is not tokenized and may only be changed by an admin", asset.ID)
+		}
+		return nil, nil
+	}
+
+	nft, err := nfts.Read(ctx, asset.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	owner, err := isAssetOwner(ctx, asset, nft)
+	if err != nil {
+		return nil, err
+	}
+	if !owner {
+		return nil, errUnauthorized("client is not the owner of asset %s", asset.ID)
+	}
+
+	return nft, nil
+}
+
+// checkTokenOperator returns the token of an asset if the client may move it,
+// that is if it is the owner, an asset admin, the approved account or an approved operator,
+// or if the transaction was invoked through the approved chaincode.
+// Tokens of fractionalized assets are locked and cannot be moved.
+func checkTokenOperator(ctx contractapi.TransactionContextInterface, asset *Asset) (*Nft, error) {
+	id := asset.ID
+	nft, err := nfts.Read(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkNotFractionalized(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	owner, err := isAssetOwner(ctx, asset, nft)
+	if err != nil {
+		return nil, err
+	}
+	if owner {
+		return nft, nil
+	}
+
+	clientID, err := getClientAccountID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if clientID == nft.Approved {
+		return nft, nil
+	}
+
+	// The owner may approve a chaincode, such as a marketplace, to move the token on its behalf
+	if strings.HasPrefix(nft.Approved, chaincodeAccountPrefix) {
+		ccName, err := getInvokerChaincodeName(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if nft.Approved == chaincodeAccountPrefix+ccName {
+			return nft, nil
+		}
+	}
+
+	approved, err := isApprovedForAll(ctx, nft.Owner, clientID)
+	if err != nil {
+		return nil, err
+	}
+	if !approved {
+		return nil, errUnauthorized("client is not the owner of asset %s nor is approved", id)
+	}
+
+	return nft, nil
+}
+
+// transferNft moves the token of an asset to to, a client ID of the organization toMSP, clearing its approval
+// and pending transfer offer, and keeps the owner of the asset and its owner index in step with the token
+func transferNft(ctx contractapi.TransactionContextInterface, nft *Nft, asset *Asset, toMSP string, to string) error {
+	if to == "" {
+		return fmt.Errorf("transfer to an empty account")
+	}
+	if toMSP == "" {
+		return fmt.Errorf("the MSP ID of the new owner must be specified")
+	}
+
+	nft.Owner = to
+	nft.Approved = ""
+	err := nfts.Save(ctx, nft)
+	if err != nil {
+		return err
+	}
+
+	err = clearOffer(ctx, asset.ID)
+	if err != nil {
+		return err
+	}
+
+	previous := *asset
+	asset.Owner = to
+	asset.OwnerMSP = toMSP
+	return saveAsset(ctx, asset, &previous)
+}
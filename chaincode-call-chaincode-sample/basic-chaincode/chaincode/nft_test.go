@@ -0,0 +1,320 @@
+package chaincode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// assertError fails the test unless err mentions want
+func assertError(t *testing.T, err error, want string) {
+	t.Helper()
+
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Fatalf("got error %v, want an error mentioning %q", err, want)
+	}
+}
+
+// ownerOf returns the committed owner of the token of an asset
+func ownerOf(t *testing.T, stub *mockStub, id string) string {
+	t.Helper()
+
+	var owner string
+	mustInvoke(t, stub, newIdentity("reader", "Org2MSP"), func(ctx contractapi.TransactionContextInterface) error {
+		nft, err := nfts.Read(ctx, id)
+		if err != nil {
+			return err
+		}
+		asset, err := assets.Read(ctx, id)
+		if err != nil {
+			return err
+		}
+		if asset.Owner != nft.Owner {
+			t.Fatalf("asset %s is owned by %s but its token by %s", id, asset.Owner, nft.Owner)
+		}
+		owner = nft.Owner
+		return nil
+	})
+	return owner
+}
+
+func TestCreateAssetMintsToken(t *testing.T) {
+	contract := &SmartContract{}
+	alice := newIdentity("alice", "Org1MSP")
+	stub := newMockStub()
+
+	mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.CreateAsset(ctx, "asset1", "blue", 5, 300)
+	})
+	if names := eventNames(t, stub); names != "Transfer" {
+		t.Fatalf("got events %q, want Transfer", names)
+	}
+	if owner := ownerOf(t, stub, "asset1"); owner != alice.id {
+		t.Fatalf("got owner %s, want %s", owner, alice.id)
+	}
+
+	err := invoke(stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.CreateAsset(ctx, "asset1", "red", 5, 300)
+	})
+	assertError(t, err, "already exists")
+}
+
+func TestTransferRequiresOwnerOrApproval(t *testing.T) {
+	contract := &SmartContract{}
+	alice := newIdentity("alice", "Org1MSP")
+	bob := newIdentity("bob", "Org2MSP")
+	carol := newIdentity("carol", "Org2MSP")
+	stub := newMockStub()
+
+	mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.CreateAsset(ctx, "asset1", "blue", 5, 300)
+	})
+
+	err := invoke(stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.TransferAsset(ctx, "asset1", bob.mspID, bob.id)
+		return err
+	})
+	assertError(t, err, "not the owner")
+
+	// An approved account may transfer the token once, as the transfer clears the approval
+	mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.Approve(ctx, bob.id, "asset1")
+	})
+	mustInvoke(t, stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.TransferFrom(ctx, alice.id, carol.mspID, carol.id, "asset1")
+	})
+	if owner := ownerOf(t, stub, "asset1"); owner != carol.id {
+		t.Fatalf("got owner %s, want %s", owner, carol.id)
+	}
+	err = invoke(stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.TransferFrom(ctx, carol.id, bob.mspID, bob.id, "asset1")
+	})
+	assertError(t, err, "not the owner")
+
+	// An operator may manage every token of the owner until the approval is revoked
+	mustInvoke(t, stub, carol, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.SetApprovalForAll(ctx, bob.id, true)
+	})
+	mustInvoke(t, stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.TransferAsset(ctx, "asset1", alice.mspID, alice.id)
+		return err
+	})
+	if owner := ownerOf(t, stub, "asset1"); owner != alice.id {
+		t.Fatalf("got owner %s, want %s", owner, alice.id)
+	}
+}
+
+func TestTokenizeAsset(t *testing.T) {
+	contract := &SmartContract{}
+	admin := newIdentity("admin", "Org1MSP")
+	bob := newIdentity("bob", "Org2MSP")
+	stub := newMockStub()
+
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return assets.Save(ctx, &Asset{ID: "legacy1", Color: "green", Size: 1, Owner: "Max", AppraisedValue: 10})
+	})
+
+	err := invoke(stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.TokenizeAsset(ctx, "legacy1", bob.mspID, bob.id)
+	})
+	assertError(t, err, "not authorized")
+
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.TokenizeAsset(ctx, "legacy1", bob.mspID, bob.id)
+	})
+	mustInvoke(t, stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		owner, err := contract.OwnerOf(ctx, "legacy1")
+		if owner != bob.id {
+			t.Fatalf("got owner %s, want %s", owner, bob.id)
+		}
+		return err
+	})
+
+	err = invoke(stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.TokenizeAsset(ctx, "legacy1", admin.mspID, admin.id)
+	})
+	assertError(t, err, "already tokenized")
+}
+
+// assertUnauthorized fails the test unless err is an authorization error
+func assertUnauthorized(t *testing.T, err error) {
+	t.Helper()
+
+	if err == nil || !strings.HasPrefix(err.Error(), unauthorizedCode+": ") {
+		t.Fatalf("got error %v, want an authorization error", err)
+	}
+}
+
+// newAdmin returns an identity of organization mspID carrying the asset admin attribute
+func newAdmin(cn string, mspID string) *mockIdentity {
+	admin := newIdentity(cn, mspID)
+	admin.attributes[assetAdminAttribute] = "true"
+	return admin
+}
+
+func TestCheckAssetOwner(t *testing.T) {
+	alice := newIdentity("alice", "Org1MSP")
+	stub := newMockStub()
+	createAssets(t, stub, alice)
+
+	// The same client ID presented by another organization is another client
+	aliceOrg2 := newIdentity("alice", "Org2MSP")
+	aliceOrg2.id = alice.id
+
+	tests := []struct {
+		name       string
+		identity   *mockIdentity
+		authorized bool
+	}{
+		{name: "owner", identity: alice, authorized: true},
+		{name: "other client", identity: newIdentity("bob", "Org1MSP"), authorized: false},
+		{name: "owner ID from another MSP", identity: aliceOrg2, authorized: false},
+		{name: "admin", identity: newAdmin("admin", "Org1MSP"), authorized: true},
+		{name: "admin attribute from another MSP", identity: newAdmin("admin", "Org2MSP"), authorized: false},
+	}
+	for _, test := range tests {
+		err := invoke(stub, test.identity, func(ctx contractapi.TransactionContextInterface) error {
+			asset, err := assets.Read(ctx, "asset1")
+			if err != nil {
+				t.Fatalf("failed to read asset1: %v", err)
+			}
+			nft, err := checkAssetOwner(ctx, asset)
+			if err == nil && (nft == nil || nft.ID != "asset1") {
+				t.Fatalf("%s: checkAssetOwner returned token %v, want the token of asset1", test.name, nft)
+			}
+			return err
+		})
+		if !test.authorized {
+			assertUnauthorized(t, err)
+		} else if err != nil {
+			t.Fatalf("%s: checkAssetOwner failed: %v", test.name, err)
+		}
+	}
+
+	// Assets that are not tokenized have no owner identity, so only an admin may change them
+	legacy := &Asset{ID: "legacy1", Color: "green", Size: 1, AppraisedValue: 10}
+	mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		return assets.Save(ctx, legacy)
+	})
+	err := invoke(stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := checkAssetOwner(ctx, legacy)
+		return err
+	})
+	assertUnauthorized(t, err)
+	mustInvoke(t, stub, newAdmin("admin", "Org1MSP"), func(ctx contractapi.TransactionContextInterface) error {
+		nft, err := checkAssetOwner(ctx, legacy)
+		if nft != nil {
+			t.Fatalf("got token %v of an untokenized asset", nft)
+		}
+		return err
+	})
+}
+
+func TestCheckTokenOperator(t *testing.T) {
+	contract := &SmartContract{}
+	alice := newIdentity("alice", "Org1MSP")
+	bob := newIdentity("bob", "Org1MSP")
+	carol := newIdentity("carol", "Org1MSP")
+	dave := newIdentity("dave", "Org1MSP")
+	stub := newMockStub()
+	createAssets(t, stub, alice)
+
+	mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.Approve(ctx, bob.id, "asset1")
+	})
+	mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.SetApprovalForAll(ctx, carol.id, true)
+	})
+
+	tests := []struct {
+		name       string
+		identity   *mockIdentity
+		id         string
+		authorized bool
+	}{
+		{name: "owner", identity: alice, id: "asset1", authorized: true},
+		{name: "approved account", identity: bob, id: "asset1", authorized: true},
+		{name: "account approved for another token", identity: bob, id: "asset2", authorized: false},
+		{name: "operator approved for all", identity: carol, id: "asset2", authorized: true},
+		{name: "other client", identity: dave, id: "asset1", authorized: false},
+		{name: "admin attribute from another MSP", identity: newAdmin("admin", "Org2MSP"), id: "asset1", authorized: false},
+	}
+	for _, test := range tests {
+		err := invoke(stub, test.identity, func(ctx contractapi.TransactionContextInterface) error {
+			asset, err := assets.Read(ctx, test.id)
+			if err != nil {
+				t.Fatalf("failed to read %s: %v", test.id, err)
+			}
+			_, err = checkTokenOperator(ctx, asset)
+			return err
+		})
+		if !test.authorized {
+			assertUnauthorized(t, err)
+		} else if err != nil {
+			t.Fatalf("%s: checkTokenOperator failed: %v", test.name, err)
+		}
+	}
+}
+
+func TestUpdateAssetRequiresOwner(t *testing.T) {
+	contract := &SmartContract{}
+	alice := newIdentity("alice", "Org1MSP")
+	bob := newIdentity("bob", "Org2MSP")
+	stub := newMockStub()
+	createAssets(t, stub, alice)
+
+	err := invoke(stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.UpdateAsset(ctx, "asset1", "red", 5, 100)
+	})
+	assertUnauthorized(t, err)
+	err = invoke(stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.DeleteAsset(ctx, "asset1")
+	})
+	assertUnauthorized(t, err)
+
+	// An update keeps the owner, whoever makes it
+	mustInvoke(t, stub, newAdmin("admin", "Org1MSP"), func(ctx contractapi.TransactionContextInterface) error {
+		return contract.UpdateAsset(ctx, "asset1", "red", 5, 100)
+	})
+	if owner := ownerOf(t, stub, "asset1"); owner != alice.id {
+		t.Fatalf("got owner %s after an admin update, want %s", owner, alice.id)
+	}
+}
+
+func TestUnboundAssetNeedsAdmin(t *testing.T) {
+	contract := &SmartContract{}
+	alice := newIdentity("alice", "Org1MSP")
+	bob := newIdentity("bob", "Org2MSP")
+	stub := newMockStub()
+
+	// A token minted before identities were bound carries no owner MSP
+	mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		if err := assets.Save(ctx, &Asset{ID: "asset1", Color: "blue", Owner: alice.id}); err != nil {
+			return err
+		}
+		return nfts.Save(ctx, &Nft{ID: "asset1", Owner: alice.id})
+	})
+
+	err := invoke(stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.TransferAsset(ctx, "asset1", bob.mspID, bob.id)
+		return err
+	})
+	assertUnauthorized(t, err)
+
+	err = invoke(stub, newAdmin("admin", "Org1MSP"), func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.TransferAsset(ctx, "asset1", "", bob.id)
+		return err
+	})
+	assertError(t, err, "MSP ID of the new owner must be specified")
+
+	// An admin binds the asset to its owner, who can then move it
+	mustInvoke(t, stub, newAdmin("admin", "Org1MSP"), func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.TransferAsset(ctx, "asset1", alice.mspID, alice.id)
+		return err
+	})
+	mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.TransferAsset(ctx, "asset1", bob.mspID, bob.id)
+		return err
+	})
+}
@@ -0,0 +1,200 @@
+package chaincode
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// Indexes of pending transfer offers by recipient and by owner, stored as composite keys with an empty value
+const (
+	offerRecipientIndex = "recipient~asset"
+	offerOwnerIndex     = "owner~asset"
+)
+
+// Statuses reported by TransferOfferEvent
+const (
+	offerCreated   = "created"
+	offerAccepted  = "accepted"
+	offerRejected  = "rejected"
+	offerCancelled = "cancelled"
+)
+
+// TransferOffer is a pending offer of the owner of an asset to transfer it to a recipient,
+// who must accept it before ExpiresAt for the asset to change hands
+type TransferOffer struct {
+	AssetID      string `json:"asset_id"`
+	Owner        string `json:"owner"`
+	Recipient    string `json:"recipient"`
+	RecipientMSP string `json:"recipient_msp"`
+	ExpiresAt    string `json:"expires_at"`
+}
+
+// TransferOfferEvent is emitted when an offer is created, accepted, rejected or cancelled
+type TransferOfferEvent struct {
+	AssetID   string `json:"asset_id"`
+	Owner     string `json:"owner"`
+	Recipient string `json:"recipient"`
+	Status    string `json:"status"`
+}
+
+// KeyAttributes returns the attributes the offer is stored under, the ID of its asset,
+// so that an asset has at most one pending offer
+func (o TransferOffer) KeyAttributes() []string {
+	return []string{o.AssetID}
+}
+
+// offers stores transfer offers under ("TransferOffer", asset) composite keys
+var offers = NewRepository[TransferOffer]()
+
+// txTime returns the timestamp of the transaction, the same on every endorsing peer
+func txTime(ctx contractapi.TransactionContextInterface) (time.Time, error) {
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+
+	return timestamp.AsTime(), nil
+}
+
+// expired reports whether the offer has expired at the time of the transaction
+func (o *TransferOffer) expired(now time.Time) (bool, error) {
+	expiresAt, err := time.Parse(time.RFC3339, o.ExpiresAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse expiry of offer for asset %s: %v", o.AssetID, err)
+	}
+
+	return !now.Before(expiresAt), nil
+}
+
+// offerIndexKeys returns the index keys pointing at offer
+func offerIndexKeys(ctx contractapi.TransactionContextInterface, offer *TransferOffer) ([]string, error) {
+	recipientKey, err := ctx.GetStub().CreateCompositeKey(offerRecipientIndex, []string{offer.Recipient, offer.AssetID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recipient index key: %v", err)
+	}
+
+	ownerKey, err := ctx.GetStub().CreateCompositeKey(offerOwnerIndex, []string{offer.Owner, offer.AssetID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create owner index key: %v", err)
+	}
+
+	return []string{recipientKey, ownerKey}, nil
+}
+
+// saveOffer stores a new offer and its index entries
+func saveOffer(ctx contractapi.TransactionContextInterface, offer *TransferOffer) error {
+	err := offers.Save(ctx, offer)
+	if err != nil {
+		return err
+	}
+
+	keys, err := offerIndexKeys(ctx, offer)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		err = ctx.GetStub().PutState(key, indexValue)
+		if err != nil {
+			return fmt.Errorf("failed to put index entry into world state: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// deleteOffer removes an offer and its index entries
+func deleteOffer(ctx contractapi.TransactionContextInterface, offer *TransferOffer) error {
+	err := offers.Delete(ctx, offer.AssetID)
+	if err != nil {
+		return err
+	}
+
+	keys, err := offerIndexKeys(ctx, offer)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		err = ctx.GetStub().DelState(key)
+		if err != nil {
+			return fmt.Errorf("failed to delete index entry: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// clearOffer removes the offer for an asset, if any, once the asset changes hands or is deleted
+func clearOffer(ctx contractapi.TransactionContextInterface, assetID string) error {
+	exists, err := offers.Exists(ctx, assetID)
+	if err != nil || !exists {
+		return err
+	}
+
+	offer, err := offers.Read(ctx, assetID)
+	if err != nil {
+		return err
+	}
+
+	return deleteOffer(ctx, offer)
+}
+
+// checkOfferRecipient returns an error unless the client is the identity the offer was made to
+func checkOfferRecipient(ctx contractapi.TransactionContextInterface, offer *TransferOffer) error {
+	clientID, err := getClientAccountID(ctx)
+	if err != nil {
+		return err
+	}
+	clientMSPID, err := getClientMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	if clientID != offer.Recipient || clientMSPID != offer.RecipientMSP {
+		return errUnauthorized("client is not the recipient of the offer for asset %s", offer.AssetID)
+	}
+
+	return nil
+}
+
+// pendingOffers returns the offers that have not expired among those indexed under account in index
+func pendingOffers(ctx contractapi.TransactionContextInterface, index string, account string) ([]*TransferOffer, error) {
+	now, err := txTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(index, []string{account})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	pending := []*TransferOffer{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split index key: %v", err)
+		}
+
+		offer, err := offers.Read(ctx, attributes[1])
+		if err != nil {
+			return nil, err
+		}
+
+		expired, err := offer.expired(now)
+		if err != nil {
+			return nil, err
+		}
+		if !expired {
+			pending = append(pending, offer)
+		}
+	}
+
+	return pending, nil
+}
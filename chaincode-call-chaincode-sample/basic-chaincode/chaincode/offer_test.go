@@ -0,0 +1,158 @@
+package chaincode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// offerAsset offers asset1 of owner to recipient for a minute
+func offerAsset(t *testing.T, stub *mockStub, owner *mockIdentity, recipient *mockIdentity) {
+	t.Helper()
+
+	mustInvoke(t, stub, owner, func(ctx contractapi.TransactionContextInterface) error {
+		return (&SmartContract{}).OfferAsset(ctx, "asset1", recipient.mspID, recipient.id, 60)
+	})
+}
+
+// offersOf returns the IDs of the assets in the pending inbound or outbound offers of account
+func offersOf(t *testing.T, stub *mockStub, account *mockIdentity, inbound bool) string {
+	t.Helper()
+
+	contract := &SmartContract{}
+	var ids string
+	mustInvoke(t, stub, account, func(ctx contractapi.TransactionContextInterface) error {
+		var pending []*TransferOffer
+		var err error
+		if inbound {
+			pending, err = contract.GetInboundOffers(ctx, account.id)
+		} else {
+			pending, err = contract.GetOutboundOffers(ctx, account.id)
+		}
+		if err != nil {
+			return err
+		}
+		for _, offer := range pending {
+			if ids != "" {
+				ids += ","
+			}
+			ids += offer.AssetID
+		}
+		return nil
+	})
+	return ids
+}
+
+func TestAcceptOffer(t *testing.T) {
+	contract := &SmartContract{}
+	alice := newIdentity("alice", "Org1MSP")
+	bob := newIdentity("bob", "Org2MSP")
+	stub := newMockStub()
+	createAssets(t, stub, alice)
+
+	offerAsset(t, stub, alice, bob)
+	if names := eventNames(t, stub); names != "TransferOffer" {
+		t.Fatalf("got events %q, want TransferOffer", names)
+	}
+	if owner := ownerOf(t, stub, "asset1"); owner != alice.id {
+		t.Fatalf("got owner %s before the offer was accepted, want %s", owner, alice.id)
+	}
+	if ids := offersOf(t, stub, bob, true); ids != "asset1" {
+		t.Fatalf("got inbound offers %q, want asset1", ids)
+	}
+	if ids := offersOf(t, stub, alice, false); ids != "asset1" {
+		t.Fatalf("got outbound offers %q, want asset1", ids)
+	}
+
+	err := invoke(stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.OfferAsset(ctx, "asset1", bob.mspID, bob.id, 60)
+	})
+	assertError(t, err, "already has a pending offer")
+
+	// Only the recipient, under the MSP it was offered to, may accept
+	err = invoke(stub, newIdentity("bob", "Org1MSP"), func(ctx contractapi.TransactionContextInterface) error {
+		return contract.AcceptOffer(ctx, "asset1")
+	})
+	assertUnauthorized(t, err)
+
+	stub.timestamp = stub.timestamp.Add(59 * time.Second)
+	mustInvoke(t, stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.AcceptOffer(ctx, "asset1")
+	})
+	if owner := ownerOf(t, stub, "asset1"); owner != bob.id {
+		t.Fatalf("got owner %s, want %s", owner, bob.id)
+	}
+	if ids := offersOf(t, stub, bob, true); ids != "" {
+		t.Fatalf("got inbound offers %q after the offer was accepted, want none", ids)
+	}
+}
+
+func TestAcceptExpiredOffer(t *testing.T) {
+	contract := &SmartContract{}
+	alice := newIdentity("alice", "Org1MSP")
+	bob := newIdentity("bob", "Org2MSP")
+	stub := newMockStub()
+	createAssets(t, stub, alice)
+
+	offerAsset(t, stub, alice, bob)
+
+	// An offer expires at the end of its lifetime
+	stub.timestamp = stub.timestamp.Add(60 * time.Second)
+	err := invoke(stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.AcceptOffer(ctx, "asset1")
+	})
+	assertError(t, err, "expired")
+	if ids := offersOf(t, stub, bob, true); ids != "" {
+		t.Fatalf("got inbound offers %q after the offer expired, want none", ids)
+	}
+
+	// An expired offer no longer blocks a new one
+	offerAsset(t, stub, alice, bob)
+}
+
+func TestRejectAndCancelOffer(t *testing.T) {
+	contract := &SmartContract{}
+	alice := newIdentity("alice", "Org1MSP")
+	bob := newIdentity("bob", "Org2MSP")
+	stub := newMockStub()
+	createAssets(t, stub, alice)
+
+	offerAsset(t, stub, alice, bob)
+
+	// Only the recipient may reject an offer
+	err := invoke(stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.RejectOffer(ctx, "asset1")
+	})
+	assertUnauthorized(t, err)
+	mustInvoke(t, stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.RejectOffer(ctx, "asset1")
+	})
+	if ids := offersOf(t, stub, alice, false); ids != "" {
+		t.Fatalf("got outbound offers %q after the offer was rejected, want none", ids)
+	}
+
+	offerAsset(t, stub, alice, bob)
+
+	// Only the owner may cancel an offer
+	err = invoke(stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.CancelOffer(ctx, "asset1")
+	})
+	assertUnauthorized(t, err)
+	mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.CancelOffer(ctx, "asset1")
+	})
+	if ids := offersOf(t, stub, bob, true); ids != "" {
+		t.Fatalf("got inbound offers %q after the offer was cancelled, want none", ids)
+	}
+
+	// A direct transfer clears a pending offer
+	offerAsset(t, stub, alice, bob)
+	mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.TransferAsset(ctx, "asset1", bob.mspID, bob.id)
+		return err
+	})
+	if ids := offersOf(t, stub, bob, true); ids != "" {
+		t.Fatalf("got inbound offers %q after the asset was transferred, want none", ids)
+	}
+}
@@ -0,0 +1,92 @@
+package chaincode
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+func TestPagination(t *testing.T) {
+	contract := &SmartContract{}
+	alice := newIdentity("alice", "Org1MSP")
+	stub := newMockStub()
+
+	mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		for i := 0; i < 5; i++ {
+			id := fmt.Sprintf("asset%d", i)
+			if err := assets.Save(ctx, &Asset{ID: id, Color: "blue", Owner: alice.id}); err != nil {
+				return err
+			}
+			if err := users.Save(ctx, &User{ID: fmt.Sprintf("user%d", i)}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	// Walk the assets two at a time until the bookmark runs out
+	var pages []int32
+	var ids []string
+	bookmark := ""
+	for {
+		var page *AssetPage
+		mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+			var err error
+			page, err = contract.GetAssetsWithPagination(ctx, 2, bookmark)
+			return err
+		})
+		pages = append(pages, page.FetchedRecordsCount)
+		for _, asset := range page.Records {
+			ids = append(ids, asset.ID)
+		}
+		if page.Bookmark == "" {
+			break
+		}
+		bookmark = page.Bookmark
+	}
+	if fmt.Sprint(pages) != "[2 2 1]" {
+		t.Fatalf("got pages of %v assets, want [2 2 1]", pages)
+	}
+	if fmt.Sprint(ids) != "[asset0 asset1 asset2 asset3 asset4]" {
+		t.Fatalf("got assets %v", ids)
+	}
+
+	mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		page, err := contract.GetUsersWithPagination(ctx, 10, "")
+		if err != nil {
+			return err
+		}
+		if page.FetchedRecordsCount != 5 || page.Bookmark != "" {
+			t.Fatalf("got %d users and bookmark %q, want 5 and none", page.FetchedRecordsCount, page.Bookmark)
+		}
+		return nil
+	})
+
+	err := invoke(stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.GetAssetsWithPagination(ctx, 0, "")
+		return err
+	})
+	assertError(t, err, "page size must be a positive integer")
+
+	mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		for i := 5; i < maxPageSize+5; i++ {
+			if err := users.Save(ctx, &User{ID: fmt.Sprintf("user%03d", i)}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	// An oversized page is capped
+	mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		page, err := contract.GetUsersWithPagination(ctx, maxPageSize+50, "")
+		if err != nil {
+			return err
+		}
+		if page.FetchedRecordsCount != maxPageSize || page.Bookmark == "" {
+			t.Fatalf("got %d users and bookmark %q, want %d and a next page", page.FetchedRecordsCount, page.Bookmark, maxPageSize)
+		}
+		return nil
+	})
+}
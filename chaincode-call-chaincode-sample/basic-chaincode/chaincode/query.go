@@ -0,0 +1,106 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim"
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// assetQuery builds a CouchDB query from a selector over asset fields, e.g. {"owner":"Tomoko","size":{"$gt":5}}.
+// Other entities such as Nft share field names with Asset, so the selector is narrowed to documents
+// having appraised_value, a field only assets carry.
+// Rich queries require CouchDB as the state database.
+func assetQuery(selectorJSON string) (string, error) {
+	var selector map[string]interface{}
+	err := json.Unmarshal([]byte(selectorJSON), &selector)
+	if err != nil {
+		return "", fmt.Errorf("selector must be a JSON object: %v", err)
+	}
+
+	query := map[string]interface{}{
+		"selector": map[string]interface{}{
+			"$and": []interface{}{
+				selector,
+				map[string]interface{}{"appraised_value": map[string]interface{}{"$exists": true}},
+			},
+		},
+	}
+
+	queryJSON, err := json.Marshal(query)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal query: %v", err)
+	}
+
+	return string(queryJSON), nil
+}
+
+// decodeAssets unmarshals every asset returned by a rich query
+func decodeAssets(resultsIterator shim.StateQueryIteratorInterface) ([]*Asset, error) {
+	var results []*Asset
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var asset Asset
+		err = json.Unmarshal(queryResponse.Value, &asset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal asset data: %v", err)
+		}
+		results = append(results, &asset)
+	}
+
+	return results, nil
+}
+
+// queryAssets returns every asset matching selectorJSON
+func queryAssets(ctx contractapi.TransactionContextInterface, selectorJSON string) ([]*Asset, error) {
+	query, err := assetQuery(selectorJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsIterator, err := ctx.GetStub().GetQueryResult(query)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	return decodeAssets(resultsIterator)
+}
+
+// queryAssetsPage returns one page of the assets matching selectorJSON
+func queryAssetsPage(ctx contractapi.TransactionContextInterface, selectorJSON string, pageSize int32, bookmark string) (*AssetPage, error) {
+	pageSize, err := checkPageSize(pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	query, err := assetQuery(selectorJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsIterator, metadata, err := ctx.GetStub().GetQueryResultWithPagination(query, pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	records, err := decodeAssets(resultsIterator)
+	if err != nil {
+		return nil, err
+	}
+	if records == nil {
+		records = []*Asset{}
+	}
+
+	return &AssetPage{
+		Records:             records,
+		FetchedRecordsCount: metadata.FetchedRecordsCount,
+		Bookmark:            metadata.Bookmark,
+	}, nil
+}
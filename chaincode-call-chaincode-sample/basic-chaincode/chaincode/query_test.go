@@ -0,0 +1,100 @@
+package chaincode
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// createAssets creates the assets asset1 (blue, 5, 100), asset2 (red, 10, 200) and asset3 (blue, 15, 300)
+// owned by alice, each with its token
+func createAssets(t *testing.T, stub *mockStub, alice *mockIdentity) {
+	t.Helper()
+
+	contract := &SmartContract{}
+	for _, asset := range []Asset{
+		{ID: "asset1", Color: "blue", Size: 5, AppraisedValue: 100},
+		{ID: "asset2", Color: "red", Size: 10, AppraisedValue: 200},
+		{ID: "asset3", Color: "blue", Size: 15, AppraisedValue: 300},
+	} {
+		mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+			return contract.CreateAsset(ctx, asset.ID, asset.Color, asset.Size, asset.AppraisedValue)
+		})
+	}
+}
+
+// idsOf returns the IDs of assets, in order
+func idsOf(assets []*Asset) string {
+	ids := []string{}
+	for _, asset := range assets {
+		ids = append(ids, asset.ID)
+	}
+	return fmt.Sprint(ids)
+}
+
+func TestQueryAssets(t *testing.T) {
+	contract := &SmartContract{}
+	alice := newIdentity("alice", "Org1MSP")
+	stub := newMockStub()
+	createAssets(t, stub, alice)
+
+	// The tokens of the assets carry the same owner field, so only the appraised_value narrowing keeps them out
+	ownerSelector := `{"owner":"` + alice.id + `"}`
+
+	tests := []struct {
+		selector string
+		want     string
+	}{
+		{selector: ownerSelector, want: "[asset1 asset2 asset3]"},
+		{selector: `{"color":"blue"}`, want: "[asset1 asset3]"},
+		{selector: `{"size":{"$gt":5,"$lt":15}}`, want: "[asset2]"},
+		{selector: `{"$and":[{"color":"blue"},{"appraised_value":{"$gt":100}}]}`, want: "[asset3]"},
+		{selector: `{"color":"green"}`, want: "[]"},
+	}
+	for _, test := range tests {
+		mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+			assets, err := contract.QueryAssets(ctx, test.selector)
+			if err == nil && idsOf(assets) != test.want {
+				t.Fatalf("QueryAssets(%s) returned %s, want %s", test.selector, idsOf(assets), test.want)
+			}
+			return err
+		})
+	}
+
+	err := invoke(stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.QueryAssets(ctx, `["color","blue"]`)
+		return err
+	})
+	assertError(t, err, "selector must be a JSON object")
+}
+
+func TestQueryAssetsWithPagination(t *testing.T) {
+	contract := &SmartContract{}
+	alice := newIdentity("alice", "Org1MSP")
+	stub := newMockStub()
+	createAssets(t, stub, alice)
+	ownerSelector := `{"owner":"` + alice.id + `"}`
+
+	err := invoke(stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.QueryAssetsWithPagination(ctx, ownerSelector, -1, "")
+		return err
+	})
+	assertError(t, err, "page size must be a positive integer")
+
+	// Each page resumes after the bookmark of the previous one, until a page comes back empty
+	bookmark := ""
+	for i, want := range []string{"[asset1 asset2]", "[asset3]", "[]"} {
+		mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+			page, err := contract.QueryAssetsWithPagination(ctx, ownerSelector, 2, bookmark)
+			if err != nil {
+				return err
+			}
+			if idsOf(page.Records) != want {
+				t.Fatalf("page %d returned %s, want %s", i+1, idsOf(page.Records), want)
+			}
+			bookmark = page.Bookmark
+			return nil
+		})
+	}
+}
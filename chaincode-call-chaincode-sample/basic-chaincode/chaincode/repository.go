@@ -0,0 +1,253 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim"
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// Entity is a record that can be stored in the world state by a Repository
+type Entity interface {
+	// KeyAttributes returns the attributes the entity is stored under within its table
+	KeyAttributes() []string
+}
+
+// Repository stores entities of type T as JSON under composite keys made of
+// the name of the struct type and the key attributes of the entity, e.g. ("Asset", "asset1")
+type Repository[T Entity] struct {
+	table string
+}
+
+// NewRepository creates the repository of entity type T
+func NewRepository[T Entity]() *Repository[T] {
+	return &Repository[T]{table: reflect.TypeOf((*T)(nil)).Elem().Name()}
+}
+
+// key builds the composite world state key of the entity with the given key attributes
+func (r *Repository[T]) key(ctx contractapi.TransactionContextInterface, attributes []string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(r.table, attributes)
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s key: %v", strings.ToLower(r.table), err)
+	}
+
+	return key, nil
+}
+
+// notFound returns the error reported when no entity has the given key attributes, e.g. "asset asset1 does not exist"
+func (r *Repository[T]) notFound(attributes []string) error {
+	return fmt.Errorf("%s %s does not exist", strings.ToLower(r.table), strings.Join(attributes, "/"))
+}
+
+// Save creates or replaces the entity in the world state and records the change in the change set of the transaction
+func (r *Repository[T]) Save(ctx contractapi.TransactionContextInterface, entity *T) error {
+	entityJSON, err := json.Marshal(entity)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %v", strings.ToLower(r.table), err)
+	}
+
+	attributes := (*entity).KeyAttributes()
+	key, err := r.key(ctx, attributes)
+	if err != nil {
+		return err
+	}
+
+	previousJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+
+	err = ctx.GetStub().PutState(key, entityJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put %s into world state: %v", strings.ToLower(r.table), err)
+	}
+
+	recordChange(ctx, r.table, attributes, previousJSON, entityJSON)
+	return nil
+}
+
+// Update replaces an entity that already exists in the world state
+func (r *Repository[T]) Update(ctx contractapi.TransactionContextInterface, entity *T) error {
+	attributes := (*entity).KeyAttributes()
+	exists, err := r.Exists(ctx, attributes...)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return r.notFound(attributes)
+	}
+
+	return r.Save(ctx, entity)
+}
+
+// Read retrieves the entity with the given key attributes from the world state
+func (r *Repository[T]) Read(ctx contractapi.TransactionContextInterface, attributes ...string) (*T, error) {
+	key, err := r.key(ctx, attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	entityJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if entityJSON == nil {
+		return nil, r.notFound(attributes)
+	}
+
+	entity := new(T)
+	err = json.Unmarshal(entityJSON, entity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s data: %v", strings.ToLower(r.table), err)
+	}
+
+	return entity, nil
+}
+
+// Delete removes the entity with the given key attributes from the world state
+// and records the change in the change set of the transaction
+func (r *Repository[T]) Delete(ctx contractapi.TransactionContextInterface, attributes ...string) error {
+	key, err := r.key(ctx, attributes)
+	if err != nil {
+		return err
+	}
+
+	previousJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if previousJSON == nil {
+		return r.notFound(attributes)
+	}
+
+	err = ctx.GetStub().DelState(key)
+	if err != nil {
+		return fmt.Errorf("failed to delete %s: %v", strings.ToLower(r.table), err)
+	}
+
+	recordChange(ctx, r.table, attributes, previousJSON, nil)
+	return nil
+}
+
+// Exists checks if an entity with the given key attributes exists in the world state
+func (r *Repository[T]) Exists(ctx contractapi.TransactionContextInterface, attributes ...string) (bool, error) {
+	key, err := r.key(ctx, attributes)
+	if err != nil {
+		return false, err
+	}
+
+	entityJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return false, fmt.Errorf("failed to read from world state: %v", err)
+	}
+
+	return entityJSON != nil, nil
+}
+
+// List returns every entity of the table
+func (r *Repository[T]) List(ctx contractapi.TransactionContextInterface) ([]*T, error) {
+	return r.ListByPartialKey(ctx)
+}
+
+// ListByPartialKey returns every entity of the table whose key attributes start with attributes
+func (r *Repository[T]) ListByPartialKey(ctx contractapi.TransactionContextInterface, attributes ...string) ([]*T, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(r.table, attributes)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	return r.decodeAll(resultsIterator)
+}
+
+// ListPage returns at most pageSize entities of the table whose key attributes start with attributes,
+// starting at bookmark, together with the number of entities fetched and the bookmark of the next page.
+// An empty bookmark starts at the first entity.
+func (r *Repository[T]) ListPage(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string, attributes ...string) ([]*T, int32, string, error) {
+	resultsIterator, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(r.table, attributes, pageSize, bookmark)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	defer resultsIterator.Close()
+
+	entities, err := r.decodeAll(resultsIterator)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	if entities == nil {
+		entities = []*T{}
+	}
+
+	return entities, metadata.FetchedRecordsCount, metadata.Bookmark, nil
+}
+
+// decodeAll unmarshals every value returned by resultsIterator
+func (r *Repository[T]) decodeAll(resultsIterator shim.StateQueryIteratorInterface) ([]*T, error) {
+	var entities []*T
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		entity := new(T)
+		err = json.Unmarshal(queryResponse.Value, entity)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s data: %v", strings.ToLower(r.table), err)
+		}
+		entities = append(entities, entity)
+	}
+
+	return entities, nil
+}
+
+// migrateLegacyKeys moves at most limit entities stored under the former "<Table>||<ID>" keys
+// to composite keys. It returns how many entities were moved and whether legacy keys remain.
+// Migrated keys are deleted, so calling it again resumes where the previous call stopped.
+func (r *Repository[T]) migrateLegacyKeys(ctx contractapi.TransactionContextInterface, limit int) (int, bool, error) {
+	return r.moveLegacyKeys(ctx, limit, r.Save)
+}
+
+// moveLegacyKeys is migrateLegacyKeys storing each moved entity with save
+func (r *Repository[T]) moveLegacyKeys(ctx contractapi.TransactionContextInterface, limit int, save func(contractapi.TransactionContextInterface, *T) error) (int, bool, error) {
+	// "}" follows "|" so the range covers every key starting with "<Table>||"
+	resultsIterator, err := ctx.GetStub().GetStateByRange(r.table+"||", r.table+"|}")
+	if err != nil {
+		return 0, false, err
+	}
+	defer resultsIterator.Close()
+
+	migrated := 0
+	for resultsIterator.HasNext() {
+		if migrated == limit {
+			return migrated, true, nil
+		}
+
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return migrated, false, err
+		}
+
+		entity := new(T)
+		err = json.Unmarshal(queryResponse.Value, entity)
+		if err != nil {
+			return migrated, false, fmt.Errorf("failed to unmarshal %s data at key %s: %v", strings.ToLower(r.table), queryResponse.Key, err)
+		}
+
+		err = save(ctx, entity)
+		if err != nil {
+			return migrated, false, err
+		}
+
+		err = ctx.GetStub().DelState(queryResponse.Key)
+		if err != nil {
+			return migrated, false, fmt.Errorf("failed to delete legacy key %s: %v", queryResponse.Key, err)
+		}
+		migrated++
+	}
+
+	return migrated, false, nil
+}
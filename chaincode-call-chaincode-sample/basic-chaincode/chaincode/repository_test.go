@@ -0,0 +1,71 @@
+package chaincode
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+func TestRepository(t *testing.T) {
+	reader := newIdentity("reader", "Org1MSP")
+	stub := newMockStub()
+
+	mustInvoke(t, stub, reader, func(ctx contractapi.TransactionContextInterface) error {
+		err := users.Update(ctx, &User{ID: "user1", DetailsHash: "hash1"})
+		assertError(t, err, "user user1 does not exist")
+		err = users.Delete(ctx, "user1")
+		assertError(t, err, "user user1 does not exist")
+		_, err = users.Read(ctx, "user1")
+		assertError(t, err, "user user1 does not exist")
+
+		for _, user := range []*User{{ID: "user1", DetailsHash: "hash1"}, {ID: "user2", DetailsHash: "hash2"}} {
+			if err := users.Save(ctx, user); err != nil {
+				return err
+			}
+		}
+		return assets.Save(ctx, &Asset{ID: "user3", Owner: "Max"})
+	})
+
+	if stub.state["\x00User\x00user1\x00"] == nil {
+		t.Fatal("got no user stored under its composite key")
+	}
+
+	mustInvoke(t, stub, reader, func(ctx contractapi.TransactionContextInterface) error {
+		// Assets are in another table, even when their IDs look alike
+		all, err := users.List(ctx)
+		if len(all) != 2 {
+			t.Fatalf("got %d users, want 2", len(all))
+		}
+		if err != nil {
+			return err
+		}
+
+		exists, err := users.Exists(ctx, "user3")
+		if exists {
+			t.Fatalf("got user3 in the user table")
+		}
+		if err != nil {
+			return err
+		}
+
+		user, err := users.Read(ctx, "user2")
+		if err == nil && user.DetailsHash != "hash2" {
+			t.Fatalf("got user %+v, want user2", user)
+		}
+		return err
+	})
+
+	mustInvoke(t, stub, reader, func(ctx contractapi.TransactionContextInterface) error {
+		return users.Update(ctx, &User{ID: "user1", DetailsHash: "hash3"})
+	})
+	mustInvoke(t, stub, reader, func(ctx contractapi.TransactionContextInterface) error {
+		return users.Delete(ctx, "user2")
+	})
+	mustInvoke(t, stub, reader, func(ctx contractapi.TransactionContextInterface) error {
+		all, err := users.List(ctx)
+		if len(all) != 1 || all[0].DetailsHash != "hash3" {
+			t.Fatalf("got users %+v, want only the updated user1", all)
+		}
+		return err
+	})
+}
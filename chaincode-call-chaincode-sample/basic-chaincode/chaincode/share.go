@@ -0,0 +1,81 @@
+package chaincode
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// Fraction records that an asset is locked and split into TotalShares share tokens
+type Fraction struct {
+	AssetID     string `json:"asset_id"`
+	TotalShares int    `json:"total_shares"`
+}
+
+// Share is the number of share tokens of an asset held by a client identity
+type Share struct {
+	AssetID string `json:"asset_id"`
+	Holder  string `json:"holder"`
+	Amount  int    `json:"amount"`
+}
+
+// ShareTransfer is emitted when shares of an asset are issued, transferred or redeemed.
+// From is empty when shares are issued and To is empty when they are redeemed.
+type ShareTransfer struct {
+	AssetID string `json:"asset_id"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Amount  int    `json:"amount"`
+}
+
+// KeyAttributes returns the attributes the fraction record is stored under, the ID of its asset
+func (f Fraction) KeyAttributes() []string {
+	return []string{f.AssetID}
+}
+
+// KeyAttributes returns the attributes the share balance is stored under, its asset and holder
+func (sh Share) KeyAttributes() []string {
+	return []string{sh.AssetID, sh.Holder}
+}
+
+// fractions stores fraction records under ("Fraction", asset) composite keys
+var fractions = NewRepository[Fraction]()
+
+// shares stores share balances under ("Share", asset, holder) composite keys,
+// so that the holders of one asset can be listed by partial key
+var shares = NewRepository[Share]()
+
+// readShare returns the shares of an asset held by holder, with a zero amount if it holds none
+func readShare(ctx contractapi.TransactionContextInterface, assetID string, holder string) (*Share, error) {
+	exists, err := shares.Exists(ctx, assetID, holder)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return &Share{AssetID: assetID, Holder: holder}, nil
+	}
+
+	return shares.Read(ctx, assetID, holder)
+}
+
+// saveShare stores a share balance, removing it when it drops to zero
+func saveShare(ctx contractapi.TransactionContextInterface, share *Share) error {
+	if share.Amount == 0 {
+		return shares.Delete(ctx, share.AssetID, share.Holder)
+	}
+
+	return shares.Save(ctx, share)
+}
+
+// checkNotFractionalized returns an error if the asset is locked into share tokens
+func checkNotFractionalized(ctx contractapi.TransactionContextInterface, assetID string) error {
+	fractionalized, err := fractions.Exists(ctx, assetID)
+	if err != nil {
+		return err
+	}
+	if fractionalized {
+		return fmt.Errorf("asset %s is fractionalized and locked", assetID)
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package chaincode
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// shareBalance returns the committed shares of an asset held by holder
+func shareBalance(t *testing.T, stub *mockStub, id string, holder string) int {
+	t.Helper()
+
+	var balance int
+	mustInvoke(t, stub, newIdentity("reader", "Org2MSP"), func(ctx contractapi.TransactionContextInterface) error {
+		share, err := readShare(ctx, id, holder)
+		if err != nil {
+			return err
+		}
+		balance = share.Amount
+		return nil
+	})
+	return balance
+}
+
+func TestFractionalizeAndRedeemAsset(t *testing.T) {
+	contract := &SmartContract{}
+	alice := newIdentity("alice", "Org1MSP")
+	bob := newIdentity("bob", "Org2MSP")
+	stub := newMockStub()
+
+	mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.CreateAsset(ctx, "asset1", "blue", 5, 300)
+	})
+
+	err := invoke(stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.FractionalizeAsset(ctx, "asset1", 100)
+	})
+	assertError(t, err, "not the owner")
+
+	mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.FractionalizeAsset(ctx, "asset1", 100)
+	})
+	if shares := shareBalance(t, stub, "asset1", alice.id); shares != 100 {
+		t.Fatalf("got %d shares, want 100", shares)
+	}
+
+	// The asset is locked while its shares exist
+	err = invoke(stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.TransferAsset(ctx, "asset1", bob.mspID, bob.id)
+		return err
+	})
+	assertError(t, err, "fractionalized")
+
+	err = invoke(stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.TransferShares(ctx, "asset1", bob.id, 101)
+	})
+	assertError(t, err, "insufficient shares")
+
+	mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.TransferShares(ctx, "asset1", bob.id, 40)
+	})
+	mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		holders, err := contract.GetShareHolders(ctx, "asset1")
+		if len(holders) != 2 {
+			t.Fatalf("got %d share holders, want 2", len(holders))
+		}
+		return err
+	})
+
+	// Only a holder of every share may redeem the asset
+	err = invoke(stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.RedeemAsset(ctx, "asset1")
+	})
+	assertError(t, err, "40 of 100 shares")
+
+	mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.TransferShares(ctx, "asset1", bob.id, 60)
+	})
+	mustInvoke(t, stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.RedeemAsset(ctx, "asset1")
+	})
+	if owner := ownerOf(t, stub, "asset1"); owner != bob.id {
+		t.Fatalf("got owner %s, want %s", owner, bob.id)
+	}
+	if shares := shareBalance(t, stub, "asset1", bob.id); shares != 0 {
+		t.Fatalf("got %d shares left after redeeming, want 0", shares)
+	}
+
+	mustInvoke(t, stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.TransferAsset(ctx, "asset1", alice.mspID, alice.id)
+		return err
+	})
+}
@@ -1,8 +1,10 @@
 package chaincode
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
 )
@@ -14,73 +16,90 @@ type SmartContract struct {
 	contractapi.Contract
 }
 
-// InitLedger adds a base set of assets to the ledger
+// InitLedger adds a base set of assets, owned by the client, to the ledger.
+// Users carry personal details passed in the transient map, so they are only created by CreateUser.
 func (s *SmartContract) InitLedger(ctx contractapi.TransactionContextInterface) error {
-	assets := []Asset{
-		{ID: "asset1", Color: "blue", Size: 5, Owner: "Tomoko", AppraisedValue: 300},
-		{ID: "asset2", Color: "red", Size: 5, Owner: "Brad", AppraisedValue: 400},
-		{ID: "asset3", Color: "green", Size: 10, Owner: "Jin Soo", AppraisedValue: 500},
-		{ID: "asset4", Color: "yellow", Size: 10, Owner: "Max", AppraisedValue: 600},
-		{ID: "asset5", Color: "black", Size: 15, Owner: "Adriana", AppraisedValue: 700},
-		{ID: "asset6", Color: "white", Size: 15, Owner: "Michel", AppraisedValue: 800},
-	}
-
-	users := []User{
-		{ID: "user1", Name: "Quang", Age: 22, Sex: "Male"},
-		{ID: "user2", Name: "Huy", Age: 30, Sex: "Male"},
-		{ID: "user3", Name: "Teo", Age: 21, Sex: "Male"},
-		{ID: "user4", Name: "Thuy", Age: 18, Sex: "Female"},
-		{ID: "user5", Name: "Ha", Age: 12, Sex: "Female"},
-		{ID: "user6", Name: "Hue", Age: 29, Sex: "Female"},
-	}
-
-	for _, asset := range assets {
-		err := asset.Save(ctx)
+	initialAssets := []Asset{
+		{ID: "asset1", Color: "blue", Size: 5, AppraisedValue: 300},
+		{ID: "asset2", Color: "red", Size: 5, AppraisedValue: 400},
+		{ID: "asset3", Color: "green", Size: 10, AppraisedValue: 500},
+		{ID: "asset4", Color: "yellow", Size: 10, AppraisedValue: 600},
+		{ID: "asset5", Color: "black", Size: 15, AppraisedValue: 700},
+		{ID: "asset6", Color: "white", Size: 15, AppraisedValue: 800},
+	}
+
+	clientID, err := getClientAccountID(ctx)
+	if err != nil {
+		return err
+	}
+	clientMSPID, err := getClientMSPID(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, asset := range initialAssets {
+		asset.Owner = clientID
+		asset.OwnerMSP = clientMSPID
+		err := saveAsset(ctx, &asset, nil)
 		if err != nil {
 			return fmt.Errorf("failed to put asset into world state: %v", err)
 		}
-	}
 
-	for _, user := range users {
-		err := user.Save(ctx)
+		err = mintNft(ctx, asset.ID, clientID)
 		if err != nil {
-			return fmt.Errorf("failed to put user into world state: %v", err)
+			return fmt.Errorf("failed to tokenize asset %s: %v", asset.ID, err)
 		}
 	}
 
 	return nil
 }
 
-// CreateAsset issues a new asset to the world state with given details.
-func (s *SmartContract) CreateAsset(ctx contractapi.TransactionContextInterface, id string, color string, size int, owner string, appraisedValue int) error {
+// CreateAsset issues a new asset to the world state with given details, owned by the client.
+func (s *SmartContract) CreateAsset(ctx contractapi.TransactionContextInterface, id string, color string, size int, appraisedValue int) error {
+	exists, err := assets.Exists(ctx, id)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("the asset %s already exists", id)
+	}
+
+	clientID, err := getClientAccountID(ctx)
+	if err != nil {
+		return err
+	}
+	clientMSPID, err := getClientMSPID(ctx)
+	if err != nil {
+		return err
+	}
+
 	asset := Asset{
 		ID:             id,
 		Color:          color,
 		Size:           size,
-		Owner:          owner,
+		Owner:          clientID,
+		OwnerMSP:       clientMSPID,
 		AppraisedValue: appraisedValue,
 	}
 
-	exists, err := IsAssetExists(ctx, id)
+	err = saveAsset(ctx, &asset, nil)
 	if err != nil {
 		return err
 	}
-	if exists {
-		return fmt.Errorf("the asset %s already exists", id)
+
+	// The creator of an asset owns its token
+	err = mintNft(ctx, id, clientID)
+	if err != nil {
+		return err
 	}
 
-	return asset.Save(ctx)
+	return emitEvent(ctx, "Transfer", Transfer{From: "", To: clientID, TokenID: id})
 }
 
-func (s *SmartContract) CreateUser(ctx contractapi.TransactionContextInterface, id string, name string, age int, sex string) error {
-	user := User{
-		ID:   id,
-		Name: name,
-		Age:  age,
-		Sex:  sex,
-	}
-
-	exists, err := IsUserExists(ctx, id)
+// CreateUser registers a user whose personal details are passed in the transient map under "user".
+// The details are stored in the private data collection and only their salted hash in the world state.
+func (s *SmartContract) CreateUser(ctx contractapi.TransactionContextInterface, id string) error {
+	exists, err := users.Exists(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -88,7 +107,12 @@ func (s *SmartContract) CreateUser(ctx contractapi.TransactionContextInterface,
 		return fmt.Errorf("the user %s already exists", id)
 	}
 
-	return user.Save(ctx)
+	details, err := transientUserDetails(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return saveUser(ctx, details)
 }
 
 // ReadAsset returns the asset stored in the world state with given id.
@@ -102,7 +126,7 @@ func (s *SmartContract) ReadAsset(ctx contractapi.TransactionContextInterface, i
 		fmt.Printf("Invoked by chaincode: %s\n", ccName)
 	}
 
-	asset, err := ReadAsset(ctx, id)
+	asset, err := assets.Read(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -120,7 +144,7 @@ func (s *SmartContract) ReadUser(ctx contractapi.TransactionContextInterface, id
 		fmt.Printf("Invoked by chaincode: %s\n", ccName)
 	}
 
-	user, err := ReadUser(ctx, id)
+	user, err := users.Read(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -129,35 +153,43 @@ func (s *SmartContract) ReadUser(ctx contractapi.TransactionContextInterface, id
 }
 
 // UpdateAsset updates an existing asset in the world state with provided parameters.
-func (s *SmartContract) UpdateAsset(ctx contractapi.TransactionContextInterface, id string, color string, size int, owner string, appraisedValue int) error {
+// Only the owner or an asset admin may update an asset; its owner changes through TransferAsset.
+func (s *SmartContract) UpdateAsset(ctx contractapi.TransactionContextInterface, id string, color string, size int, appraisedValue int) error {
+	previous, err := assets.Read(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = checkAssetOwner(ctx, previous)
+	if err != nil {
+		return err
+	}
+
 	asset := Asset{
 		ID:             id,
 		Color:          color,
 		Size:           size,
-		Owner:          owner,
+		Owner:          previous.Owner,
+		OwnerMSP:       previous.OwnerMSP,
 		AppraisedValue: appraisedValue,
 	}
 
-	exists, err := IsAssetExists(ctx, id)
+	err = checkNotFractionalized(ctx, id)
 	if err != nil {
 		return err
 	}
-	if !exists {
-		return fmt.Errorf("the asset %s does not exist", id)
-	}
 
-	return asset.Save(ctx)
+	return saveAsset(ctx, &asset, previous)
 }
 
-func (s *SmartContract) UpdateUser(ctx contractapi.TransactionContextInterface, id string, name string, age int, sex string) error {
-	user := User{
-		ID:   id,
-		Name: name,
-		Age:  age,
-		Sex:  sex,
+// UpdateUser replaces the personal details of a user with those passed in the transient map under "user".
+// Only the admin organization may update users.
+func (s *SmartContract) UpdateUser(ctx contractapi.TransactionContextInterface, id string) error {
+	if err := checkAdmin(ctx); err != nil {
+		return err
 	}
 
-	exists, err := IsUserExists(ctx, id)
+	exists, err := users.Exists(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -165,29 +197,119 @@ func (s *SmartContract) UpdateUser(ctx contractapi.TransactionContextInterface,
 		return fmt.Errorf("the user %s does not exist", id)
 	}
 
-	return user.Save(ctx)
+	details, err := transientUserDetails(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return saveUser(ctx, details)
 }
 
-// DeleteAsset deletes an asset from the world state.
+// DeleteAsset deletes an asset from the world state, burning its token.
+// Only the owner or an asset admin may delete an asset.
 func (s *SmartContract) DeleteAsset(ctx contractapi.TransactionContextInterface, id string) error {
-	return DeleteAsset(ctx, id)
+	asset, err := assets.Read(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	nft, err := checkAssetOwner(ctx, asset)
+	if err != nil {
+		return err
+	}
+	if nft == nil {
+		return deleteAsset(ctx, asset)
+	}
+
+	err = checkNotFractionalized(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = deleteAsset(ctx, asset)
+	if err != nil {
+		return err
+	}
+
+	err = clearOffer(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = nfts.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, "Transfer", Transfer{From: nft.Owner, To: "", TokenID: id})
 }
 
+// DeleteUser removes a user and its personal details. Only the admin organization may delete users.
 func (s *SmartContract) DeleteUser(ctx contractapi.TransactionContextInterface, id string) error {
-	return DeleteUser(ctx, id)
+	if err := checkAdmin(ctx); err != nil {
+		return err
+	}
+
+	err := users.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return deleteUserDetails(ctx, id, false)
+}
+
+// ReadUserDetails returns the personal details of a user. Only members of the collection can read them.
+func (s *SmartContract) ReadUserDetails(ctx contractapi.TransactionContextInterface, id string) (*UserDetails, error) {
+	return readUserDetails(ctx, id)
+}
+
+// PurgeUserDetails erases the personal details of a user, and every earlier version of them,
+// from the private data store of the peers to honour an erasure request. The public record of
+// the user is kept with an empty hash. Only the admin organization may purge user details.
+func (s *SmartContract) PurgeUserDetails(ctx contractapi.TransactionContextInterface, id string) error {
+	if err := checkAdmin(ctx); err != nil {
+		return err
+	}
+
+	_, err := users.Read(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = deleteUserDetails(ctx, id, true)
+	if err != nil {
+		return err
+	}
+
+	return users.Save(ctx, &User{ID: id})
 }
 
-// TransferAsset updates the owner field of an asset with the given id in the world state, and returns the old owner.
-func (s *SmartContract) TransferAsset(ctx contractapi.TransactionContextInterface, id string, newOwner string) (string, error) {
-	asset, err := ReadAsset(ctx, id)
+// TransferAsset moves the token of an asset to newOwner, a client ID of the organization newOwnerMSP,
+// and returns the old owner.
+// Only the owner of the token, an asset admin, its approved account or an approved operator may transfer it.
+func (s *SmartContract) TransferAsset(ctx contractapi.TransactionContextInterface, id string, newOwnerMSP string, newOwner string) (string, error) {
+	if newOwnerMSP == "" {
+		return "", fmt.Errorf("the MSP ID of the new owner must be specified")
+	}
+
+	asset, err := assets.Read(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	nft, err := checkTokenOperator(ctx, asset)
 	if err != nil {
 		return "", err
 	}
 
 	oldOwner := asset.Owner
-	asset.Owner = newOwner
+	from := nft.Owner
+	err = transferNft(ctx, nft, asset, newOwnerMSP, newOwner)
+	if err != nil {
+		return "", err
+	}
 
-	err = asset.Save(ctx)
+	err = emitEvent(ctx, "Transfer", Transfer{From: from, To: newOwner, TokenID: id})
 	if err != nil {
 		return "", err
 	}
@@ -195,6 +317,593 @@ func (s *SmartContract) TransferAsset(ctx contractapi.TransactionContextInterfac
 	return oldOwner, nil
 }
 
+// OfferAsset offers to transfer an asset owned by the client to recipient, a client ID of the organization
+// recipientMSP, who may accept the offer within ttlSeconds of this transaction. The asset keeps its owner until
+// then, and an asset has at most one pending offer. Only the owner or an asset admin may offer an asset.
+func (s *SmartContract) OfferAsset(ctx contractapi.TransactionContextInterface, id string, recipientMSP string, recipient string, ttlSeconds int) error {
+	if recipient == "" || recipientMSP == "" {
+		return fmt.Errorf("offer to an empty account")
+	}
+	if ttlSeconds <= 0 {
+		return fmt.Errorf("offer lifetime must be a positive number of seconds")
+	}
+
+	asset, err := assets.Read(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	nft, err := checkAssetOwner(ctx, asset)
+	if err != nil {
+		return err
+	}
+	if nft == nil {
+		return fmt.Errorf("asset %s is not tokenized", id)
+	}
+	if recipient == nft.Owner {
+		return fmt.Errorf("cannot offer asset %s to its owner", id)
+	}
+
+	err = checkNotFractionalized(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	// An expired offer no longer blocks a new one
+	exists, err := offers.Exists(ctx, id)
+	if err != nil {
+		return err
+	}
+	if exists {
+		previous, err := offers.Read(ctx, id)
+		if err != nil {
+			return err
+		}
+		expired, err := previous.expired(now)
+		if err != nil {
+			return err
+		}
+		if !expired {
+			return fmt.Errorf("asset %s already has a pending offer", id)
+		}
+		err = deleteOffer(ctx, previous)
+		if err != nil {
+			return err
+		}
+	}
+
+	offer := TransferOffer{
+		AssetID:      id,
+		Owner:        nft.Owner,
+		Recipient:    recipient,
+		RecipientMSP: recipientMSP,
+		ExpiresAt:    now.Add(time.Duration(ttlSeconds) * time.Second).UTC().Format(time.RFC3339),
+	}
+	err = saveOffer(ctx, &offer)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, "TransferOffer", TransferOfferEvent{AssetID: id, Owner: offer.Owner, Recipient: recipient, Status: offerCreated})
+}
+
+// AcceptOffer transfers an asset offered to the client to the client.
+// The offer must not have expired and the asset must still belong to the identity that offered it.
+func (s *SmartContract) AcceptOffer(ctx contractapi.TransactionContextInterface, id string) error {
+	offer, err := offers.Read(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = checkOfferRecipient(ctx, offer)
+	if err != nil {
+		return err
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+	expired, err := offer.expired(now)
+	if err != nil {
+		return err
+	}
+	if expired {
+		return fmt.Errorf("the offer for asset %s expired at %s", id, offer.ExpiresAt)
+	}
+
+	asset, err := assets.Read(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	nft, err := nfts.Read(ctx, id)
+	if err != nil {
+		return err
+	}
+	if nft.Owner != offer.Owner {
+		return fmt.Errorf("asset %s no longer belongs to the account that offered it", id)
+	}
+
+	err = checkNotFractionalized(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// transferNft also removes the offer
+	err = transferNft(ctx, nft, asset, offer.RecipientMSP, offer.Recipient)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, "TransferOffer", TransferOfferEvent{AssetID: id, Owner: offer.Owner, Recipient: offer.Recipient, Status: offerAccepted})
+}
+
+// RejectOffer declines an offer made to the client
+func (s *SmartContract) RejectOffer(ctx contractapi.TransactionContextInterface, id string) error {
+	offer, err := offers.Read(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = checkOfferRecipient(ctx, offer)
+	if err != nil {
+		return err
+	}
+
+	err = deleteOffer(ctx, offer)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, "TransferOffer", TransferOfferEvent{AssetID: id, Owner: offer.Owner, Recipient: offer.Recipient, Status: offerRejected})
+}
+
+// CancelOffer withdraws an offer of the owner of an asset. Only the owner or an asset admin may cancel it.
+func (s *SmartContract) CancelOffer(ctx contractapi.TransactionContextInterface, id string) error {
+	offer, err := offers.Read(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	asset, err := assets.Read(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = checkAssetOwner(ctx, asset)
+	if err != nil {
+		return err
+	}
+
+	err = deleteOffer(ctx, offer)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, "TransferOffer", TransferOfferEvent{AssetID: id, Owner: offer.Owner, Recipient: offer.Recipient, Status: offerCancelled})
+}
+
+// ReadOffer returns the offer for an asset, which may have expired
+func (s *SmartContract) ReadOffer(ctx contractapi.TransactionContextInterface, id string) (*TransferOffer, error) {
+	return offers.Read(ctx, id)
+}
+
+// GetInboundOffers returns the offers made to recipient that have not expired
+func (s *SmartContract) GetInboundOffers(ctx contractapi.TransactionContextInterface, recipient string) ([]*TransferOffer, error) {
+	return pendingOffers(ctx, offerRecipientIndex, recipient)
+}
+
+// GetOutboundOffers returns the offers made by owner that have not expired
+func (s *SmartContract) GetOutboundOffers(ctx contractapi.TransactionContextInterface, owner string) ([]*TransferOffer, error) {
+	return pendingOffers(ctx, offerOwnerIndex, owner)
+}
+
+// TokenizeAsset issues the token of an asset created before assets were tokenized and assigns it,
+// and the asset, to owner, a client ID of the organization ownerMSP.
+// Only the admin organization may tokenize assets.
+func (s *SmartContract) TokenizeAsset(ctx contractapi.TransactionContextInterface, id string, ownerMSP string, owner string) error {
+	if err := checkAdmin(ctx); err != nil {
+		return err
+	}
+	if owner == "" || ownerMSP == "" {
+		return fmt.Errorf("mint to an empty account")
+	}
+
+	asset, err := assets.Read(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = mintNft(ctx, id, owner)
+	if err != nil {
+		return err
+	}
+
+	previous := *asset
+	asset.Owner = owner
+	asset.OwnerMSP = ownerMSP
+	err = saveAsset(ctx, asset, &previous)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, "Transfer", Transfer{From: "", To: owner, TokenID: id})
+}
+
+// OwnerOf returns the client identity owning the token of an asset
+func (s *SmartContract) OwnerOf(ctx contractapi.TransactionContextInterface, tokenID string) (string, error) {
+	nft, err := nfts.Read(ctx, tokenID)
+	if err != nil {
+		return "", err
+	}
+
+	return nft.Owner, nil
+}
+
+// Approve allows approved to transfer the token of an asset, an empty approved clears the approval.
+// The client must be the owner of the token or an approved operator.
+func (s *SmartContract) Approve(ctx contractapi.TransactionContextInterface, approved string, tokenID string) error {
+	nft, err := nfts.Read(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+
+	clientID, err := getClientAccountID(ctx)
+	if err != nil {
+		return err
+	}
+	if clientID != nft.Owner {
+		operator, err := isApprovedForAll(ctx, nft.Owner, clientID)
+		if err != nil {
+			return err
+		}
+		if !operator {
+			return errUnauthorized("client is not the owner of asset %s nor is approved for all", tokenID)
+		}
+	}
+
+	nft.Approved = approved
+	err = nfts.Save(ctx, nft)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, "Approval", Approval{Owner: nft.Owner, Approved: approved, TokenID: tokenID})
+}
+
+// GetApproved returns the account approved to transfer the token of an asset, empty if none
+func (s *SmartContract) GetApproved(ctx contractapi.TransactionContextInterface, tokenID string) (string, error) {
+	nft, err := nfts.Read(ctx, tokenID)
+	if err != nil {
+		return "", err
+	}
+
+	return nft.Approved, nil
+}
+
+// SetApprovalForAll allows or disallows operator to manage every token of the invoking client
+func (s *SmartContract) SetApprovalForAll(ctx contractapi.TransactionContextInterface, operator string, approved bool) error {
+	clientID, err := getClientAccountID(ctx)
+	if err != nil {
+		return err
+	}
+	if clientID == operator {
+		return fmt.Errorf("setting approval status for self")
+	}
+
+	err = setApprovalForAll(ctx, clientID, operator, approved)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, "ApprovalForAll", ApprovalForAll{Owner: clientID, Operator: operator, Approved: approved})
+}
+
+// IsApprovedForAll returns true if operator may manage every token of owner
+func (s *SmartContract) IsApprovedForAll(ctx contractapi.TransactionContextInterface, owner string, operator string) (bool, error) {
+	return isApprovedForAll(ctx, owner, operator)
+}
+
+// TransferFrom moves the token of an asset from one client identity to to, a client ID of the organization toMSP
+func (s *SmartContract) TransferFrom(ctx contractapi.TransactionContextInterface, from string, toMSP string, to string, tokenID string) error {
+	asset, err := assets.Read(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+
+	nft, err := checkTokenOperator(ctx, asset)
+	if err != nil {
+		return err
+	}
+	if nft.Owner != from {
+		return fmt.Errorf("account %s is not the owner of asset %s", from, tokenID)
+	}
+
+	err = transferNft(ctx, nft, asset, toMSP, to)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, "Transfer", Transfer{From: from, To: to, TokenID: tokenID})
+}
+
+// FractionalizeAsset locks an asset owned by the client and issues totalShares share tokens of it to the client.
+// While fractionalized the asset cannot be updated, transferred or deleted.
+func (s *SmartContract) FractionalizeAsset(ctx contractapi.TransactionContextInterface, id string, totalShares int) error {
+	if totalShares <= 0 {
+		return fmt.Errorf("total shares must be a positive integer")
+	}
+
+	nft, err := nfts.Read(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	clientID, err := getClientAccountID(ctx)
+	if err != nil {
+		return err
+	}
+	if clientID != nft.Owner {
+		return errUnauthorized("client is not the owner of asset %s", id)
+	}
+
+	err = checkNotFractionalized(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	fraction := Fraction{AssetID: id, TotalShares: totalShares}
+	err = fractions.Save(ctx, &fraction)
+	if err != nil {
+		return err
+	}
+
+	share := Share{AssetID: id, Holder: clientID, Amount: totalShares}
+	err = shares.Save(ctx, &share)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, "ShareTransfer", ShareTransfer{AssetID: id, From: "", To: clientID, Amount: totalShares})
+}
+
+// TransferShares moves amount shares of an asset from the client to another client identity
+func (s *SmartContract) TransferShares(ctx contractapi.TransactionContextInterface, id string, to string, amount int) error {
+	if amount <= 0 {
+		return fmt.Errorf("transfer amount must be a positive integer")
+	}
+	if to == "" {
+		return fmt.Errorf("transfer to an empty account")
+	}
+
+	_, err := fractions.Read(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	clientID, err := getClientAccountID(ctx)
+	if err != nil {
+		return err
+	}
+	if clientID == to {
+		return fmt.Errorf("cannot transfer shares to and from the same account")
+	}
+
+	fromShare, err := readShare(ctx, id, clientID)
+	if err != nil {
+		return err
+	}
+	if fromShare.Amount < amount {
+		return fmt.Errorf("client has insufficient shares of asset %s", id)
+	}
+
+	toShare, err := readShare(ctx, id, to)
+	if err != nil {
+		return err
+	}
+
+	fromShare.Amount -= amount
+	err = saveShare(ctx, fromShare)
+	if err != nil {
+		return err
+	}
+
+	toShare.Amount += amount
+	err = saveShare(ctx, toShare)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, "ShareTransfer", ShareTransfer{AssetID: id, From: clientID, To: to, Amount: amount})
+}
+
+// RedeemAsset burns every share of an asset held by the client, unlocks the asset and makes the client its sole owner.
+// The client must hold all shares of the asset.
+func (s *SmartContract) RedeemAsset(ctx contractapi.TransactionContextInterface, id string) error {
+	fraction, err := fractions.Read(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	clientID, err := getClientAccountID(ctx)
+	if err != nil {
+		return err
+	}
+
+	share, err := readShare(ctx, id, clientID)
+	if err != nil {
+		return err
+	}
+	if share.Amount != fraction.TotalShares {
+		return fmt.Errorf("client holds %d of %d shares of asset %s", share.Amount, fraction.TotalShares, id)
+	}
+
+	share.Amount = 0
+	err = saveShare(ctx, share)
+	if err != nil {
+		return err
+	}
+
+	err = fractions.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	asset, err := assets.Read(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	nft, err := nfts.Read(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	clientMSPID, err := getClientMSPID(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = transferNft(ctx, nft, asset, clientMSPID, clientID)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, "ShareTransfer", ShareTransfer{AssetID: id, From: clientID, To: "", Amount: fraction.TotalShares})
+}
+
+// ReadFraction returns the fraction record of an asset
+func (s *SmartContract) ReadFraction(ctx contractapi.TransactionContextInterface, id string) (*Fraction, error) {
+	return fractions.Read(ctx, id)
+}
+
+// GetShareBalance returns the number of shares of an asset held by holder
+func (s *SmartContract) GetShareBalance(ctx contractapi.TransactionContextInterface, id string, holder string) (int, error) {
+	share, err := readShare(ctx, id, holder)
+	if err != nil {
+		return 0, err
+	}
+
+	return share.Amount, nil
+}
+
+// GetShareHolders returns every holder of shares of an asset with its balance
+func (s *SmartContract) GetShareHolders(ctx contractapi.TransactionContextInterface, id string) ([]*Share, error) {
+	return shares.ListByPartialKey(ctx, id)
+}
+
+// TokenURI returns the metadata of the token of an asset as a data URI built from the asset record
+func (s *SmartContract) TokenURI(ctx contractapi.TransactionContextInterface, tokenID string) (string, error) {
+	exists, err := nfts.Exists(ctx, tokenID)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", fmt.Errorf("asset %s has not been tokenized", tokenID)
+	}
+
+	asset, err := assets.Read(ctx, tokenID)
+	if err != nil {
+		return "", err
+	}
+
+	metadataJSON, err := json.Marshal(asset)
+	if err != nil {
+		return "", fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	return "data:application/json;base64," + base64.StdEncoding.EncodeToString(metadataJSON), nil
+}
+
+// GetAssetHistory returns every version of an asset, oldest first, with the fields changed by each transaction
+func (s *SmartContract) GetAssetHistory(ctx contractapi.TransactionContextInterface, id string) ([]*AssetVersion, error) {
+	revisions, err := assets.History(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	history := []*AssetVersion{}
+	for _, rev := range revisions {
+		history = append(history, &AssetVersion{TxID: rev.TxID, Timestamp: rev.Timestamp, IsDelete: rev.IsDelete, Value: rev.Value, Changes: rev.Changes})
+	}
+
+	return history, nil
+}
+
+// GetUserHistory returns every version of a user, oldest first, with the fields changed by each transaction
+func (s *SmartContract) GetUserHistory(ctx contractapi.TransactionContextInterface, id string) ([]*UserVersion, error) {
+	revisions, err := users.History(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	history := []*UserVersion{}
+	for _, rev := range revisions {
+		history = append(history, &UserVersion{TxID: rev.TxID, Timestamp: rev.Timestamp, IsDelete: rev.IsDelete, Value: rev.Value, Changes: rev.Changes})
+	}
+
+	return history, nil
+}
+
+// QueryAssetsByOwner returns the assets whose Owner field equals owner
+func (s *SmartContract) QueryAssetsByOwner(ctx contractapi.TransactionContextInterface, owner string) ([]*Asset, error) {
+	return queryAssetsByIndex(ctx, ownerIndex, owner)
+}
+
+// QueryAssetsByColor returns the assets of the given color
+func (s *SmartContract) QueryAssetsByColor(ctx contractapi.TransactionContextInterface, color string) ([]*Asset, error) {
+	return queryAssetsByIndex(ctx, colorIndex, color)
+}
+
+// RebuildIndexes recreates the owner and color indexes from the stored assets and returns how many assets were indexed.
+// Run it once to index the assets stored before the indexes existed; MigrateKeys indexes the assets it migrates.
+// Only the admin organization may rebuild indexes.
+func (s *SmartContract) RebuildIndexes(ctx contractapi.TransactionContextInterface) (int, error) {
+	if err := checkAdmin(ctx); err != nil {
+		return 0, err
+	}
+
+	return rebuildIndexes(ctx)
+}
+
+// MigrateKeys rewrites at most batchSize records stored under the former "Table||ID" keys to composite keys.
+// Users are left to MigrateUserDetails, so that their personal details are never written to a new public key.
+// Call it repeatedly until it reports done; each call resumes where the previous one stopped.
+// Only the admin organization may migrate keys.
+func (s *SmartContract) MigrateKeys(ctx contractapi.TransactionContextInterface, batchSize int) (*MigrationProgress, error) {
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if batchSize <= 0 {
+		return nil, fmt.Errorf("batch size must be a positive integer")
+	}
+
+	return migrateKeys(ctx, batchSize)
+}
+
+// MigrateUserDetails moves the personal details of the users on one page of at most batchSize users,
+// written to the world state before they were kept private, to the private data collection, leaving only
+// their salted hash public. The salt of every user is derived from a secret of at least 16 bytes passed in
+// the transient map under "salt". Call it with an empty bookmark first, then repeatedly with the same secret
+// and the bookmark it returns until it reports done. Users under the former "User||ID" keys are moved to
+// composite keys too, as MigrateKeys leaves them alone. Only the admin organization may migrate user details.
+func (s *SmartContract) MigrateUserDetails(ctx contractapi.TransactionContextInterface, batchSize int32, bookmark string) (*MigrationProgress, error) {
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if batchSize <= 0 {
+		return nil, fmt.Errorf("batch size must be a positive integer")
+	}
+
+	return migrateUserDetails(ctx, batchSize, bookmark)
+}
+
 // // GetAllAssets returns all assets found in the world state
 // func (s *SmartContract) GetAllAssets(ctx contractapi.TransactionContextInterface) ([]*Asset, error) {
 // 	// range query with empty string for startKey and endKey does an
@@ -223,56 +932,58 @@ func (s *SmartContract) TransferAsset(ctx contractapi.TransactionContextInterfac
 // 	return assets, nil
 // }
 
+// GetAllAssets returns all assets found in the world state
 func (s *SmartContract) GetAllAssets(ctx contractapi.TransactionContextInterface) ([]*Asset, error) {
-	// Query only keys that start with "Asset||" to filter out non-asset records
-	resultsIterator, err := ctx.GetStub().GetStateByRange("Asset||", "Asset||\ufff0")
+	return assets.List(ctx)
+}
+
+// GetAllUsers returns all users found in the world state
+func (s *SmartContract) GetAllUsers(ctx contractapi.TransactionContextInterface) ([]*User, error) {
+	return users.List(ctx)
+}
+
+// GetAssetsWithPagination returns at most pageSize assets starting at bookmark, and the bookmark of the next page.
+// An empty bookmark returns the first page. pageSize must be positive and is capped at maxPageSize.
+func (s *SmartContract) GetAssetsWithPagination(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*AssetPage, error) {
+	pageSize, err := checkPageSize(pageSize)
 	if err != nil {
 		return nil, err
 	}
-	defer resultsIterator.Close()
-
-	var assets []*Asset
-	for resultsIterator.HasNext() {
-		queryResponse, err := resultsIterator.Next()
-		if err != nil {
-			return nil, err
-		}
 
-		var asset Asset
-		err = json.Unmarshal(queryResponse.Value, &asset)
-		if err != nil {
-			return nil, err
-		}
-		assets = append(assets, &asset)
+	records, fetched, nextBookmark, err := assets.ListPage(ctx, pageSize, bookmark)
+	if err != nil {
+		return nil, err
 	}
 
-	return assets, nil
+	return &AssetPage{Records: records, FetchedRecordsCount: fetched, Bookmark: nextBookmark}, nil
 }
 
-// GetAllAssets returns all assets found in the world state
-func (s *SmartContract) GetAllUsers(ctx contractapi.TransactionContextInterface) ([]*User, error) {
-	// range query with empty string for startKey and endKey does an
-	// open-ended query of all assets in the chaincode namespace.
-	resultsIterator, err := ctx.GetStub().GetStateByRange("User||", "User||\ufff0")
+// QueryAssets returns the assets matching a CouchDB selector, e.g. {"owner":"Tomoko"}.
+// It requires CouchDB as the state database; the indexes under META-INF/statedb/couchdb/indexes
+// cover selectors on owner, color and appraised_value.
+func (s *SmartContract) QueryAssets(ctx contractapi.TransactionContextInterface, selectorJSON string) ([]*Asset, error) {
+	return queryAssets(ctx, selectorJSON)
+}
+
+// QueryAssetsWithPagination returns at most pageSize assets matching a CouchDB selector starting at bookmark,
+// and the bookmark of the next page. An empty bookmark returns the first page.
+// pageSize must be positive and is capped at maxPageSize.
+func (s *SmartContract) QueryAssetsWithPagination(ctx contractapi.TransactionContextInterface, selectorJSON string, pageSize int32, bookmark string) (*AssetPage, error) {
+	return queryAssetsPage(ctx, selectorJSON, pageSize, bookmark)
+}
+
+// GetUsersWithPagination returns at most pageSize users starting at bookmark, and the bookmark of the next page.
+// An empty bookmark returns the first page. pageSize must be positive and is capped at maxPageSize.
+func (s *SmartContract) GetUsersWithPagination(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*UserPage, error) {
+	pageSize, err := checkPageSize(pageSize)
 	if err != nil {
 		return nil, err
 	}
-	defer resultsIterator.Close()
 
-	var users []*User
-	for resultsIterator.HasNext() {
-		queryResponse, err := resultsIterator.Next()
-		if err != nil {
-			return nil, err
-		}
-
-		var user User
-		err = json.Unmarshal(queryResponse.Value, &user)
-		if err != nil {
-			return nil, err
-		}
-		users = append(users, &user)
+	records, fetched, nextBookmark, err := users.ListPage(ctx, pageSize, bookmark)
+	if err != nil {
+		return nil, err
 	}
 
-	return users, nil
+	return &UserPage{Records: records, FetchedRecordsCount: fetched, Bookmark: nextBookmark}, nil
 }
@@ -0,0 +1,511 @@
+package chaincode
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim"
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+	"github.com/hyperledger/fabric-protos-go-apiv2/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// mockStub is an in-memory ChaincodeStubInterface that behaves like a peer: reads see the state
+// committed before the transaction, never its own writes, and the writes of a transaction are
+// only applied when it commits. Rich queries evaluate CouchDB selectors over the JSON values of the
+// world state, supporting field equality, $and, $exists, $eq, $gt, $gte, $lt and $lte.
+// Private data collections follow the same rules, and the transient map is the one set by the test.
+// Methods the contract does not use panic.
+type mockStub struct {
+	shim.ChaincodeStubInterface
+	state     map[string][]byte
+	writes    map[string][]byte
+	private   map[string]map[string][]byte
+	pending   map[string]map[string][]byte
+	transient map[string][]byte
+	history   map[string][]*queryresult.KeyModification
+	txNumber  int
+	timestamp time.Time
+	event     *mockEvent
+}
+
+// mockEvent is the chaincode event set by a transaction
+type mockEvent struct {
+	name    string
+	payload []byte
+}
+
+func newMockStub() *mockStub {
+	return &mockStub{
+		state:     map[string][]byte{},
+		writes:    map[string][]byte{},
+		private:   map[string]map[string][]byte{},
+		pending:   map[string]map[string][]byte{},
+		history:   map[string][]*queryresult.KeyModification{},
+		timestamp: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+// begin starts a new transaction, discarding the writes of any transaction that did not commit
+func (s *mockStub) begin() {
+	s.txNumber++
+	s.writes = map[string][]byte{}
+	s.pending = map[string]map[string][]byte{}
+	s.event = nil
+}
+
+// commit applies the writes of the transaction to the world state and records them in the history
+// of their keys. A nil value deletes its key.
+func (s *mockStub) commit() {
+	for key, value := range s.writes {
+		s.history[key] = append(s.history[key], &queryresult.KeyModification{
+			TxId:      s.GetTxID(),
+			Value:     value,
+			Timestamp: timestamppb.New(s.timestamp),
+			IsDelete:  value == nil,
+		})
+		if value == nil {
+			delete(s.state, key)
+			continue
+		}
+		s.state[key] = value
+	}
+	s.writes = map[string][]byte{}
+
+	for collection, writes := range s.pending {
+		if s.private[collection] == nil {
+			s.private[collection] = map[string][]byte{}
+		}
+		for key, value := range writes {
+			if value == nil {
+				delete(s.private[collection], key)
+				continue
+			}
+			s.private[collection][key] = value
+		}
+	}
+	s.pending = map[string]map[string][]byte{}
+}
+
+func (s *mockStub) GetTxID() string {
+	return fmt.Sprintf("tx%d", s.txNumber)
+}
+
+func (s *mockStub) GetTxTimestamp() (*timestamppb.Timestamp, error) {
+	return timestamppb.New(s.timestamp), nil
+}
+
+// SetEvent replaces the event of the transaction, as Fabric keeps only the last one
+func (s *mockStub) SetEvent(name string, payload []byte) error {
+	s.event = &mockEvent{name: name, payload: payload}
+	return nil
+}
+
+func (s *mockStub) GetState(key string) ([]byte, error) {
+	return s.state[key], nil
+}
+
+func (s *mockStub) PutState(key string, value []byte) error {
+	if value == nil {
+		value = []byte{}
+	}
+	s.writes[key] = value
+	return nil
+}
+
+func (s *mockStub) DelState(key string) error {
+	s.writes[key] = nil
+	return nil
+}
+
+func (s *mockStub) GetTransient() (map[string][]byte, error) {
+	return s.transient, nil
+}
+
+func (s *mockStub) GetPrivateData(collection string, key string) ([]byte, error) {
+	return s.private[collection][key], nil
+}
+
+func (s *mockStub) PutPrivateData(collection string, key string, value []byte) error {
+	if value == nil {
+		value = []byte{}
+	}
+	s.writePrivateData(collection, key, value)
+	return nil
+}
+
+func (s *mockStub) DelPrivateData(collection string, key string) error {
+	s.writePrivateData(collection, key, nil)
+	return nil
+}
+
+// PurgePrivateData deletes the key like DelPrivateData, as the stub keeps no history of private data
+func (s *mockStub) PurgePrivateData(collection string, key string) error {
+	s.writePrivateData(collection, key, nil)
+	return nil
+}
+
+// writePrivateData queues a write to a collection until the transaction commits. A nil value deletes its key.
+func (s *mockStub) writePrivateData(collection string, key string, value []byte) {
+	if s.pending[collection] == nil {
+		s.pending[collection] = map[string][]byte{}
+	}
+	s.pending[collection][key] = value
+}
+
+func (s *mockStub) CreateCompositeKey(objectType string, attributes []string) (string, error) {
+	key := "\x00" + objectType + "\x00"
+	for _, attribute := range attributes {
+		key += attribute + "\x00"
+	}
+	return key, nil
+}
+
+func (s *mockStub) SplitCompositeKey(compositeKey string) (string, []string, error) {
+	parts := strings.Split(strings.TrimSuffix(strings.TrimPrefix(compositeKey, "\x00"), "\x00"), "\x00")
+	return parts[0], parts[1:], nil
+}
+
+// sortedKeys returns the committed keys accepted by keep, in order
+func (s *mockStub) sortedKeys(keep func(key string) bool) []string {
+	var keys []string
+	for key := range s.state {
+		if keep(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// iterator snapshots the committed values of keys
+func (s *mockStub) iterator(keys []string) *mockIterator {
+	results := []*queryresult.KV{}
+	for _, key := range keys {
+		results = append(results, &queryresult.KV{Key: key, Value: s.state[key]})
+	}
+	return &mockIterator{results: results}
+}
+
+func (s *mockStub) GetStateByRange(startKey, endKey string) (shim.StateQueryIteratorInterface, error) {
+	keys := s.sortedKeys(func(key string) bool {
+		return key >= startKey && (endKey == "" || key < endKey)
+	})
+	return s.iterator(keys), nil
+}
+
+func (s *mockStub) GetStateByPartialCompositeKey(objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+	prefix, err := s.CreateCompositeKey(objectType, attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := s.sortedKeys(func(key string) bool { return strings.HasPrefix(key, prefix) })
+	return s.iterator(keys), nil
+}
+
+// GetStateByPartialCompositeKeyWithPagination returns at most pageSize keys starting at bookmark.
+// The bookmark of the next page is its first key, empty on the last page.
+func (s *mockStub) GetStateByPartialCompositeKeyWithPagination(objectType string, keys []string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	prefix, err := s.CreateCompositeKey(objectType, keys)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	matches := s.sortedKeys(func(key string) bool { return strings.HasPrefix(key, prefix) && key >= bookmark })
+	next := ""
+	if len(matches) > int(pageSize) {
+		next = matches[pageSize]
+		matches = matches[:pageSize]
+	}
+	return s.iterator(matches), &peer.QueryResponseMetadata{FetchedRecordsCount: int32(len(matches)), Bookmark: next}, nil
+}
+
+func (s *mockStub) GetQueryResult(query string) (shim.StateQueryIteratorInterface, error) {
+	keys, err := s.queryKeys(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.iterator(keys), nil
+}
+
+// GetQueryResultWithPagination uses the last key of a page as the bookmark of the next page
+func (s *mockStub) GetQueryResultWithPagination(query string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	keys, err := s.queryKeys(query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var page []string
+	for _, key := range keys {
+		if key > bookmark && int32(len(page)) < pageSize {
+			page = append(page, key)
+		}
+	}
+
+	metadata := &peer.QueryResponseMetadata{FetchedRecordsCount: int32(len(page))}
+	if len(page) > 0 {
+		metadata.Bookmark = page[len(page)-1]
+	}
+	return s.iterator(page), metadata, nil
+}
+
+// queryKeys returns the keys of the JSON documents matching the selector of query, in order.
+// Like CouchDB, it never matches values that are not JSON objects, such as index entries.
+func (s *mockStub) queryKeys(query string) ([]string, error) {
+	var parsed struct {
+		Selector map[string]interface{} `json:"selector"`
+	}
+	err := json.Unmarshal([]byte(query), &parsed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query %s: %v", query, err)
+	}
+
+	var keys []string
+	for _, key := range s.sortedKeys(func(string) bool { return true }) {
+		var doc map[string]interface{}
+		if json.Unmarshal(s.state[key], &doc) != nil {
+			continue
+		}
+
+		matched, err := matchSelector(doc, parsed.Selector)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys, nil
+}
+
+// matchSelector reports whether doc matches a CouchDB selector
+func matchSelector(doc map[string]interface{}, selector map[string]interface{}) (bool, error) {
+	for field, condition := range selector {
+		if field == "$and" {
+			clauses, ok := condition.([]interface{})
+			if !ok {
+				return false, fmt.Errorf("$and takes an array of selectors")
+			}
+			for _, clause := range clauses {
+				clauseSelector, ok := clause.(map[string]interface{})
+				if !ok {
+					return false, fmt.Errorf("$and takes an array of selectors")
+				}
+				matched, err := matchSelector(doc, clauseSelector)
+				if err != nil || !matched {
+					return false, err
+				}
+			}
+			continue
+		}
+		if strings.HasPrefix(field, "$") {
+			return false, fmt.Errorf("unsupported operator %s", field)
+		}
+
+		value, exists := doc[field]
+		matched, err := matchCondition(value, exists, condition)
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// matchCondition reports whether a field of a document matches condition, either a value
+// it must equal or an object of operators
+func matchCondition(value interface{}, exists bool, condition interface{}) (bool, error) {
+	operators, ok := condition.(map[string]interface{})
+	if !ok {
+		return exists && reflect.DeepEqual(value, condition), nil
+	}
+
+	for operator, operand := range operators {
+		var matched bool
+		switch operator {
+		case "$exists":
+			want, ok := operand.(bool)
+			if !ok {
+				return false, fmt.Errorf("$exists takes a boolean")
+			}
+			matched = exists == want
+		case "$eq":
+			matched = exists && reflect.DeepEqual(value, operand)
+		case "$gt", "$gte", "$lt", "$lte":
+			order, comparable := compareValues(value, operand)
+			matched = exists && comparable && map[string]bool{
+				"$gt":  order > 0,
+				"$gte": order >= 0,
+				"$lt":  order < 0,
+				"$lte": order <= 0,
+			}[operator]
+		default:
+			return false, fmt.Errorf("unsupported operator %s", operator)
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// compareValues orders two numbers or two strings, and reports whether they could be compared
+func compareValues(a interface{}, b interface{}) (int, bool) {
+	switch a := a.(type) {
+	case float64:
+		b, ok := b.(float64)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case a < b:
+			return -1, true
+		case a > b:
+			return 1, true
+		}
+		return 0, true
+	case string:
+		b, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(a, b), true
+	}
+
+	return 0, false
+}
+
+// GetHistoryForKey returns the committed versions of key newest first, as Fabric v2 does
+func (s *mockStub) GetHistoryForKey(key string) (shim.HistoryQueryIteratorInterface, error) {
+	modifications := []*queryresult.KeyModification{}
+	for i := len(s.history[key]) - 1; i >= 0; i-- {
+		modifications = append(modifications, s.history[key][i])
+	}
+	return &mockHistoryIterator{modifications: modifications}, nil
+}
+
+// mockIterator iterates over a snapshot of query results
+type mockIterator struct {
+	results []*queryresult.KV
+	next    int
+}
+
+func (it *mockIterator) HasNext() bool {
+	return it.next < len(it.results)
+}
+
+func (it *mockIterator) Next() (*queryresult.KV, error) {
+	if !it.HasNext() {
+		return nil, fmt.Errorf("no more results")
+	}
+	it.next++
+	return it.results[it.next-1], nil
+}
+
+func (it *mockIterator) Close() error {
+	return nil
+}
+
+// mockHistoryIterator iterates over the versions of a key
+type mockHistoryIterator struct {
+	modifications []*queryresult.KeyModification
+	next          int
+}
+
+func (it *mockHistoryIterator) HasNext() bool {
+	return it.next < len(it.modifications)
+}
+
+func (it *mockHistoryIterator) Next() (*queryresult.KeyModification, error) {
+	if !it.HasNext() {
+		return nil, fmt.Errorf("no more history")
+	}
+	it.next++
+	return it.modifications[it.next-1], nil
+}
+
+func (it *mockHistoryIterator) Close() error {
+	return nil
+}
+
+// mockIdentity is a client identity with an x509 ID, an MSP ID and certificate attributes
+type mockIdentity struct {
+	id         string
+	mspID      string
+	attributes map[string]string
+}
+
+// newIdentity returns the identity of the client with common name cn of organization mspID
+func newIdentity(cn string, mspID string) *mockIdentity {
+	return &mockIdentity{id: accountID(cn, mspID), mspID: mspID, attributes: map[string]string{}}
+}
+
+// accountID returns the client ID of the client with common name cn of organization mspID
+func accountID(cn string, mspID string) string {
+	return fmt.Sprintf("x509::CN=%s,OU=client::CN=ca.%s", cn, strings.ToLower(strings.TrimSuffix(mspID, "MSP")))
+}
+
+func (i *mockIdentity) GetID() (string, error) {
+	return base64.StdEncoding.EncodeToString([]byte(i.id)), nil
+}
+
+func (i *mockIdentity) GetMSPID() (string, error) {
+	return i.mspID, nil
+}
+
+func (i *mockIdentity) GetAttributeValue(attrName string) (string, bool, error) {
+	value, found := i.attributes[attrName]
+	return value, found, nil
+}
+
+func (i *mockIdentity) AssertAttributeValue(attrName, attrValue string) error {
+	value, found := i.attributes[attrName]
+	if !found || value != attrValue {
+		return fmt.Errorf("attribute %s is not %s", attrName, attrValue)
+	}
+	return nil
+}
+
+func (i *mockIdentity) GetX509Certificate() (*x509.Certificate, error) {
+	return nil, nil
+}
+
+// invoke runs fn as a transaction submitted by identity, emitting its change set and committing its writes
+// only if it succeeds, as contractapi does
+func invoke(stub *mockStub, identity *mockIdentity, fn func(ctx contractapi.TransactionContextInterface) error) error {
+	stub.begin()
+
+	ctx := &TransactionContext{}
+	ctx.SetStub(stub)
+	ctx.SetClientIdentity(identity)
+
+	err := fn(ctx)
+	if err == nil {
+		err = emitChangeSet(ctx)
+	}
+	if err == nil {
+		stub.commit()
+	}
+	return err
+}
+
+// mustInvoke runs fn as a transaction submitted by identity and fails the test if it fails
+func mustInvoke(t *testing.T, stub *mockStub, identity *mockIdentity, fn func(ctx contractapi.TransactionContextInterface) error) {
+	t.Helper()
+
+	if err := invoke(stub, identity, fn); err != nil {
+		t.Fatalf("transaction failed: %v", err)
+	}
+}
@@ -1,116 +1,197 @@
 package chaincode
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"reflect"
 
 	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
 )
 
+// userCollection is the private data collection, defined in collections_config.json,
+// holding the personal details of users
+const userCollection = "userPrivateDetails"
+
+// userTransientKey is the key of the transient map entry carrying the personal details of a user,
+// a JSON object with name, age, sex and salt, so that they never appear in the transaction
+const userTransientKey = "user"
+
+// User is the public record of a person registered on the ledger.
+// Its personal details are kept in the private data collection; DetailsHash is the hex SHA-256
+// of them, salted, so that a member can prove details it disclosed without putting them on the ledger.
+// DetailsHash is empty once the details have been purged.
 type User struct {
+	ID          string `json:"id"`
+	DetailsHash string `json:"details_hash"`
+}
+
+// UserDetails are the personal details of a user, stored in the private data collection
+type UserDetails struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
 	Age  int    `json:"age"`
 	Sex  string `json:"sex"`
+	Salt string `json:"salt"`
 }
 
-func (u *User) getTableName() string {
-	return reflect.TypeOf(*u).Name()
+// legacyUser is a user record written before the personal details of users moved to the private
+// data collection. Name is nil for records that only carry the hash of the details.
+type legacyUser struct {
+	ID   string  `json:"id"`
+	Name *string `json:"name"`
+	Age  int     `json:"age"`
+	Sex  string  `json:"sex"`
 }
 
-func (u *User) Save(ctx contractapi.TransactionContextInterface) error {
-	userJson, err := json.Marshal(u)
-	if err != nil {
-		return fmt.Errorf("failed to marshal user: %v", err)
-	}
+// UserPage is one page of users and the bookmark of the next page
+type UserPage struct {
+	Records             []*User `json:"records"`
+	FetchedRecordsCount int32   `json:"fetched_records_count"`
+	Bookmark            string  `json:"bookmark"`
+}
 
-	tableName := u.getTableName()
+// UserVersion is one version of a user in its change history.
+// Value is omitted for the version recording its deletion.
+type UserVersion struct {
+	TxID      string        `json:"tx_id"`
+	Timestamp string        `json:"timestamp"`
+	IsDelete  bool          `json:"is_delete"`
+	Value     *User         `json:"value,omitempty" metadata:",optional"`
+	Changes   []FieldChange `json:"changes"`
+}
 
-	err = ctx.GetStub().PutState(tableName+"||"+u.ID, userJson)
+// KeyAttributes returns the attributes the user is stored under, its ID
+func (u User) KeyAttributes() []string {
+	return []string{u.ID}
+}
+
+// users stores users under ("User", id) composite keys
+var users = NewRepository[User]()
+
+// userDetailsKey builds the key of the personal details of a user in the private data collection
+func userDetailsKey(ctx contractapi.TransactionContextInterface, id string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey("UserDetails", []string{id})
 	if err != nil {
-		return fmt.Errorf("failed to put user into world state: %v", err)
+		return "", fmt.Errorf("failed to create user details key: %v", err)
 	}
 
-	return nil
+	return key, nil
 }
 
-func ReadUser(ctx contractapi.TransactionContextInterface, id string) (*User, error) {
-	user := &User{}
-	tableName := user.getTableName()
-
-	userJson, err := ctx.GetStub().GetState(tableName + "||" + id)
+// transientUserDetails returns the personal details of user id passed in the transient map
+func transientUserDetails(ctx contractapi.TransactionContextInterface, id string) (*UserDetails, error) {
+	transientMap, err := ctx.GetStub().GetTransient()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read from world state: %v", err)
+		return nil, fmt.Errorf("failed to get transient data: %v", err)
 	}
 
-	if userJson == nil {
-		return nil, fmt.Errorf("user %s does not exist", id)
+	detailsJSON, ok := transientMap[userTransientKey]
+	if !ok {
+		return nil, fmt.Errorf("the user details must be passed in the transient map under %q", userTransientKey)
 	}
 
-	err = json.Unmarshal(userJson, user)
+	var details UserDetails
+	err = json.Unmarshal(detailsJSON, &details)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal user data: %v", err)
+		return nil, fmt.Errorf("failed to unmarshal user details: %v", err)
 	}
+	if details.Name == "" {
+		return nil, fmt.Errorf("the name of the user must be specified")
+	}
+	if details.Age < 0 {
+		return nil, fmt.Errorf("the age of the user must not be negative")
+	}
+	if len(details.Salt) < 16 {
+		return nil, fmt.Errorf("the salt must be at least 16 characters long")
+	}
+	details.ID = id
 
-	return user, nil
+	return &details, nil
 }
 
-// Update updates the user in the world state
-func (u *User) Update(ctx contractapi.TransactionContextInterface) error {
-	exists, err := IsUserExists(ctx, u.ID)
+// hashUserDetails returns the hex SHA-256 of the JSON encoding of details, salt included
+func hashUserDetails(details *UserDetails) (string, error) {
+	detailsJSON, err := json.Marshal(details)
 	if err != nil {
-		return fmt.Errorf("failed to check if user exists: %v", err)
-	}
-	if !exists {
-		return fmt.Errorf("user %s does not exist", u.ID)
+		return "", fmt.Errorf("failed to marshal user details: %v", err)
 	}
 
-	userJson, err := json.Marshal(u)
+	hash := sha256.Sum256(detailsJSON)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// putUserDetails stores the personal details of a user in the private data collection and returns their hash
+func putUserDetails(ctx contractapi.TransactionContextInterface, details *UserDetails) (string, error) {
+	detailsJSON, err := json.Marshal(details)
 	if err != nil {
-		return fmt.Errorf("failed to marshal user: %v", err)
+		return "", fmt.Errorf("failed to marshal user details: %v", err)
 	}
 
-	tableName := u.getTableName()
+	key, err := userDetailsKey(ctx, details.ID)
+	if err != nil {
+		return "", err
+	}
 
-	err = ctx.GetStub().PutState(tableName+"||"+u.ID, userJson)
+	err = ctx.GetStub().PutPrivateData(userCollection, key, detailsJSON)
 	if err != nil {
-		return fmt.Errorf("failed to update user in world state: %v", err)
+		return "", fmt.Errorf("failed to put user details into collection %s: %v", userCollection, err)
 	}
 
-	return nil
+	return hashUserDetails(details)
 }
 
-func DeleteUser(ctx contractapi.TransactionContextInterface, id string) error {
-	userExists, err := IsUserExists(ctx, id)
+// saveUser stores the personal details of a user in the private data collection
+// and its public record, carrying their hash, in the world state
+func saveUser(ctx contractapi.TransactionContextInterface, details *UserDetails) error {
+	hash, err := putUserDetails(ctx, details)
 	if err != nil {
-		return fmt.Errorf("failed to check if user exists: %v", err)
+		return err
 	}
-	if !userExists {
-		return fmt.Errorf("the user %s does not exist", id)
+
+	return users.Save(ctx, &User{ID: details.ID, DetailsHash: hash})
+}
+
+// readUserDetails returns the personal details of a user from the private data collection
+func readUserDetails(ctx contractapi.TransactionContextInterface, id string) (*UserDetails, error) {
+	key, err := userDetailsKey(ctx, id)
+	if err != nil {
+		return nil, err
 	}
 
-	user := &User{}
-	tableName := user.getTableName()
+	detailsJSON, err := ctx.GetStub().GetPrivateData(userCollection, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from collection %s: %v", userCollection, err)
+	}
+	if detailsJSON == nil {
+		return nil, fmt.Errorf("the details of user %s do not exist", id)
+	}
 
-	// Delete the user with the tableName prefix
-	err = ctx.GetStub().DelState(tableName + "||" + id)
+	var details UserDetails
+	err = json.Unmarshal(detailsJSON, &details)
 	if err != nil {
-		return fmt.Errorf("failed to delete user: %v", err)
+		return nil, fmt.Errorf("failed to unmarshal user details: %v", err)
 	}
 
-	return nil
+	return &details, nil
 }
 
-func IsUserExists(ctx contractapi.TransactionContextInterface, id string) (bool, error) {
-	user := &User{}
-	tableName := user.getTableName()
+// deleteUserDetails removes the personal details of a user from the private data collection.
+// Purging also removes every earlier version of them from the private data store of the peers.
+func deleteUserDetails(ctx contractapi.TransactionContextInterface, id string, purge bool) error {
+	key, err := userDetailsKey(ctx, id)
+	if err != nil {
+		return err
+	}
 
-	// Check if the user exists in the world state
-	assetJSON, err := ctx.GetStub().GetState(tableName + "||" + id)
+	if purge {
+		err = ctx.GetStub().PurgePrivateData(userCollection, key)
+	} else {
+		err = ctx.GetStub().DelPrivateData(userCollection, key)
+	}
 	if err != nil {
-		return false, fmt.Errorf("failed to read from world state: %v", err)
+		return fmt.Errorf("failed to delete user details from collection %s: %v", userCollection, err)
 	}
 
-	return assetJSON != nil, nil
+	return nil
 }
@@ -0,0 +1,141 @@
+package chaincode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// setUserDetails passes the personal details of a user to the next transactions in the transient map
+func setUserDetails(stub *mockStub, details string) {
+	stub.transient = map[string][]byte{userTransientKey: []byte(details)}
+}
+
+// readUser returns the public record of a user and its personal details, nil once they are removed
+func readUser(t *testing.T, stub *mockStub, id string) (*User, *UserDetails) {
+	t.Helper()
+
+	var user *User
+	var details *UserDetails
+	mustInvoke(t, stub, newIdentity("reader", "Org2MSP"), func(ctx contractapi.TransactionContextInterface) error {
+		var err error
+		user, err = users.Read(ctx, id)
+		if err != nil {
+			return err
+		}
+		details, err = (&SmartContract{}).ReadUserDetails(ctx, id)
+		if err != nil && strings.Contains(err.Error(), "do not exist") {
+			return nil
+		}
+		return err
+	})
+	return user, details
+}
+
+func TestCreateUserKeepsDetailsPrivate(t *testing.T) {
+	contract := &SmartContract{}
+	alice := newIdentity("alice", "Org1MSP")
+	stub := newMockStub()
+
+	err := invoke(stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.CreateUser(ctx, "user1")
+	})
+	assertError(t, err, "transient map")
+
+	setUserDetails(stub, `{"name":"Tomoko","age":30,"sex":"F","salt":"short"}`)
+	err = invoke(stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.CreateUser(ctx, "user1")
+	})
+	assertError(t, err, "at least 16 characters")
+
+	setUserDetails(stub, `{"name":"Tomoko","age":30,"sex":"F","salt":"0123456789abcdef"}`)
+	mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.CreateUser(ctx, "user1")
+	})
+
+	// The world state only holds the salted hash of the details
+	if public := string(stub.state["\x00User\x00user1\x00"]); strings.Contains(public, "Tomoko") {
+		t.Fatalf("got public user record %s carrying personal details", public)
+	}
+	user, details := readUser(t, stub, "user1")
+	if details == nil || details.Name != "Tomoko" || details.Age != 30 {
+		t.Fatalf("got details %+v, want those of Tomoko", details)
+	}
+	hash, err := hashUserDetails(details)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.DetailsHash != hash {
+		t.Fatalf("got details hash %s, want %s", user.DetailsHash, hash)
+	}
+
+	err = invoke(stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.CreateUser(ctx, "user1")
+	})
+	assertError(t, err, "already exists")
+}
+
+func TestUpdateAndDeleteUser(t *testing.T) {
+	contract := &SmartContract{}
+	admin := newIdentity("admin", "Org1MSP")
+	stub := newMockStub()
+
+	setUserDetails(stub, `{"name":"Tomoko","age":30,"sex":"F","salt":"0123456789abcdef"}`)
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.CreateUser(ctx, "user1")
+	})
+	before, _ := readUser(t, stub, "user1")
+
+	// Only the admin organization may update or delete users
+	setUserDetails(stub, `{"name":"Tomoko","age":31,"sex":"F","salt":"0123456789abcdef"}`)
+	bob := newIdentity("bob", "Org2MSP")
+	err := invoke(stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.UpdateUser(ctx, "user1")
+	})
+	assertUnauthorized(t, err)
+	err = invoke(stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.DeleteUser(ctx, "user1")
+	})
+	assertUnauthorized(t, err)
+
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.UpdateUser(ctx, "user1")
+	})
+	after, details := readUser(t, stub, "user1")
+	if details.Age != 31 || after.DetailsHash == before.DetailsHash {
+		t.Fatalf("got details %+v with hash %s, want the updated details and a new hash", details, after.DetailsHash)
+	}
+
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.DeleteUser(ctx, "user1")
+	})
+	if len(stub.private[userCollection]) != 0 {
+		t.Fatalf("got %d details left in the collection after deleting the user", len(stub.private[userCollection]))
+	}
+}
+
+func TestPurgeUserDetails(t *testing.T) {
+	contract := &SmartContract{}
+	admin := newIdentity("admin", "Org1MSP")
+	stub := newMockStub()
+
+	setUserDetails(stub, `{"name":"Tomoko","age":30,"sex":"F","salt":"0123456789abcdef"}`)
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.CreateUser(ctx, "user1")
+	})
+
+	err := invoke(stub, newIdentity("bob", "Org2MSP"), func(ctx contractapi.TransactionContextInterface) error {
+		return contract.PurgeUserDetails(ctx, "user1")
+	})
+	assertUnauthorized(t, err)
+
+	// Purging keeps the public record without a hash
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.PurgeUserDetails(ctx, "user1")
+	})
+	user, details := readUser(t, stub, "user1")
+	if details != nil || user.DetailsHash != "" {
+		t.Fatalf("got user %+v with details %+v, want both purged", user, details)
+	}
+}
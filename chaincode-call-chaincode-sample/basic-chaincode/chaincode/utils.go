@@ -1,6 +1,7 @@
 package chaincode
 
 import (
+	"encoding/base64"
 	"fmt"
 
 	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
@@ -59,3 +60,92 @@ func getInvokerChaincodeName(ctx contractapi.TransactionContextInterface) (strin
 
 	return chaincodeHeaderExtension.ChaincodeId.Name, nil
 }
+
+// adminMSPID is the only organization allowed to tokenize existing assets
+const adminMSPID = "Org1MSP"
+
+// assetAdminAttribute is the certificate attribute that, set to "true" on a client of the admin organization,
+// allows it to update, delete and transfer assets it does not own
+const assetAdminAttribute = "asset.admin"
+
+// unauthorizedCode prefixes the message of every authorization error,
+// so that clients can tell them apart from other failures
+const unauthorizedCode = "UNAUTHORIZED"
+
+// errUnauthorized returns an authorization error, e.g. "UNAUTHORIZED: client is not the owner of asset asset1"
+func errUnauthorized(format string, args ...interface{}) error {
+	return fmt.Errorf(unauthorizedCode+": "+format, args...)
+}
+
+// getClientAccountID returns the decoded x509 identity of the invoking client,
+// e.g. "x509::CN=user1,OU=client,...::CN=ca.org1.example.com,..."
+func getClientAccountID(ctx contractapi.TransactionContextInterface) (string, error) {
+	b64ID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return "", fmt.Errorf("failed to get client id: %v", err)
+	}
+
+	decodedID, err := base64.StdEncoding.DecodeString(b64ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to base64 decode client id: %v", err)
+	}
+
+	return string(decodedID), nil
+}
+
+// getClientMSPID returns the MSP ID of the organization of the invoking client
+func getClientMSPID(ctx contractapi.TransactionContextInterface) (string, error) {
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return "", fmt.Errorf("failed to get MSPID: %v", err)
+	}
+
+	return clientMSPID, nil
+}
+
+// isAssetAdmin reports whether the client belongs to the admin organization and its certificate
+// carries the asset admin attribute. Attributes issued by the CA of any other organization are ignored.
+func isAssetAdmin(ctx contractapi.TransactionContextInterface) (bool, error) {
+	clientMSPID, err := getClientMSPID(ctx)
+	if err != nil {
+		return false, err
+	}
+	if clientMSPID != adminMSPID {
+		return false, nil
+	}
+
+	value, found, err := ctx.GetClientIdentity().GetAttributeValue(assetAdminAttribute)
+	if err != nil {
+		return false, fmt.Errorf("failed to get client attribute %s: %v", assetAdminAttribute, err)
+	}
+
+	return found && value == "true", nil
+}
+
+// checkAdmin returns an error unless the client belongs to the admin organization
+func checkAdmin(ctx contractapi.TransactionContextInterface) error {
+	clientMSPID, err := getClientMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != adminMSPID {
+		return errUnauthorized("client is not authorized to perform this operation")
+	}
+
+	return nil
+}
+
+// maxPageSize caps the number of records returned by one paginated query
+const maxPageSize = 100
+
+// checkPageSize rejects a page size that is not positive and caps it at maxPageSize
+func checkPageSize(pageSize int32) (int32, error) {
+	if pageSize <= 0 {
+		return 0, fmt.Errorf("page size must be a positive integer")
+	}
+	if pageSize > maxPageSize {
+		return maxPageSize, nil
+	}
+
+	return pageSize, nil
+}
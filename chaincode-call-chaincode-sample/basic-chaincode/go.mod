@@ -3,6 +3,7 @@ module chilley.net
 go 1.22.5
 
 require (
+	github.com/hyperledger/fabric-chaincode-go/v2 v2.0.0-20240618210511-f7903324a8af
 	github.com/hyperledger/fabric-contract-api-go/v2 v2.0.0
 	github.com/hyperledger/fabric-protos-go-apiv2 v0.3.3
 	google.golang.org/protobuf v1.34.2
@@ -16,7 +17,6 @@ require (
 	github.com/gobuffalo/envy v1.10.2 // indirect
 	github.com/gobuffalo/packd v1.0.2 // indirect
 	github.com/gobuffalo/packr v1.30.1 // indirect
-	github.com/hyperledger/fabric-chaincode-go/v2 v2.0.0-20240618210511-f7903324a8af // indirect
 	github.com/joho/godotenv v1.5.1 // indirect
 	github.com/josharian/intern v1.0.0 // indirect
 	github.com/mailru/easyjson v0.7.7 // indirect
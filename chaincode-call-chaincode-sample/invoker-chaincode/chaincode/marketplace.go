@@ -0,0 +1,301 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// marketplaceAccount is the approved account basic-chaincode checks when this chaincode moves an asset
+const marketplaceAccount = "chaincode::" + chaincodeName
+
+// Listing describes an asset of basic-chaincode offered for sale for a price in ERC-20 tokens.
+// The chaincodes holding the asset and the tokens are fixed when the asset is listed, so the buyer cannot choose them.
+type Listing struct {
+	AssetID        string `json:"asset_id"`
+	AssetChaincode string `json:"asset_chaincode"`
+	TokenChaincode string `json:"token_chaincode"`
+	Seller         string `json:"seller"`
+	Price          int    `json:"price"`
+}
+
+// ListingCancelled is emitted when a seller withdraws an asset from sale
+type ListingCancelled struct {
+	AssetID string `json:"asset_id"`
+	Seller  string `json:"seller"`
+}
+
+// AssetSold is emitted when a listed asset is bought
+type AssetSold struct {
+	AssetID string `json:"asset_id"`
+	Seller  string `json:"seller"`
+	Buyer   string `json:"buyer"`
+	Price   int    `json:"price"`
+}
+
+// getTableName returns the name of the struct type to use as the table name
+func (l *Listing) getTableName() string {
+	return reflect.TypeOf(l).Elem().Name() // Get the struct name, the value is "Listing"
+}
+
+// listingKey builds the composite world state key of the listing of an asset, ("Listing", asset)
+func listingKey(ctx contractapi.TransactionContextInterface, assetID string) (string, error) {
+	listing := &Listing{}
+
+	key, err := ctx.GetStub().CreateCompositeKey(listing.getTableName(), []string{assetID})
+	if err != nil {
+		return "", fmt.Errorf("failed to create listing key: %v", err)
+	}
+
+	return key, nil
+}
+
+// Save stores the listing in the world state
+func (l *Listing) Save(ctx contractapi.TransactionContextInterface) error {
+	listingJSON, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+
+	key, err := listingKey(ctx, l.AssetID)
+	if err != nil {
+		return err
+	}
+
+	err = ctx.GetStub().PutState(key, listingJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put listing into world state: %v", err)
+	}
+
+	return nil
+}
+
+// findListing retrieves the listing of an asset from the world state, nil if the asset is not for sale
+func findListing(ctx contractapi.TransactionContextInterface, assetID string) (*Listing, error) {
+	key, err := listingKey(ctx, assetID)
+	if err != nil {
+		return nil, err
+	}
+
+	listingJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if listingJSON == nil {
+		return nil, nil
+	}
+
+	listing := &Listing{}
+	err = json.Unmarshal(listingJSON, listing)
+	if err != nil {
+		return nil, err
+	}
+
+	return listing, nil
+}
+
+// ReadListing retrieves the listing of an asset from the world state
+func ReadListing(ctx contractapi.TransactionContextInterface, assetID string) (*Listing, error) {
+	listing, err := findListing(ctx, assetID)
+	if err != nil {
+		return nil, err
+	}
+	if listing == nil {
+		return nil, fmt.Errorf("asset %s is not for sale", assetID)
+	}
+
+	return listing, nil
+}
+
+// DeleteListing removes the listing of an asset from the world state
+func DeleteListing(ctx contractapi.TransactionContextInterface, assetID string) error {
+	key, err := listingKey(ctx, assetID)
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().DelState(key)
+}
+
+// sellerOwnsAsset reports whether the seller of a listing still owns the listed asset.
+// Once the asset changes hands its approval of this chaincode is cleared and the listing is stale.
+func sellerOwnsAsset(ctx contractapi.TransactionContextInterface, listing *Listing) (bool, error) {
+	owner, err := invoke(ctx, listing.AssetChaincode, "OwnerOf", listing.AssetID)
+	if err != nil {
+		return false, err
+	}
+
+	return string(owner) == listing.Seller, nil
+}
+
+// ListAssetForSale offers an asset of Chaincode A for price tokens of the token chaincode tokenChaincodeName.
+// The client must own the asset; it approves this chaincode to transfer the asset once it is bought.
+// An asset listed by the client stays listed at its price: listing it again at the same price and
+// chaincodes does nothing, while a new price requires cancelling the listing first. A stale listing
+// of a former owner is replaced.
+func (s *BSmartContract) ListAssetForSale(ctx contractapi.TransactionContextInterface, chaincodeAName, tokenChaincodeName, assetID string, price int) error {
+	if price <= 0 {
+		return fmt.Errorf("price must be a positive integer")
+	}
+
+	seller, err := getClientAccountID(ctx)
+	if err != nil {
+		return err
+	}
+
+	owner, err := invoke(ctx, chaincodeAName, "OwnerOf", assetID)
+	if err != nil {
+		return err
+	}
+	if string(owner) != seller {
+		return errUnauthorized("client is not the owner of asset %s", assetID)
+	}
+
+	listing := Listing{AssetID: assetID, AssetChaincode: chaincodeAName, TokenChaincode: tokenChaincodeName, Seller: seller, Price: price}
+
+	existing, err := findListing(ctx, assetID)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.Seller == seller {
+		if *existing == listing {
+			return nil
+		}
+		return fmt.Errorf("asset %s is already listed for %d tokens, cancel the listing before listing it again", assetID, existing.Price)
+	}
+
+	_, err = invoke(ctx, chaincodeAName, "Approve", marketplaceAccount, assetID)
+	if err != nil {
+		return err
+	}
+
+	err = listing.Save(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The approval set in the asset chaincode emits no event of its own, as only the event of this chaincode is kept
+	return emitEvent(ctx, "AssetListed", listing)
+}
+
+// CancelListing withdraws an asset from sale and revokes the approval of this chaincode.
+// A stale listing, whose asset the seller no longer owns, is removed without touching the asset.
+func (s *BSmartContract) CancelListing(ctx contractapi.TransactionContextInterface, assetID string) error {
+	listing, err := ReadListing(ctx, assetID)
+	if err != nil {
+		return err
+	}
+
+	clientID, err := getClientAccountID(ctx)
+	if err != nil {
+		return err
+	}
+	if clientID != listing.Seller {
+		return errUnauthorized("client is not the seller of asset %s", assetID)
+	}
+
+	owned, err := sellerOwnsAsset(ctx, listing)
+	if err != nil {
+		return err
+	}
+	if owned {
+		_, err = invoke(ctx, listing.AssetChaincode, "Approve", "", assetID)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = DeleteListing(ctx, assetID)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, "ListingCancelled", ListingCancelled{AssetID: assetID, Seller: listing.Seller})
+}
+
+// BuyAsset pays the listed price from the client to the seller in the token chaincode of the listing and
+// transfers the asset to the client in its asset chaincode. Both legs run in this transaction,
+// so if either fails nothing is committed. The purchase fails if the listed price exceeds maxPrice,
+// the most the buyer agreed to pay.
+func (s *BSmartContract) BuyAsset(ctx contractapi.TransactionContextInterface, assetID string, maxPrice int) error {
+	listing, err := ReadListing(ctx, assetID)
+	if err != nil {
+		return err
+	}
+	if listing.Price > maxPrice {
+		return fmt.Errorf("asset %s is listed for %d tokens, more than the maximum price %d", assetID, listing.Price, maxPrice)
+	}
+
+	buyer, err := getClientAccountID(ctx)
+	if err != nil {
+		return err
+	}
+	if buyer == listing.Seller {
+		return fmt.Errorf("seller cannot buy its own asset %s", assetID)
+	}
+
+	owned, err := sellerOwnsAsset(ctx, listing)
+	if err != nil {
+		return err
+	}
+	if !owned {
+		return fmt.Errorf("the listing of asset %s is stale, the seller no longer owns it", assetID)
+	}
+
+	buyerMSPID, err := getClientMSPID(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The token chaincode debits the invoking client, i.e. the buyer
+	_, err = invoke(ctx, listing.TokenChaincode, "Transfer", listing.Seller, strconv.Itoa(listing.Price))
+	if err != nil {
+		return err
+	}
+
+	_, err = invoke(ctx, listing.AssetChaincode, "TransferAsset", assetID, buyerMSPID, buyer)
+	if err != nil {
+		return err
+	}
+
+	err = DeleteListing(ctx, assetID)
+	if err != nil {
+		return fmt.Errorf("failed to delete listing: %v", err)
+	}
+
+	return emitEvent(ctx, "AssetSold", AssetSold{AssetID: assetID, Seller: listing.Seller, Buyer: buyer, Price: listing.Price})
+}
+
+// ReadListing returns the listing of an asset
+func (s *BSmartContract) ReadListing(ctx contractapi.TransactionContextInterface, assetID string) (*Listing, error) {
+	return ReadListing(ctx, assetID)
+}
+
+// GetAllListings returns every asset currently for sale
+func (s *BSmartContract) GetAllListings(ctx contractapi.TransactionContextInterface) ([]*Listing, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey((&Listing{}).getTableName(), []string{})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	var listings []*Listing
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var listing Listing
+		err = json.Unmarshal(queryResponse.Value, &listing)
+		if err != nil {
+			return nil, err
+		}
+		listings = append(listings, &listing)
+	}
+
+	return listings, nil
+}
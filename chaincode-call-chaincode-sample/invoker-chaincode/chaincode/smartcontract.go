@@ -59,12 +59,13 @@ type Asset struct {
 	Color          string `json:"color"`
 	ID             string `json:"id"`
 	Owner          string `json:"owner"`
+	OwnerMSP       string `json:"owner_msp"`
 	Size           int    `json:"size"`
 }
 
+// User is the public record of a user of Chaincode A. Its personal details stay in the private data
+// collection of Chaincode A; DetailsHash is their salted hash, empty once they have been purged.
 type User struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
-	Age  int    `json:"age"`
-	Sex  string `json:"sex"`
+	ID          string `json:"id"`
+	DetailsHash string `json:"details_hash"`
 }
@@ -0,0 +1,76 @@
+package chaincode
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// unauthorizedCode prefixes the message of every authorization error, as in basic-chaincode,
+// so that clients can tell them apart from other failures
+const unauthorizedCode = "UNAUTHORIZED"
+
+// errUnauthorized returns an authorization error, e.g. "UNAUTHORIZED: client is not the seller of asset asset1"
+func errUnauthorized(format string, args ...interface{}) error {
+	return fmt.Errorf(unauthorizedCode+": "+format, args...)
+}
+
+// getClientAccountID returns the decoded x509 identity of the invoking client,
+// e.g. "x509::CN=user1,OU=client,...::CN=ca.org1.example.com,..."
+func getClientAccountID(ctx contractapi.TransactionContextInterface) (string, error) {
+	b64ID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return "", fmt.Errorf("failed to get client id: %v", err)
+	}
+
+	decodedID, err := base64.StdEncoding.DecodeString(b64ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to base64 decode client id: %v", err)
+	}
+
+	return string(decodedID), nil
+}
+
+// getClientMSPID returns the MSP ID of the organization of the invoking client
+func getClientMSPID(ctx contractapi.TransactionContextInterface) (string, error) {
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return "", fmt.Errorf("failed to get MSPID: %v", err)
+	}
+
+	return clientMSPID, nil
+}
+
+// invoke calls function on another chaincode of the channel and returns its payload.
+// The called chaincode runs with the identity of the client and its writes belong to
+// this transaction, so returning the error fails every leg of the transaction.
+func invoke(ctx contractapi.TransactionContextInterface, chaincodeName string, function string, args ...string) ([]byte, error) {
+	invokeArgs := [][]byte{[]byte(function)}
+	for _, arg := range args {
+		invokeArgs = append(invokeArgs, []byte(arg))
+	}
+
+	response := ctx.GetStub().InvokeChaincode(chaincodeName, invokeArgs, "")
+	if response.Status != 200 {
+		return nil, fmt.Errorf("failed to invoke %s on chaincode %s: %s", function, chaincodeName, response.Message)
+	}
+
+	return response.Payload, nil
+}
+
+// emitEvent marshals payload and sets it as the chaincode event of the transaction
+func emitEvent(ctx contractapi.TransactionContextInterface, name string, payload interface{}) error {
+	eventJSON, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	err = ctx.GetStub().SetEvent(name, eventJSON)
+	if err != nil {
+		return fmt.Errorf("failed to set event: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package chaincode
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+const (
+	balancePrefix  = "balance"
+	approvalPrefix = "approval"
+	uriPrefix      = "uri"
+)
+
+// balanceKey builds the composite key holding the balance of token id for account
+func balanceKey(ctx contractapi.TransactionContextInterface, account string, id uint64) (string, error) {
+	return ctx.GetStub().CreateCompositeKey(balancePrefix, []string{account, strconv.FormatUint(id, 10)})
+}
+
+// ReadBalance returns the amount of token id held by account, zero if it holds none
+func ReadBalance(ctx contractapi.TransactionContextInterface, account string, id uint64) (uint64, error) {
+	key, err := balanceKey(ctx, account, id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create balance key: %v", err)
+	}
+
+	balanceBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if balanceBytes == nil {
+		return 0, nil
+	}
+
+	balance, err := strconv.ParseUint(string(balanceBytes), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse balance of token %d for %s: %v", id, account, err)
+	}
+
+	return balance, nil
+}
+
+// writeBalance stores the balance of token id for account, removing the key when it drops to zero
+func writeBalance(ctx contractapi.TransactionContextInterface, account string, id uint64, balance uint64) error {
+	key, err := balanceKey(ctx, account, id)
+	if err != nil {
+		return fmt.Errorf("failed to create balance key: %v", err)
+	}
+
+	if balance == 0 {
+		return ctx.GetStub().DelState(key)
+	}
+
+	err = ctx.GetStub().PutState(key, []byte(strconv.FormatUint(balance, 10)))
+	if err != nil {
+		return fmt.Errorf("failed to put balance into world state: %v", err)
+	}
+
+	return nil
+}
+
+// addBalance credits amount of token id to account
+func addBalance(ctx contractapi.TransactionContextInterface, account string, id uint64, amount uint64) error {
+	balance, err := ReadBalance(ctx, account, id)
+	if err != nil {
+		return err
+	}
+	if balance > math.MaxUint64-amount {
+		return codedError(invalidArgumentCode, "balance of token %d for %s would overflow", id, account)
+	}
+
+	return writeBalance(ctx, account, id, balance+amount)
+}
+
+// removeBalance debits amount of token id from account
+func removeBalance(ctx contractapi.TransactionContextInterface, account string, id uint64, amount uint64) error {
+	balance, err := ReadBalance(ctx, account, id)
+	if err != nil {
+		return err
+	}
+	if balance < amount {
+		return codedError(insufficientFundsCode, "account %s has insufficient balance of token %d", account, id)
+	}
+
+	return writeBalance(ctx, account, id, balance-amount)
+}
+
+// isApprovedForAll reports whether operator may move every token held by account
+func isApprovedForAll(ctx contractapi.TransactionContextInterface, account string, operator string) (bool, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(approvalPrefix, []string{account, operator})
+	if err != nil {
+		return false, fmt.Errorf("failed to create approval key: %v", err)
+	}
+
+	approvalBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return false, fmt.Errorf("failed to read from world state: %v", err)
+	}
+
+	return approvalBytes != nil, nil
+}
+
+// setApprovalForAll grants or revokes operator's approval over the tokens of account
+func setApprovalForAll(ctx contractapi.TransactionContextInterface, account string, operator string, approved bool) error {
+	key, err := ctx.GetStub().CreateCompositeKey(approvalPrefix, []string{account, operator})
+	if err != nil {
+		return fmt.Errorf("failed to create approval key: %v", err)
+	}
+
+	if !approved {
+		return ctx.GetStub().DelState(key)
+	}
+
+	err = ctx.GetStub().PutState(key, []byte("true"))
+	if err != nil {
+		return fmt.Errorf("failed to put approval into world state: %v", err)
+	}
+
+	return nil
+}
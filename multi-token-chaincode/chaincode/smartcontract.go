@@ -0,0 +1,301 @@
+package chaincode
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// SmartContract provides functions for managing multiple fungible and non-fungible tokens
+type SmartContract struct {
+	contractapi.Contract
+}
+
+// TransferSingle is emitted when a single token type moves between accounts.
+// From is empty for mints.
+type TransferSingle struct {
+	Operator string `json:"operator"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	ID       uint64 `json:"id"`
+	Value    uint64 `json:"value"`
+}
+
+// TransferBatch is emitted when several token types move between the same accounts.
+// From is empty for mints.
+type TransferBatch struct {
+	Operator string   `json:"operator"`
+	From     string   `json:"from"`
+	To       string   `json:"to"`
+	IDs      []uint64 `json:"ids"`
+	Values   []uint64 `json:"values"`
+}
+
+// ApprovalForAll is emitted when an operator is granted or denied access to all tokens of an account
+type ApprovalForAll struct {
+	Account  string `json:"account"`
+	Operator string `json:"operator"`
+	Approved bool   `json:"approved"`
+}
+
+// URI is emitted when the metadata URI of a token changes
+type URI struct {
+	Value string `json:"value"`
+	ID    uint64 `json:"id"`
+}
+
+// Mint creates amount tokens of type id and assigns them to account
+func (s *SmartContract) Mint(ctx contractapi.TransactionContextInterface, account string, id uint64, amount uint64) error {
+	if err := checkMinter(ctx); err != nil {
+		return err
+	}
+	if account == "" {
+		return codedError(invalidArgumentCode, "mint to an empty account")
+	}
+
+	operator, err := getClientAccountID(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = addBalance(ctx, account, id, amount)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, "TransferSingle", TransferSingle{Operator: operator, From: "", To: account, ID: id, Value: amount})
+}
+
+// MintBatch creates several token types at once and assigns them to account
+func (s *SmartContract) MintBatch(ctx contractapi.TransactionContextInterface, account string, ids []uint64, amounts []uint64) error {
+	if err := checkMinter(ctx); err != nil {
+		return err
+	}
+	if account == "" {
+		return codedError(invalidArgumentCode, "mint to an empty account")
+	}
+
+	totals, err := sumByID(ids, amounts)
+	if err != nil {
+		return err
+	}
+
+	operator, err := getClientAccountID(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range uniqueIDs(ids) {
+		err = addBalance(ctx, account, id, totals[id])
+		if err != nil {
+			return err
+		}
+	}
+
+	return emitEvent(ctx, "TransferBatch", TransferBatch{Operator: operator, From: "", To: account, IDs: ids, Values: amounts})
+}
+
+// BalanceOf returns the amount of token id held by account
+func (s *SmartContract) BalanceOf(ctx contractapi.TransactionContextInterface, account string, id uint64) (uint64, error) {
+	return ReadBalance(ctx, account, id)
+}
+
+// BalanceOfBatch returns the balance of each (accounts[i], ids[i]) pair
+func (s *SmartContract) BalanceOfBatch(ctx contractapi.TransactionContextInterface, accounts []string, ids []uint64) ([]uint64, error) {
+	if len(accounts) != len(ids) {
+		return nil, codedError(invalidArgumentCode, "accounts and ids must have the same length")
+	}
+
+	balances := make([]uint64, len(accounts))
+	for i := range accounts {
+		balance, err := ReadBalance(ctx, accounts[i], ids[i])
+		if err != nil {
+			return nil, err
+		}
+		balances[i] = balance
+	}
+
+	return balances, nil
+}
+
+// ClientAccountID returns the account ID of the invoking client
+func (s *SmartContract) ClientAccountID(ctx contractapi.TransactionContextInterface) (string, error) {
+	return getClientAccountID(ctx)
+}
+
+// ClientAccountBalance returns the amount of token id held by the invoking client
+func (s *SmartContract) ClientAccountBalance(ctx contractapi.TransactionContextInterface, id uint64) (uint64, error) {
+	clientID, err := getClientAccountID(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return ReadBalance(ctx, clientID, id)
+}
+
+// SafeTransferFrom moves amount tokens of type id from one account to another.
+// The client must be the sender or an operator approved by the sender.
+func (s *SmartContract) SafeTransferFrom(ctx contractapi.TransactionContextInterface, from string, to string, id uint64, amount uint64) error {
+	operator, err := checkTransfer(ctx, from, to)
+	if err != nil {
+		return err
+	}
+
+	err = removeBalance(ctx, from, id, amount)
+	if err != nil {
+		return err
+	}
+
+	err = addBalance(ctx, to, id, amount)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, "TransferSingle", TransferSingle{Operator: operator, From: from, To: to, ID: id, Value: amount})
+}
+
+// SafeBatchTransferFrom moves several token types from one account to another in a single transaction
+func (s *SmartContract) SafeBatchTransferFrom(ctx contractapi.TransactionContextInterface, from string, to string, ids []uint64, amounts []uint64) error {
+	operator, err := checkTransfer(ctx, from, to)
+	if err != nil {
+		return err
+	}
+
+	// Fabric does not let a transaction read its own writes, so repeated ids are summed first
+	totals, err := sumByID(ids, amounts)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range uniqueIDs(ids) {
+		err = removeBalance(ctx, from, id, totals[id])
+		if err != nil {
+			return err
+		}
+
+		err = addBalance(ctx, to, id, totals[id])
+		if err != nil {
+			return err
+		}
+	}
+
+	return emitEvent(ctx, "TransferBatch", TransferBatch{Operator: operator, From: from, To: to, IDs: ids, Values: amounts})
+}
+
+// SetApprovalForAll allows or disallows operator to transfer every token of the invoking client
+func (s *SmartContract) SetApprovalForAll(ctx contractapi.TransactionContextInterface, operator string, approved bool) error {
+	account, err := getClientAccountID(ctx)
+	if err != nil {
+		return err
+	}
+	if account == operator {
+		return codedError(invalidArgumentCode, "setting approval status for self")
+	}
+
+	err = setApprovalForAll(ctx, account, operator, approved)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, "ApprovalForAll", ApprovalForAll{Account: account, Operator: operator, Approved: approved})
+}
+
+// IsApprovedForAll returns true if operator is approved to transfer the tokens of account
+func (s *SmartContract) IsApprovedForAll(ctx contractapi.TransactionContextInterface, account string, operator string) (bool, error) {
+	return isApprovedForAll(ctx, account, operator)
+}
+
+// SetURI sets the metadata URI of token id
+func (s *SmartContract) SetURI(ctx contractapi.TransactionContextInterface, id uint64, uri string) error {
+	if err := checkMinter(ctx); err != nil {
+		return err
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(uriPrefix, []string{strconv.FormatUint(id, 10)})
+	if err != nil {
+		return fmt.Errorf("failed to create uri key: %v", err)
+	}
+
+	err = ctx.GetStub().PutState(key, []byte(uri))
+	if err != nil {
+		return fmt.Errorf("failed to put uri into world state: %v", err)
+	}
+
+	return emitEvent(ctx, "URI", URI{Value: uri, ID: id})
+}
+
+// URI returns the metadata URI of token id, empty if none was set
+func (s *SmartContract) URI(ctx contractapi.TransactionContextInterface, id uint64) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(uriPrefix, []string{strconv.FormatUint(id, 10)})
+	if err != nil {
+		return "", fmt.Errorf("failed to create uri key: %v", err)
+	}
+
+	uriBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to read from world state: %v", err)
+	}
+
+	return string(uriBytes), nil
+}
+
+// checkTransfer validates the accounts of a transfer and returns the invoking operator
+func checkTransfer(ctx contractapi.TransactionContextInterface, from string, to string) (string, error) {
+	if from == "" || to == "" {
+		return "", codedError(invalidArgumentCode, "transfer from or to an empty account")
+	}
+	if from == to {
+		return "", codedError(invalidArgumentCode, "cannot transfer to and from the same account")
+	}
+
+	operator, err := getClientAccountID(ctx)
+	if err != nil {
+		return "", err
+	}
+	if operator == from {
+		return operator, nil
+	}
+
+	approved, err := isApprovedForAll(ctx, from, operator)
+	if err != nil {
+		return "", err
+	}
+	if !approved {
+		return "", codedError(unauthorizedCode, "caller is not owner nor is approved")
+	}
+
+	return operator, nil
+}
+
+// sumByID totals the amounts of a batch per token id
+func sumByID(ids []uint64, amounts []uint64) (map[uint64]uint64, error) {
+	if len(ids) != len(amounts) {
+		return nil, codedError(invalidArgumentCode, "ids and amounts must have the same length")
+	}
+
+	totals := make(map[uint64]uint64, len(ids))
+	for i, id := range ids {
+		if totals[id]+amounts[i] < totals[id] {
+			return nil, codedError(invalidArgumentCode, "amount of token %d overflows", id)
+		}
+		totals[id] += amounts[i]
+	}
+
+	return totals, nil
+}
+
+// uniqueIDs returns ids without duplicates, keeping their first-seen order
+// so that state is written deterministically on every peer
+func uniqueIDs(ids []uint64) []uint64 {
+	seen := make(map[uint64]bool, len(ids))
+	var unique []uint64
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	return unique
+}
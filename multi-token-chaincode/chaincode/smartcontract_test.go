@@ -0,0 +1,168 @@
+package chaincode
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// assertCode fails the test unless err carries the given error code
+func assertCode(t *testing.T, err error, code string) {
+	t.Helper()
+
+	if err == nil || !strings.HasPrefix(err.Error(), code+": ") {
+		t.Fatalf("got error %v, want a %s error", err, code)
+	}
+}
+
+// balances returns the committed balances of account for ids
+func balances(t *testing.T, stub *mockStub, account string, ids ...uint64) []uint64 {
+	t.Helper()
+
+	var result []uint64
+	mustInvoke(t, stub, newIdentity("reader", "Org2MSP"), func(ctx contractapi.TransactionContextInterface) error {
+		for _, id := range ids {
+			balance, err := ReadBalance(ctx, account, id)
+			if err != nil {
+				return err
+			}
+			result = append(result, balance)
+		}
+		return nil
+	})
+	return result
+}
+
+// assertBalances fails the test unless account holds want of ids
+func assertBalances(t *testing.T, stub *mockStub, account string, ids []uint64, want []uint64) {
+	t.Helper()
+
+	got := balances(t, stub, account, ids...)
+	for i := range ids {
+		if got[i] != want[i] {
+			t.Fatalf("got balances %v of tokens %v, want %v", got, ids, want)
+		}
+	}
+}
+
+func TestMintBatch(t *testing.T) {
+	contract := &SmartContract{}
+	minter := newIdentity("minter", "Org1MSP")
+	bob := newIdentity("bob", "Org2MSP")
+	stub := newMockStub()
+
+	err := invoke(stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.MintBatch(ctx, bob.id, []uint64{1}, []uint64{10})
+	})
+	assertCode(t, err, unauthorizedCode)
+
+	err = invoke(stub, minter, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.MintBatch(ctx, bob.id, []uint64{1, 2}, []uint64{10})
+	})
+	assertCode(t, err, invalidArgumentCode)
+
+	// Repeated ids are summed, as a transaction cannot read the balance it just wrote
+	mustInvoke(t, stub, minter, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.MintBatch(ctx, bob.id, []uint64{1, 2, 1}, []uint64{10, 20, 5})
+	})
+	if stub.event == nil || stub.event.name != "TransferBatch" {
+		t.Fatalf("got event %v, want a TransferBatch event", stub.event)
+	}
+	assertBalances(t, stub, bob.id, []uint64{1, 2}, []uint64{15, 20})
+}
+
+func TestMintOverflow(t *testing.T) {
+	contract := &SmartContract{}
+	minter := newIdentity("minter", "Org1MSP")
+	stub := newMockStub()
+
+	mustInvoke(t, stub, minter, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.Mint(ctx, minter.id, 1, math.MaxUint64)
+	})
+
+	err := invoke(stub, minter, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.Mint(ctx, minter.id, 1, 1)
+	})
+	assertCode(t, err, invalidArgumentCode)
+
+	err = invoke(stub, minter, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.MintBatch(ctx, minter.id, []uint64{2, 2}, []uint64{math.MaxUint64, 1})
+	})
+	assertCode(t, err, invalidArgumentCode)
+	assertBalances(t, stub, minter.id, []uint64{1, 2}, []uint64{math.MaxUint64, 0})
+}
+
+func TestSafeBatchTransferFrom(t *testing.T) {
+	contract := &SmartContract{}
+	minter := newIdentity("minter", "Org1MSP")
+	bob := newIdentity("bob", "Org2MSP")
+	stub := newMockStub()
+
+	mustInvoke(t, stub, minter, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.MintBatch(ctx, minter.id, []uint64{1, 2}, []uint64{10, 20})
+	})
+
+	// The batch is all or nothing: token 2 is short, so token 1 does not move either
+	err := invoke(stub, minter, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.SafeBatchTransferFrom(ctx, minter.id, bob.id, []uint64{1, 2}, []uint64{5, 21})
+	})
+	assertCode(t, err, insufficientFundsCode)
+	assertBalances(t, stub, bob.id, []uint64{1, 2}, []uint64{0, 0})
+
+	// Repeated ids count against the same balance
+	err = invoke(stub, minter, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.SafeBatchTransferFrom(ctx, minter.id, bob.id, []uint64{1, 1}, []uint64{6, 5})
+	})
+	assertCode(t, err, insufficientFundsCode)
+
+	mustInvoke(t, stub, minter, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.SafeBatchTransferFrom(ctx, minter.id, bob.id, []uint64{1, 2, 1}, []uint64{4, 20, 6})
+	})
+	assertBalances(t, stub, minter.id, []uint64{1, 2}, []uint64{0, 0})
+	assertBalances(t, stub, bob.id, []uint64{1, 2}, []uint64{10, 20})
+
+	err = invoke(stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.SafeBatchTransferFrom(ctx, bob.id, bob.id, []uint64{1}, []uint64{1})
+	})
+	assertCode(t, err, invalidArgumentCode)
+}
+
+func TestOperatorApproval(t *testing.T) {
+	contract := &SmartContract{}
+	minter := newIdentity("minter", "Org1MSP")
+	operator := newIdentity("operator", "Org2MSP")
+	bob := newIdentity("bob", "Org2MSP")
+	stub := newMockStub()
+
+	mustInvoke(t, stub, minter, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.Mint(ctx, minter.id, 1, 10)
+	})
+
+	transfer := func() error {
+		return invoke(stub, operator, func(ctx contractapi.TransactionContextInterface) error {
+			return contract.SafeTransferFrom(ctx, minter.id, bob.id, 1, 4)
+		})
+	}
+
+	assertCode(t, transfer(), unauthorizedCode)
+
+	err := invoke(stub, minter, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.SetApprovalForAll(ctx, minter.id, true)
+	})
+	assertCode(t, err, invalidArgumentCode)
+
+	mustInvoke(t, stub, minter, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.SetApprovalForAll(ctx, operator.id, true)
+	})
+	if err := transfer(); err != nil {
+		t.Fatalf("approved operator failed to transfer: %v", err)
+	}
+	assertBalances(t, stub, bob.id, []uint64{1}, []uint64{4})
+
+	mustInvoke(t, stub, minter, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.SetApprovalForAll(ctx, operator.id, false)
+	})
+	assertCode(t, transfer(), unauthorizedCode)
+}
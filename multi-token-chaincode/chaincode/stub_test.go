@@ -0,0 +1,224 @@
+package chaincode
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim"
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+	"github.com/hyperledger/fabric-protos-go-apiv2/ledger/queryresult"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// mockStub is an in-memory ChaincodeStubInterface that behaves like a peer: reads see the state
+// committed before the transaction, never its own writes, and the writes of a transaction are
+// only applied when it commits. Methods the contract does not use panic.
+type mockStub struct {
+	shim.ChaincodeStubInterface
+	state     map[string][]byte
+	writes    map[string][]byte
+	txNumber  int
+	timestamp time.Time
+	event     *mockEvent
+}
+
+// mockEvent is the chaincode event set by a transaction
+type mockEvent struct {
+	name    string
+	payload []byte
+}
+
+func newMockStub() *mockStub {
+	return &mockStub{
+		state:     map[string][]byte{},
+		writes:    map[string][]byte{},
+		timestamp: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+// begin starts a new transaction, discarding the writes of any transaction that did not commit
+func (s *mockStub) begin() {
+	s.txNumber++
+	s.writes = map[string][]byte{}
+	s.event = nil
+}
+
+// commit applies the writes of the transaction to the world state. A nil value deletes its key.
+func (s *mockStub) commit() {
+	for key, value := range s.writes {
+		if value == nil {
+			delete(s.state, key)
+			continue
+		}
+		s.state[key] = value
+	}
+	s.writes = map[string][]byte{}
+}
+
+func (s *mockStub) GetTxID() string {
+	return fmt.Sprintf("tx%d", s.txNumber)
+}
+
+func (s *mockStub) GetTxTimestamp() (*timestamppb.Timestamp, error) {
+	return timestamppb.New(s.timestamp), nil
+}
+
+// SetEvent replaces the event of the transaction, as Fabric keeps only the last one
+func (s *mockStub) SetEvent(name string, payload []byte) error {
+	s.event = &mockEvent{name: name, payload: payload}
+	return nil
+}
+
+func (s *mockStub) GetState(key string) ([]byte, error) {
+	return s.state[key], nil
+}
+
+func (s *mockStub) PutState(key string, value []byte) error {
+	if value == nil {
+		value = []byte{}
+	}
+	s.writes[key] = value
+	return nil
+}
+
+func (s *mockStub) DelState(key string) error {
+	s.writes[key] = nil
+	return nil
+}
+
+func (s *mockStub) CreateCompositeKey(objectType string, attributes []string) (string, error) {
+	key := "\x00" + objectType + "\x00"
+	for _, attribute := range attributes {
+		key += attribute + "\x00"
+	}
+	return key, nil
+}
+
+func (s *mockStub) SplitCompositeKey(compositeKey string) (string, []string, error) {
+	parts := strings.Split(strings.TrimSuffix(strings.TrimPrefix(compositeKey, "\x00"), "\x00"), "\x00")
+	return parts[0], parts[1:], nil
+}
+
+// sortedKeys returns the committed keys accepted by keep, in order
+func (s *mockStub) sortedKeys(keep func(key string) bool) []string {
+	var keys []string
+	for key := range s.state {
+		if keep(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// iterator snapshots the committed values of keys
+func (s *mockStub) iterator(keys []string) *mockIterator {
+	results := []*queryresult.KV{}
+	for _, key := range keys {
+		results = append(results, &queryresult.KV{Key: key, Value: s.state[key]})
+	}
+	return &mockIterator{results: results}
+}
+
+func (s *mockStub) GetStateByPartialCompositeKey(objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+	prefix, err := s.CreateCompositeKey(objectType, attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := s.sortedKeys(func(key string) bool { return strings.HasPrefix(key, prefix) })
+	return s.iterator(keys), nil
+}
+
+// mockIterator iterates over a snapshot of query results
+type mockIterator struct {
+	results []*queryresult.KV
+	next    int
+}
+
+func (it *mockIterator) HasNext() bool {
+	return it.next < len(it.results)
+}
+
+func (it *mockIterator) Next() (*queryresult.KV, error) {
+	if !it.HasNext() {
+		return nil, fmt.Errorf("no more results")
+	}
+	it.next++
+	return it.results[it.next-1], nil
+}
+
+func (it *mockIterator) Close() error {
+	return nil
+}
+
+// mockIdentity is a client identity with an x509 ID, an MSP ID and certificate attributes
+type mockIdentity struct {
+	id         string
+	mspID      string
+	attributes map[string]string
+}
+
+// newIdentity returns the identity of the client with common name cn of organization mspID
+func newIdentity(cn string, mspID string) *mockIdentity {
+	return &mockIdentity{id: accountID(cn, mspID), mspID: mspID, attributes: map[string]string{}}
+}
+
+// accountID returns the client ID of the client with common name cn of organization mspID
+func accountID(cn string, mspID string) string {
+	return fmt.Sprintf("x509::CN=%s,OU=client::CN=ca.%s", cn, strings.ToLower(strings.TrimSuffix(mspID, "MSP")))
+}
+
+func (i *mockIdentity) GetID() (string, error) {
+	return base64.StdEncoding.EncodeToString([]byte(i.id)), nil
+}
+
+func (i *mockIdentity) GetMSPID() (string, error) {
+	return i.mspID, nil
+}
+
+func (i *mockIdentity) GetAttributeValue(attrName string) (string, bool, error) {
+	value, found := i.attributes[attrName]
+	return value, found, nil
+}
+
+func (i *mockIdentity) AssertAttributeValue(attrName, attrValue string) error {
+	value, found := i.attributes[attrName]
+	if !found || value != attrValue {
+		return fmt.Errorf("attribute %s is not %s", attrName, attrValue)
+	}
+	return nil
+}
+
+func (i *mockIdentity) GetX509Certificate() (*x509.Certificate, error) {
+	return nil, nil
+}
+
+// invoke runs fn as a transaction submitted by identity, committing its writes only if it succeeds
+func invoke(stub *mockStub, identity *mockIdentity, fn func(ctx contractapi.TransactionContextInterface) error) error {
+	stub.begin()
+
+	ctx := &contractapi.TransactionContext{}
+	ctx.SetStub(stub)
+	ctx.SetClientIdentity(identity)
+
+	err := fn(ctx)
+	if err == nil {
+		stub.commit()
+	}
+	return err
+}
+
+// mustInvoke runs fn as a transaction submitted by identity and fails the test if it fails
+func mustInvoke(t *testing.T, stub *mockStub, identity *mockIdentity, fn func(ctx contractapi.TransactionContextInterface) error) {
+	t.Helper()
+
+	if err := invoke(stub, identity, fn); err != nil {
+		t.Fatalf("transaction failed: %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package chaincode
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// minterMSPID is the only organization allowed to mint tokens and set their URIs
+const minterMSPID = "Org1MSP"
+
+// Error codes prefix the message of every error a client can act on, e.g. "INSUFFICIENT_FUNDS: ...",
+// so that clients such as the REST API can tell them apart from other failures
+const (
+	unauthorizedCode      = "UNAUTHORIZED"
+	invalidArgumentCode   = "INVALID_ARGUMENT"
+	insufficientFundsCode = "INSUFFICIENT_FUNDS"
+)
+
+// codedError returns an error whose message starts with code, e.g. "UNAUTHORIZED: caller is not owner nor is approved"
+func codedError(code string, format string, args ...interface{}) error {
+	return fmt.Errorf(code+": "+format, args...)
+}
+
+// getClientAccountID returns the decoded x509 identity of the invoking client,
+// e.g. "x509::CN=user1,OU=client,...::CN=ca.org1.example.com,..."
+func getClientAccountID(ctx contractapi.TransactionContextInterface) (string, error) {
+	b64ID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return "", fmt.Errorf("failed to get client id: %v", err)
+	}
+
+	decodedID, err := base64.StdEncoding.DecodeString(b64ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to base64 decode client id: %v", err)
+	}
+
+	return string(decodedID), nil
+}
+
+// checkMinter returns an error unless the client belongs to the minter organization
+func checkMinter(ctx contractapi.TransactionContextInterface) error {
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get MSPID: %v", err)
+	}
+	if clientMSPID != minterMSPID {
+		return codedError(unauthorizedCode, "client is not authorized to mint new tokens")
+	}
+
+	return nil
+}
+
+// emitEvent marshals payload and sets it as the chaincode event of the transaction
+func emitEvent(ctx contractapi.TransactionContextInterface, name string, payload interface{}) error {
+	eventJSON, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	err = ctx.GetStub().SetEvent(name, eventJSON)
+	if err != nil {
+		return fmt.Errorf("failed to set event: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,23 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"log"
+
+	"chilley.net/chaincode"
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+func main() {
+	multiTokenChaincode, err := contractapi.NewChaincode(&chaincode.SmartContract{})
+	if err != nil {
+		log.Panicf("Error creating multi-token chaincode: %v", err)
+	}
+
+	if err := multiTokenChaincode.Start(); err != nil {
+		log.Panicf("Error starting multi-token chaincode: %v", err)
+	}
+}
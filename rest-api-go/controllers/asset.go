@@ -0,0 +1,337 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"rest-api-go/services"
+	"strconv"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// AssetController handles requests for the assets and users of basic-chaincode.
+type AssetController struct {
+	Service *services.GatewayService
+}
+
+// NewAssetController creates a new AssetController instance.
+func NewAssetController(setup *services.OrgSetup) *AssetController {
+	return &AssetController{Service: services.NewGatewayService(setup)}
+}
+
+// page is one page of records returned by a paginated chaincode query.
+type page struct {
+	Records             json.RawMessage `json:"records"`
+	FetchedRecordsCount int32           `json:"fetched_records_count"`
+	Bookmark            string          `json:"bookmark"`
+}
+
+// CreateAsset handles issuing a new asset owned by the client.
+func (c *AssetController) CreateAsset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	id := r.FormValue("id")
+	color := r.FormValue("color")
+	size := r.FormValue("size")
+	appraisedValue := r.FormValue("appraisedvalue")
+
+	if chainCodeName == "" || channelID == "" || id == "" || color == "" || size == "" || appraisedValue == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, id, color, size, or appraisedvalue", http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to create the asset
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, "CreateAsset", []string{id, color, size, appraisedValue})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to create asset: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "Creation successful. Transaction ID: %s", transactionID)
+}
+
+// UpdateAsset handles changing the details of an asset owned by the client.
+func (c *AssetController) UpdateAsset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	id := r.FormValue("id")
+	color := r.FormValue("color")
+	size := r.FormValue("size")
+	appraisedValue := r.FormValue("appraisedvalue")
+
+	if chainCodeName == "" || channelID == "" || id == "" || color == "" || size == "" || appraisedValue == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, id, color, size, or appraisedvalue", http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to update the asset
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, "UpdateAsset", []string{id, color, size, appraisedValue})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to update asset: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "Update successful. Transaction ID: %s", transactionID)
+}
+
+// DeleteAsset handles deleting an asset owned by the client.
+func (c *AssetController) DeleteAsset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	id := r.FormValue("id")
+
+	if chainCodeName == "" || channelID == "" || id == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, or id", http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to delete the asset
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, "DeleteAsset", []string{id})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to delete asset: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "Deletion successful. Transaction ID: %s", transactionID)
+}
+
+// TransferAsset handles moving an asset owned by the client to another client identity.
+func (c *AssetController) TransferAsset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	id := r.FormValue("id")
+	recipientMSP := r.FormValue("recipientMSP")
+	recipientCN := r.FormValue("recipientCN")
+
+	if chainCodeName == "" || channelID == "" || id == "" || recipientMSP == "" || recipientCN == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, id, recipientMSP, or recipientCN", http.StatusBadRequest)
+		return
+	}
+
+	recipient, err := accountID(recipientCN, recipientMSP)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to transfer the asset
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, "TransferAsset", []string{id, recipientMSP, recipient})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to transfer asset: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "Transfer successful. Transaction ID: %s", transactionID)
+}
+
+// GetAssets handles the request to get one page of assets.
+func (c *AssetController) GetAssets(w http.ResponseWriter, r *http.Request) {
+	c.getPage(w, r, "GetAssetsWithPagination", "assets")
+}
+
+// GetUsers handles the request to get one page of users.
+func (c *AssetController) GetUsers(w http.ResponseWriter, r *http.Request) {
+	c.getPage(w, r, "GetUsersWithPagination", "users")
+}
+
+// GetAssetHistory handles the request to get the change history of an asset.
+func (c *AssetController) GetAssetHistory(w http.ResponseWriter, r *http.Request) {
+	c.getHistory(w, r, "GetAssetHistory", "asset")
+}
+
+// GetUserHistory handles the request to get the change history of a user.
+func (c *AssetController) GetUserHistory(w http.ResponseWriter, r *http.Request) {
+	c.getHistory(w, r, "GetUserHistory", "user")
+}
+
+// getHistory queries every version of the record with the given id, oldest first,
+// and responds with them as a timeline.
+func (c *AssetController) getHistory(w http.ResponseWriter, r *http.Request, functionChaincode, name string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.URL.Query().Get("chaincodeid")
+	channelID := r.URL.Query().Get("channelid")
+	id := r.URL.Query().Get("id")
+
+	if chainCodeName == "" || channelID == "" || id == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, or id", http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to get the history
+	result, err := c.Service.EvaluateChaincode(channelID, chainCodeName, functionChaincode, []string{id})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get %s history: %v", name, err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with the timeline
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":       id,
+		"timeline": json.RawMessage(result),
+	})
+}
+
+// getPage queries one page of records with a paginated chaincode function.
+// pagesize defaults to defaultPageSize and may not exceed maxPageSize; cursor is the
+// next_cursor of the previous page, or empty for the first page.
+func (c *AssetController) getPage(w http.ResponseWriter, r *http.Request, functionChaincode, name string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.URL.Query().Get("chaincodeid")
+	channelID := r.URL.Query().Get("channelid")
+
+	if chainCodeName == "" || channelID == "" {
+		http.Error(w, "Missing required fields: chaincodeid or channelid", http.StatusBadRequest)
+		return
+	}
+
+	pageSize, err := parsePageSize(r.URL.Query().Get("pagesize"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	bookmark, err := decodeCursor(r.URL.Query().Get("cursor"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to get the page
+	result, err := c.Service.EvaluateChaincode(channelID, chainCodeName, functionChaincode, []string{strconv.Itoa(pageSize), bookmark})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get %s: %v", name, err), chaincodeErrorStatus(err))
+		return
+	}
+
+	var records page
+	if err := json.Unmarshal(result, &records); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to decode %s: %v", name, err), http.StatusInternalServerError)
+		return
+	}
+
+	// A short page is the last one
+	nextCursor := ""
+	if int(records.FetchedRecordsCount) == pageSize && records.Bookmark != "" {
+		nextCursor = encodeCursor(records.Bookmark)
+	}
+
+	// Respond with the page
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		name:          records.Records,
+		"count":       records.FetchedRecordsCount,
+		"next_cursor": nextCursor,
+	})
+}
+
+// parsePageSize validates the requested page size, defaulting to defaultPageSize.
+func parsePageSize(value string) (int, error) {
+	if value == "" {
+		return defaultPageSize, nil
+	}
+
+	pageSize, err := strconv.Atoi(value)
+	if err != nil || pageSize <= 0 {
+		return 0, fmt.Errorf("pagesize must be a positive integer")
+	}
+	if pageSize > maxPageSize {
+		return 0, fmt.Errorf("pagesize may not exceed %d", maxPageSize)
+	}
+
+	return pageSize, nil
+}
+
+// encodeCursor turns a chaincode bookmark into an opaque cursor for clients.
+func encodeCursor(bookmark string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(bookmark))
+}
+
+// decodeCursor turns a cursor returned by encodeCursor back into a chaincode bookmark.
+func decodeCursor(cursor string) (string, error) {
+	bookmark, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", fmt.Errorf("invalid cursor")
+	}
+
+	return string(bookmark), nil
+}
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"rest-api-go/services"
+)
+
+// BalanceModelController handles requests for the balance model of the token chaincode.
+type BalanceModelController struct {
+	Service *services.GatewayService
+}
+
+// NewBalanceModelController creates a new BalanceModelController instance.
+func NewBalanceModelController(setup *services.OrgSetup) *BalanceModelController {
+	return &BalanceModelController{Service: services.NewGatewayService(setup)}
+}
+
+// Model handles reading the balance model with GET and setting it with POST.
+// model is "account" or "utxo"; it can only change while no tokens exist.
+func (c *BalanceModelController) Model(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		chainCodeName := r.URL.Query().Get("chaincodeid")
+		channelID := r.URL.Query().Get("channelid")
+
+		if chainCodeName == "" || channelID == "" {
+			http.Error(w, "Missing required fields: chaincodeid or channelid", http.StatusBadRequest)
+			return
+		}
+
+		result, err := c.Service.EvaluateChaincode(channelID, chainCodeName, "GetBalanceModel", nil)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to get balance model: %v", err), chaincodeErrorStatus(err))
+			return
+		}
+
+		fmt.Fprintf(w, "Balance model: %s", result)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	model := r.FormValue("model")
+
+	if chainCodeName == "" || channelID == "" || model == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, or model", http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to set the balance model
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, "SetBalanceModel", []string{model})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to set balance model: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "Balance model set. Transaction ID: %s", transactionID)
+}
+
+// Consolidate handles merging the unspent outputs of the client into one
+func (c *BalanceModelController) Consolidate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+
+	if chainCodeName == "" || channelID == "" {
+		http.Error(w, "Missing required fields: chaincodeid or channelid", http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to consolidate, keeping the number of outputs merged
+	merged, transactionID, err := c.Service.SubmitChaincode(channelID, chainCodeName, "Consolidate", nil)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to consolidate outputs: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "Consolidated %s outputs. Transaction ID: %s", merged, transactionID)
+}
@@ -0,0 +1,216 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"rest-api-go/services"
+)
+
+// reviewEvents are the chaincode events of the compliance review queue
+var reviewEvents = map[string]bool{
+	"TransferPending":  true,
+	"TransferApproved": true,
+	"TransferRejected": true,
+	"TransferRefunded": true,
+}
+
+// ComplianceController handles requests for the compliance review of large transfers of the token chaincode.
+type ComplianceController struct {
+	Service *services.GatewayService
+}
+
+// NewComplianceController creates a new ComplianceController instance.
+func NewComplianceController(setup *services.OrgSetup) *ComplianceController {
+	return &ComplianceController{Service: services.NewGatewayService(setup)}
+}
+
+// Config handles reading the compliance threshold with GET and setting it with POST.
+// Transfers of more than threshold tokens wait for review for up to reviewperiod seconds; a threshold of 0 reviews none.
+func (c *ComplianceController) Config(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		chainCodeName := r.URL.Query().Get("chaincodeid")
+		channelID := r.URL.Query().Get("channelid")
+
+		if chainCodeName == "" || channelID == "" {
+			http.Error(w, "Missing required fields: chaincodeid or channelid", http.StatusBadRequest)
+			return
+		}
+
+		result, err := c.Service.EvaluateChaincode(channelID, chainCodeName, "GetComplianceConfig", nil)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to get compliance config: %v", err), chaincodeErrorStatus(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(result)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	threshold := r.FormValue("threshold")
+	reviewPeriod := r.FormValue("reviewperiod")
+
+	if chainCodeName == "" || channelID == "" || threshold == "" || reviewPeriod == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, threshold, or reviewperiod", http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to set the compliance threshold
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, "SetComplianceConfig", []string{threshold, reviewPeriod})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to set compliance config: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "Compliance config set. Transaction ID: %s", transactionID)
+}
+
+// GetPending handles listing the review queue, the transfers awaiting review.
+func (c *ComplianceController) GetPending(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.URL.Query().Get("chaincodeid")
+	channelID := r.URL.Query().Get("channelid")
+
+	if chainCodeName == "" || channelID == "" {
+		http.Error(w, "Missing required fields: chaincodeid or channelid", http.StatusBadRequest)
+		return
+	}
+
+	result, err := c.Service.EvaluateChaincode(channelID, chainCodeName, "GetPendingTransfers", nil)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get pending transfers: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(result)
+}
+
+// Approve handles a compliance officer approving a pending transfer, which settles it.
+func (c *ComplianceController) Approve(w http.ResponseWriter, r *http.Request) {
+	c.transferTransaction(w, r, "ApproveTransfer", "approve transfer", "Transfer approved", false)
+}
+
+// Reject handles a compliance officer rejecting a pending transfer, which refunds its sender. reason is required.
+func (c *ComplianceController) Reject(w http.ResponseWriter, r *http.Request) {
+	c.transferTransaction(w, r, "RejectTransfer", "reject transfer", "Transfer rejected", true)
+}
+
+// Refund handles refunding the sender of a pending transfer nobody reviewed in time.
+func (c *ComplianceController) Refund(w http.ResponseWriter, r *http.Request) {
+	c.transferTransaction(w, r, "RefundTransfer", "refund transfer", "Transfer refunded", false)
+}
+
+// transferTransaction submits a chaincode function taking the ID of a pending transfer, followed by
+// the reason of the decision when withReason is set.
+func (c *ComplianceController) transferTransaction(w http.ResponseWriter, r *http.Request, function string, action string, success string, withReason bool) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	id := r.FormValue("id")
+	reason := r.FormValue("reason")
+
+	if chainCodeName == "" || channelID == "" || id == "" || (withReason && reason == "") {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, id, or reason", http.StatusBadRequest)
+		return
+	}
+
+	args := []string{id}
+	if withReason {
+		args = append(args, reason)
+	}
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, function, args)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to %s: %v", action, err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "%s. Transaction ID: %s", success, transactionID)
+}
+
+// Events handles streaming the changes of the review queue as server-sent events, one per pending
+// transfer placed, approved, rejected or refunded, until the client disconnects.
+func (c *ComplianceController) Events(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.URL.Query().Get("chaincodeid")
+	channelID := r.URL.Query().Get("channelid")
+
+	if chainCodeName == "" || channelID == "" {
+		http.Error(w, "Missing required fields: chaincodeid or channelid", http.StatusBadRequest)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	// The events stop when the client disconnects and the request context is done
+	events, err := c.Service.ChaincodeEvents(r.Context(), channelID, chainCodeName)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to listen for review events: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	flusher.Flush()
+
+	for event := range events {
+		if !reviewEvents[event.EventName] {
+			continue
+		}
+		fmt.Fprintf(w, "event: %s\nid: %s\ndata: %s\n\n", event.EventName, event.TransactionID, event.Payload)
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"rest-api-go/services"
+)
+
+// FeeController handles requests for the transfer fees of the token chaincode.
+type FeeController struct {
+	Service *services.GatewayService
+}
+
+// NewFeeController creates a new FeeController instance.
+func NewFeeController(setup *services.OrgSetup) *FeeController {
+	return &FeeController{Service: services.NewGatewayService(setup)}
+}
+
+// Config handles reading the fee charged on transfers with GET and setting it with POST.
+// rate is in basis points of the amount, raised to minfee and capped at maxfee unless it is zero.
+func (c *FeeController) Config(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		chainCodeName := r.URL.Query().Get("chaincodeid")
+		channelID := r.URL.Query().Get("channelid")
+
+		if chainCodeName == "" || channelID == "" {
+			http.Error(w, "Missing required fields: chaincodeid or channelid", http.StatusBadRequest)
+			return
+		}
+
+		result, err := c.Service.EvaluateChaincode(channelID, chainCodeName, "GetFeeConfig", nil)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to get fee config: %v", err), chaincodeErrorStatus(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(result)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	rate := r.FormValue("rate")
+	minFee := r.FormValue("minfee")
+	maxFee := r.FormValue("maxfee")
+	treasuryCN := r.FormValue("treasuryCN")
+
+	if chainCodeName == "" || channelID == "" || rate == "" || minFee == "" || maxFee == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, rate, minfee, or maxfee", http.StatusBadRequest)
+		return
+	}
+
+	// An empty treasury is only accepted by the chaincode when no fee is charged
+	treasury := ""
+	if treasuryCN != "" {
+		treasury, err = accountID(treasuryCN, r.URL.Query().Get("treasuryMSP"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Call the service to set the fees
+	args := []string{rate, minFee, maxFee, treasury}
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, "SetFeeConfig", args)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to set fee config: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "Fee config set. Transaction ID: %s", transactionID)
+}
+
+// SetExemption handles exempting a system account from transfer fees, or lifting its exemption.
+// exempt is "true" or "false".
+func (c *FeeController) SetExemption(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	accountCN := r.FormValue("accountCN")
+	exempt := r.FormValue("exempt")
+
+	if chainCodeName == "" || channelID == "" || accountCN == "" || exempt == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, accountCN, or exempt", http.StatusBadRequest)
+		return
+	}
+
+	account, err := accountID(accountCN, r.FormValue("accountMSP"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to update the exemption
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, "SetFeeExemption", []string{account, exempt})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to set fee exemption: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "Fee exemption set. Transaction ID: %s", transactionID)
+}
@@ -0,0 +1,233 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"rest-api-go/services"
+	"strings"
+)
+
+// GovernanceController handles requests for the multi-signature governance of the token chaincode.
+// Mints and role changes are proposed as operations that governors of distinct organizations approve.
+type GovernanceController struct {
+	Service *services.GatewayService
+}
+
+// NewGovernanceController creates a new GovernanceController instance.
+func NewGovernanceController(setup *services.OrgSetup) *GovernanceController {
+	return &GovernanceController{Service: services.NewGatewayService(setup)}
+}
+
+// Initialize handles handing minting and role changes over to governance.
+// orgs is a comma separated list of MSP IDs, threshold the number of them that must approve an operation.
+func (c *GovernanceController) Initialize(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	orgs := r.FormValue("orgs")
+	threshold := r.FormValue("threshold")
+
+	if chainCodeName == "" || channelID == "" || orgs == "" || threshold == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, orgs, or threshold", http.StatusBadRequest)
+		return
+	}
+
+	// The chaincode takes the organizations as a JSON array
+	orgList := strings.Split(orgs, ",")
+	for i := range orgList {
+		orgList[i] = strings.TrimSpace(orgList[i])
+	}
+	orgsJSON, err := json.Marshal(orgList)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to encode organizations: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// Call the service to initialize governance
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, "InitializeGovernance", []string{string(orgsJSON), threshold})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize governance: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "Governance initialized. Transaction ID: %s", transactionID)
+}
+
+// ProposeMint handles a governor proposing to mint tokens to a recipient.
+// expiration is in seconds since the Unix epoch.
+func (c *GovernanceController) ProposeMint(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	id := r.FormValue("id")
+	recipientCN := r.FormValue("recipientCN")
+	amount := r.FormValue("amount")
+	expiration := r.FormValue("expiration")
+
+	if chainCodeName == "" || channelID == "" || id == "" || recipientCN == "" || amount == "" || expiration == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, id, recipientCN, amount, or expiration", http.StatusBadRequest)
+		return
+	}
+
+	recipient, err := accountID(recipientCN, r.FormValue("recipientMSP"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to propose the mint
+	args := []string{id, recipient, amount, expiration}
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, "ProposeMint", args)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to propose mint: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "Mint proposed. Transaction ID: %s", transactionID)
+}
+
+// ProposeRoleChange handles a governor proposing to grant a role to an account, or to revoke it.
+// grant is "true" or "false" and expiration is in seconds since the Unix epoch.
+func (c *GovernanceController) ProposeRoleChange(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	id := r.FormValue("id")
+	role := r.FormValue("role")
+	accountCN := r.FormValue("accountCN")
+	grant := r.FormValue("grant")
+	expiration := r.FormValue("expiration")
+
+	if chainCodeName == "" || channelID == "" || id == "" || role == "" || accountCN == "" || grant == "" || expiration == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, id, role, accountCN, grant, or expiration", http.StatusBadRequest)
+		return
+	}
+
+	account, err := accountID(accountCN, r.FormValue("accountMSP"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to propose the role change
+	args := []string{id, role, account, grant, expiration}
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, "ProposeRoleChange", args)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to propose role change: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "Role change proposed. Transaction ID: %s", transactionID)
+}
+
+// Approve handles a governor approving an operation on behalf of its organization.
+func (c *GovernanceController) Approve(w http.ResponseWriter, r *http.Request) {
+	c.operationTransaction(w, r, "ApproveOperation", "approve operation", "Operation approved")
+}
+
+// Reject handles a governor rejecting an operation on behalf of its organization.
+func (c *GovernanceController) Reject(w http.ResponseWriter, r *http.Request) {
+	c.operationTransaction(w, r, "RejectOperation", "reject operation", "Operation rejected")
+}
+
+// operationTransaction submits a chaincode function taking the ID of an operation as its only argument.
+func (c *GovernanceController) operationTransaction(w http.ResponseWriter, r *http.Request, function string, action string, success string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	id := r.FormValue("id")
+
+	if chainCodeName == "" || channelID == "" || id == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, or id", http.StatusBadRequest)
+		return
+	}
+
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, function, []string{id})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to %s: %v", action, err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "%s. Transaction ID: %s", success, transactionID)
+}
+
+// GetPending handles listing the operations awaiting approval that have not expired.
+func (c *GovernanceController) GetPending(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.URL.Query().Get("chaincodeid")
+	channelID := r.URL.Query().Get("channelid")
+
+	if chainCodeName == "" || channelID == "" {
+		http.Error(w, "Missing required fields: chaincodeid or channelid", http.StatusBadRequest)
+		return
+	}
+
+	result, err := c.Service.EvaluateChaincode(channelID, chainCodeName, "GetPendingOperations", nil)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get pending operations: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(result)
+}
@@ -0,0 +1,194 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"rest-api-go/services"
+)
+
+// HoldController handles requests for the holds of the token chaincode.
+type HoldController struct {
+	Service *services.GatewayService
+}
+
+// NewHoldController creates a new HoldController instance.
+func NewHoldController(setup *services.OrgSetup) *HoldController {
+	return &HoldController{Service: services.NewGatewayService(setup)}
+}
+
+// Place handles reserving tokens of the client for a recipient until a notary executes or releases the hold.
+// expiration is in seconds since the Unix epoch.
+func (c *HoldController) Place(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	id := r.FormValue("id")
+	recipientCN := r.FormValue("recipientCN")
+	notaryCN := r.FormValue("notaryCN")
+	amount := r.FormValue("amount")
+	expiration := r.FormValue("expiration")
+
+	if chainCodeName == "" || channelID == "" || id == "" || recipientCN == "" || notaryCN == "" || amount == "" || expiration == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, id, recipientCN, notaryCN, amount, or expiration", http.StatusBadRequest)
+		return
+	}
+
+	recipient, err := accountID(recipientCN, r.FormValue("recipientMSP"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	notary, err := accountID(notaryCN, r.FormValue("notaryMSP"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to place the hold
+	args := []string{id, recipient, notary, amount, expiration}
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, "PlaceHold", args)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to place hold: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "Hold placed. Transaction ID: %s", transactionID)
+}
+
+// Execute handles the notary of a hold transferring its tokens to the recipient.
+func (c *HoldController) Execute(w http.ResponseWriter, r *http.Request) {
+	c.holdTransaction(w, r, "ExecuteHold", "execute hold", "Hold executed")
+}
+
+// Release handles returning the tokens of a hold to its owner, by the notary or once the hold expired.
+func (c *HoldController) Release(w http.ResponseWriter, r *http.Request) {
+	c.holdTransaction(w, r, "ReleaseHold", "release hold", "Hold released")
+}
+
+// holdTransaction submits a chaincode function taking the ID of a hold as its only argument.
+func (c *HoldController) holdTransaction(w http.ResponseWriter, r *http.Request, function string, action string, success string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	id := r.FormValue("id")
+
+	if chainCodeName == "" || channelID == "" || id == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, or id", http.StatusBadRequest)
+		return
+	}
+
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, function, []string{id})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to %s: %v", action, err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "%s. Transaction ID: %s", success, transactionID)
+}
+
+// GetHold handles reading a hold.
+func (c *HoldController) GetHold(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.URL.Query().Get("chaincodeid")
+	channelID := r.URL.Query().Get("channelid")
+	id := r.URL.Query().Get("id")
+
+	if chainCodeName == "" || channelID == "" || id == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, or id", http.StatusBadRequest)
+		return
+	}
+
+	result, err := c.Service.EvaluateChaincode(channelID, chainCodeName, "ReadHold", []string{id})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to read hold: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(result)
+}
+
+// GetBalance handles reading the balance of an account split into held and available tokens,
+// the account of the client by default.
+func (c *HoldController) GetBalance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.URL.Query().Get("chaincodeid")
+	channelID := r.URL.Query().Get("channelid")
+	accountCN := r.URL.Query().Get("accountCN")
+
+	if chainCodeName == "" || channelID == "" {
+		http.Error(w, "Missing required fields: chaincodeid or channelid", http.StatusBadRequest)
+		return
+	}
+
+	var account string
+	if accountCN != "" {
+		account, err = accountID(accountCN, r.URL.Query().Get("accountMSP"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	} else {
+		clientID, err := c.Service.EvaluateChaincode(channelID, chainCodeName, "ClientAccountID", nil)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to get client account ID: %v", err), chaincodeErrorStatus(err))
+			return
+		}
+		account = string(clientID)
+	}
+
+	result, err := c.Service.EvaluateChaincode(channelID, chainCodeName, "BalanceDetailsOf", []string{account})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get balance: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(result)
+}
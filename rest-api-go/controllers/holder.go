@@ -0,0 +1,217 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"rest-api-go/services"
+	"sort"
+	"strconv"
+)
+
+// holderPageSize is the page size used to read every holder of the token chaincode
+const holderPageSize = "1000"
+
+// holder is an account holding tokens and its balance, as returned by the token chaincode
+type holder struct {
+	Account string `json:"account"`
+	Balance int    `json:"balance"`
+}
+
+// holderPage is a page of holders returned by ListHolders
+type holderPage struct {
+	Holders  []holder `json:"holders"`
+	Bookmark string   `json:"bookmark"`
+}
+
+// HolderController handles requests for the holders of the token chaincode.
+type HolderController struct {
+	Service *services.GatewayService
+}
+
+// NewHolderController creates a new HolderController instance.
+func NewHolderController(setup *services.OrgSetup) *HolderController {
+	return &HolderController{Service: services.NewGatewayService(setup)}
+}
+
+// listHolders returns one page of holders starting at bookmark
+func (c *HolderController) listHolders(channelID, chainCodeName, pageSize, bookmark string) (*holderPage, error) {
+	result, err := c.Service.EvaluateChaincode(channelID, chainCodeName, "ListHolders", []string{pageSize, bookmark})
+	if err != nil {
+		return nil, err
+	}
+
+	page := &holderPage{}
+	err = json.Unmarshal(result, page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode holders: %v", err)
+	}
+
+	return page, nil
+}
+
+// allHolders returns every holder, sorted by balance from the largest
+func (c *HolderController) allHolders(channelID, chainCodeName string) ([]holder, error) {
+	var holders []holder
+	bookmark := ""
+	for {
+		page, err := c.listHolders(channelID, chainCodeName, holderPageSize, bookmark)
+		if err != nil {
+			return nil, err
+		}
+		holders = append(holders, page.Holders...)
+
+		bookmark = page.Bookmark
+		if bookmark == "" || len(page.Holders) == 0 {
+			break
+		}
+	}
+
+	sort.SliceStable(holders, func(i, j int) bool { return holders[i].Balance > holders[j].Balance })
+	return holders, nil
+}
+
+// List handles reading one page of holders. pagesize is required; bookmark, returned with
+// each page, fetches the next one.
+func (c *HolderController) List(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.URL.Query().Get("chaincodeid")
+	channelID := r.URL.Query().Get("channelid")
+	pageSize := r.URL.Query().Get("pagesize")
+	bookmark := r.URL.Query().Get("bookmark")
+
+	if chainCodeName == "" || channelID == "" || pageSize == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, or pagesize", http.StatusBadRequest)
+		return
+	}
+
+	page, err := c.listHolders(channelID, chainCodeName, pageSize, bookmark)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list holders: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// Top handles reading the n holders with the largest balances
+func (c *HolderController) Top(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.URL.Query().Get("chaincodeid")
+	channelID := r.URL.Query().Get("channelid")
+	n, err := strconv.Atoi(r.URL.Query().Get("n"))
+
+	if chainCodeName == "" || channelID == "" || err != nil || n <= 0 {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, or a positive n", http.StatusBadRequest)
+		return
+	}
+
+	holders, err := c.allHolders(channelID, chainCodeName)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list holders: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+	if len(holders) > n {
+		holders = holders[:n]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(holders)
+}
+
+// Concentration handles reading how concentrated the token supply is among its holders:
+// the share of the supply held by the top holders (10 unless top is given), the
+// Herfindahl-Hirschman index, from 1/holders to 1, and the Gini coefficient, from 0 to 1.
+func (c *HolderController) Concentration(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.URL.Query().Get("chaincodeid")
+	channelID := r.URL.Query().Get("channelid")
+
+	if chainCodeName == "" || channelID == "" {
+		http.Error(w, "Missing required fields: chaincodeid or channelid", http.StatusBadRequest)
+		return
+	}
+
+	top := 10
+	if topParam := r.URL.Query().Get("top"); topParam != "" {
+		top, err = strconv.Atoi(topParam)
+		if err != nil || top <= 0 {
+			http.Error(w, "top must be a positive integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	holders, err := c.allHolders(channelID, chainCodeName)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list holders: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Holders are sorted from the largest balance, so the top holders come first
+	supply := 0.0
+	topSupply := 0.0
+	for i, holder := range holders {
+		supply += float64(holder.Balance)
+		if i < top {
+			topSupply += float64(holder.Balance)
+		}
+	}
+
+	topShare, herfindahl, gini := 0.0, 0.0, 0.0
+	if supply > 0 {
+		topShare = topSupply / supply
+		weighted := 0.0
+		n := float64(len(holders))
+		for i, holder := range holders {
+			share := float64(holder.Balance) / supply
+			herfindahl += share * share
+			// Rank from the smallest balance, as the Gini formula expects ascending order
+			weighted += (n - float64(i)) * float64(holder.Balance)
+		}
+		gini = (2*weighted)/(n*supply) - (n+1)/n
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"holders":    len(holders),
+		"supply":     supply,
+		"top":        top,
+		"top_share":  topShare,
+		"herfindahl": herfindahl,
+		"gini":       gini,
+	})
+}
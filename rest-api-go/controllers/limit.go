@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"rest-api-go/services"
+)
+
+// LimitController handles requests for the daily transfer limits of the token chaincode.
+type LimitController struct {
+	Service *services.GatewayService
+}
+
+// NewLimitController creates a new LimitController instance.
+func NewLimitController(setup *services.OrgSetup) *LimitController {
+	return &LimitController{Service: services.NewGatewayService(setup)}
+}
+
+// SetDefault handles setting the most an account without its own limit may send over a rolling day.
+// A limit of 0 lifts the default limit.
+func (c *LimitController) SetDefault(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	limit := r.FormValue("limit")
+
+	if chainCodeName == "" || channelID == "" || limit == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, or limit", http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to set the default limit
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, "SetDefaultTransferLimit", []string{limit})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to set default transfer limit: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "Default transfer limit set. Transaction ID: %s", transactionID)
+}
+
+// Account handles reading the limit of an account and how much of it was used over the last day with GET,
+// and setting the limit of the account with POST. A limit of 0 falls back to the default limit.
+func (c *LimitController) Account(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		chainCodeName := r.URL.Query().Get("chaincodeid")
+		channelID := r.URL.Query().Get("channelid")
+		accountCN := r.URL.Query().Get("accountCN")
+
+		if chainCodeName == "" || channelID == "" || accountCN == "" {
+			http.Error(w, "Missing required fields: chaincodeid, channelid, or accountCN", http.StatusBadRequest)
+			return
+		}
+
+		account, err := accountID(accountCN, r.URL.Query().Get("accountMSP"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		result, err := c.Service.EvaluateChaincode(channelID, chainCodeName, "GetTransferLimit", []string{account})
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to get transfer limit: %v", err), chaincodeErrorStatus(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(result)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	accountCN := r.FormValue("accountCN")
+	limit := r.FormValue("limit")
+
+	if chainCodeName == "" || channelID == "" || accountCN == "" || limit == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, accountCN, or limit", http.StatusBadRequest)
+		return
+	}
+
+	account, err := accountID(accountCN, r.FormValue("accountMSP"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to set the limit of the account
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, "SetTransferLimit", []string{account, limit})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to set transfer limit: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "Transfer limit set. Transaction ID: %s", transactionID)
+}
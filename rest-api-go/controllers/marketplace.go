@@ -0,0 +1,167 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"rest-api-go/services"
+)
+
+// MarketplaceController handles requests for buying and selling basic-chaincode assets with tokens.
+type MarketplaceController struct {
+	Service *services.GatewayService
+}
+
+// NewMarketplaceController creates a new MarketplaceController instance.
+func NewMarketplaceController(setup *services.OrgSetup) *MarketplaceController {
+	return &MarketplaceController{Service: services.NewGatewayService(setup)}
+}
+
+// ListAssetForSale handles offering an asset owned by the client for a price in tokens of a token chaincode.
+func (c *MarketplaceController) ListAssetForSale(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	assetChainCodeName := r.FormValue("assetchaincodeid")
+	tokenChainCodeName := r.FormValue("tokenchaincodeid")
+	assetID := r.FormValue("assetid")
+	price := r.FormValue("price")
+
+	if chainCodeName == "" || channelID == "" || assetChainCodeName == "" || tokenChainCodeName == "" || assetID == "" || price == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, assetchaincodeid, tokenchaincodeid, assetid, or price", http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to list the asset
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, "ListAssetForSale", []string{assetChainCodeName, tokenChainCodeName, assetID, price})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list asset: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "Listing successful. Transaction ID: %s", transactionID)
+}
+
+// CancelListing handles withdrawing an asset of the client from sale.
+func (c *MarketplaceController) CancelListing(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	assetID := r.FormValue("assetid")
+
+	if chainCodeName == "" || channelID == "" || assetID == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, or assetid", http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to cancel the listing
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, "CancelListing", []string{assetID})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to cancel listing: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "Listing cancelled. Transaction ID: %s", transactionID)
+}
+
+// BuyAsset handles paying for a listed asset with tokens and taking ownership of it in one transaction.
+// The asset and token chaincodes are those recorded in the listing; maxprice is the most the client pays.
+func (c *MarketplaceController) BuyAsset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	assetID := r.FormValue("assetid")
+	maxPrice := r.FormValue("maxprice")
+
+	if chainCodeName == "" || channelID == "" || assetID == "" || maxPrice == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, assetid, or maxprice", http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to buy the asset
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, "BuyAsset", []string{assetID, maxPrice})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to buy asset: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "Purchase successful. Transaction ID: %s", transactionID)
+}
+
+// GetListings handles the request to get every asset for sale, or a single one when assetid is given.
+func (c *MarketplaceController) GetListings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.URL.Query().Get("chaincodeid")
+	channelID := r.URL.Query().Get("channelid")
+	assetID := r.URL.Query().Get("assetid")
+
+	if chainCodeName == "" || channelID == "" {
+		http.Error(w, "Missing required fields: chaincodeid or channelid", http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to get the listings
+	var result []byte
+	if assetID != "" {
+		result, err = c.Service.EvaluateChaincode(channelID, chainCodeName, "ReadListing", []string{assetID})
+	} else {
+		result, err = c.Service.EvaluateChaincode(channelID, chainCodeName, "GetAllListings", nil)
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get listings: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with the listings
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"listings": json.RawMessage(result),
+	})
+}
@@ -0,0 +1,468 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"rest-api-go/services"
+	"strconv"
+	"strings"
+)
+
+// MultiTokenController handles requests for the multi-token (ERC-1155 style) chaincode.
+type MultiTokenController struct {
+	Service *services.GatewayService
+}
+
+// NewMultiTokenController creates a new MultiTokenController instance.
+func NewMultiTokenController(setup *services.OrgSetup) *MultiTokenController {
+	return &MultiTokenController{Service: services.NewGatewayService(setup)}
+}
+
+// Mint handles minting a single token type to an account.
+func (c *MultiTokenController) Mint(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	recipientCN := r.FormValue("recipientCN")
+	id := r.FormValue("id")
+	amount := r.FormValue("amount")
+
+	if chainCodeName == "" || channelID == "" || recipientCN == "" || id == "" || amount == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, recipientCN, id, or amount", http.StatusBadRequest)
+		return
+	}
+
+	recipient, err := accountID(recipientCN, r.FormValue("recipientMSP"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to mint tokens
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, "Mint", []string{recipient, id, amount})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to mint tokens: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "Minting successful. Transaction ID: %s", transactionID)
+}
+
+// MintBatch handles minting several token types to an account.
+// ids and amounts are comma separated lists of the same length.
+func (c *MultiTokenController) MintBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	recipientCN := r.FormValue("recipientCN")
+
+	if chainCodeName == "" || channelID == "" || recipientCN == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, or recipientCN", http.StatusBadRequest)
+		return
+	}
+
+	ids, amounts, err := parseBatch(r.FormValue("ids"), r.FormValue("amounts"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	recipient, err := accountID(recipientCN, r.FormValue("recipientMSP"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to mint tokens
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, "MintBatch", []string{recipient, ids, amounts})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to mint tokens: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "Minting successful. Transaction ID: %s", transactionID)
+}
+
+// GetBalance handles the request to get the balance of one token type for an account.
+func (c *MultiTokenController) GetBalance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.URL.Query().Get("chaincodeid")
+	channelID := r.URL.Query().Get("channelid")
+	accountCN := r.URL.Query().Get("accountCN")
+	id := r.URL.Query().Get("id")
+
+	if chainCodeName == "" || channelID == "" || accountCN == "" || id == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, accountCN, or id", http.StatusBadRequest)
+		return
+	}
+
+	account, err := accountID(accountCN, r.URL.Query().Get("accountMSP"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to get the balance
+	result, err := c.Service.EvaluateChaincode(channelID, chainCodeName, "BalanceOf", []string{account, id})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get balance: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with the balance
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"balance": json.RawMessage(result),
+	})
+}
+
+// GetBalanceBatch handles the request to get balances for several (account, id) pairs.
+// accountCNs and ids are comma separated lists of the same length.
+func (c *MultiTokenController) GetBalanceBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.URL.Query().Get("chaincodeid")
+	channelID := r.URL.Query().Get("channelid")
+	accountCNs := r.URL.Query().Get("accountCNs")
+
+	if chainCodeName == "" || channelID == "" || accountCNs == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, or accountCNs", http.StatusBadRequest)
+		return
+	}
+
+	var accounts []string
+	for _, cn := range strings.Split(accountCNs, ",") {
+		account, err := accountID(cn, r.URL.Query().Get("accountMSP"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		accounts = append(accounts, account)
+	}
+	accountsJSON, err := json.Marshal(accounts)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to encode accounts: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	ids, err := parseUintList(r.URL.Query().Get("ids"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(ids) != len(accounts) {
+		http.Error(w, "accountCNs and ids must have the same length", http.StatusBadRequest)
+		return
+	}
+	idsJSON, err := json.Marshal(ids)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to encode ids: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// Call the service to get the balances
+	result, err := c.Service.EvaluateChaincode(channelID, chainCodeName, "BalanceOfBatch", []string{string(accountsJSON), string(idsJSON)})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get balances: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with the balances
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"balances": json.RawMessage(result),
+	})
+}
+
+// Transfer handles moving a single token type between accounts.
+func (c *MultiTokenController) Transfer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	senderCN := r.FormValue("senderCN")
+	recipientCN := r.FormValue("recipientCN")
+	id := r.FormValue("id")
+	amount := r.FormValue("amount")
+
+	if chainCodeName == "" || channelID == "" || senderCN == "" || recipientCN == "" || id == "" || amount == "" {
+		http.Error(w, "Missing required form data", http.StatusBadRequest)
+		return
+	}
+
+	sender, err := accountID(senderCN, r.FormValue("senderMSP"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	recipient, err := accountID(recipientCN, r.FormValue("recipientMSP"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to transfer tokens
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, "SafeTransferFrom", []string{sender, recipient, id, amount})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to transfer tokens: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "Transfer successful. Transaction ID: %s", transactionID)
+}
+
+// TransferBatch handles moving several token types between accounts.
+// ids and amounts are comma separated lists of the same length.
+func (c *MultiTokenController) TransferBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	senderCN := r.FormValue("senderCN")
+	recipientCN := r.FormValue("recipientCN")
+
+	if chainCodeName == "" || channelID == "" || senderCN == "" || recipientCN == "" {
+		http.Error(w, "Missing required form data", http.StatusBadRequest)
+		return
+	}
+
+	ids, amounts, err := parseBatch(r.FormValue("ids"), r.FormValue("amounts"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sender, err := accountID(senderCN, r.FormValue("senderMSP"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	recipient, err := accountID(recipientCN, r.FormValue("recipientMSP"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to transfer tokens
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, "SafeBatchTransferFrom", []string{sender, recipient, ids, amounts})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to transfer tokens: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "Transfer successful. Transaction ID: %s", transactionID)
+}
+
+// SetApprovalForAll handles granting or revoking an operator over all tokens of the client.
+func (c *MultiTokenController) SetApprovalForAll(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	operatorCN := r.FormValue("operatorCN")
+	approved := r.FormValue("approved")
+
+	if chainCodeName == "" || channelID == "" || operatorCN == "" || approved == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, operatorCN, or approved", http.StatusBadRequest)
+		return
+	}
+	if _, err := strconv.ParseBool(approved); err != nil {
+		http.Error(w, "approved must be true or false", http.StatusBadRequest)
+		return
+	}
+
+	operator, err := accountID(operatorCN, r.FormValue("operatorMSP"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to set the approval
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, "SetApprovalForAll", []string{operator, approved})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to set approval: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "Approval updated. Transaction ID: %s", transactionID)
+}
+
+// URI handles reading (GET) and setting (POST) the metadata URI of a token type.
+func (c *MultiTokenController) URI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	id := r.FormValue("id")
+
+	if chainCodeName == "" || channelID == "" || id == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, or id", http.StatusBadRequest)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		// Call the service to get the URI
+		result, err := c.Service.EvaluateChaincode(channelID, chainCodeName, "URI", []string{id})
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to get URI: %v", err), chaincodeErrorStatus(err))
+			return
+		}
+
+		// Respond with the URI
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":  id,
+			"uri": string(result),
+		})
+		return
+	}
+
+	uri := r.FormValue("uri")
+	if uri == "" {
+		http.Error(w, "Missing required field: uri", http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to set the URI
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, "SetURI", []string{id, uri})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to set URI: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "URI updated. Transaction ID: %s", transactionID)
+}
+
+// parseBatch converts comma separated ids and amounts into the JSON arrays expected by the chaincode.
+func parseBatch(idList, amountList string) (string, string, error) {
+	ids, err := parseUintList(idList)
+	if err != nil {
+		return "", "", err
+	}
+	amounts, err := parseUintList(amountList)
+	if err != nil {
+		return "", "", err
+	}
+	if len(ids) != len(amounts) {
+		return "", "", fmt.Errorf("ids and amounts must have the same length")
+	}
+
+	idsJSON, err := json.Marshal(ids)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode ids: %v", err)
+	}
+	amountsJSON, err := json.Marshal(amounts)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode amounts: %v", err)
+	}
+
+	return string(idsJSON), string(amountsJSON), nil
+}
+
+// parseUintList parses a comma separated list of unsigned integers.
+func parseUintList(list string) ([]uint64, error) {
+	if list == "" {
+		return nil, fmt.Errorf("empty list")
+	}
+
+	var values []uint64
+	for _, item := range strings.Split(list, ",") {
+		value, err := strconv.ParseUint(strings.TrimSpace(item), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q: %v", item, err)
+		}
+		values = append(values, value)
+	}
+
+	return values, nil
+}
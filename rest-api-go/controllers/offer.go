@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"rest-api-go/services"
+)
+
+// OfferController handles requests for two-phase transfers of basic-chaincode assets,
+// where the owner offers an asset and the recipient accepts or rejects it.
+type OfferController struct {
+	Service *services.GatewayService
+}
+
+// NewOfferController creates a new OfferController instance.
+func NewOfferController(setup *services.OrgSetup) *OfferController {
+	return &OfferController{Service: services.NewGatewayService(setup)}
+}
+
+// Create handles offering an asset of the client to a recipient for ttl seconds.
+func (c *OfferController) Create(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	assetID := r.FormValue("assetid")
+	recipientMSP := r.FormValue("recipientMSP")
+	recipientCN := r.FormValue("recipientCN")
+	ttl := r.FormValue("ttl")
+
+	if chainCodeName == "" || channelID == "" || assetID == "" || recipientMSP == "" || recipientCN == "" || ttl == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, assetid, recipientMSP, recipientCN, or ttl", http.StatusBadRequest)
+		return
+	}
+
+	recipient, err := accountID(recipientCN, recipientMSP)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to create the offer
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, "OfferAsset", []string{assetID, recipientMSP, recipient, ttl})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to offer asset: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "Offer successful. Transaction ID: %s", transactionID)
+}
+
+// Accept handles taking ownership of an asset offered to the client.
+func (c *OfferController) Accept(w http.ResponseWriter, r *http.Request) {
+	c.respond(w, r, "AcceptOffer", "accept offer", "Offer accepted")
+}
+
+// Reject handles declining an offer made to the client.
+func (c *OfferController) Reject(w http.ResponseWriter, r *http.Request) {
+	c.respond(w, r, "RejectOffer", "reject offer", "Offer rejected")
+}
+
+// Cancel handles withdrawing an offer made by the client.
+func (c *OfferController) Cancel(w http.ResponseWriter, r *http.Request) {
+	c.respond(w, r, "CancelOffer", "cancel offer", "Offer cancelled")
+}
+
+// respond submits a chaincode function settling the offer for assetid.
+func (c *OfferController) respond(w http.ResponseWriter, r *http.Request, functionChaincode, action, success string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	assetID := r.FormValue("assetid")
+
+	if chainCodeName == "" || channelID == "" || assetID == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, or assetid", http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to settle the offer
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, functionChaincode, []string{assetID})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to %s: %v", action, err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "%s. Transaction ID: %s", success, transactionID)
+}
+
+// GetInbound handles the request to get the pending offers made to an account.
+func (c *OfferController) GetInbound(w http.ResponseWriter, r *http.Request) {
+	c.getPending(w, r, "GetInboundOffers")
+}
+
+// GetOutbound handles the request to get the pending offers made by an account.
+func (c *OfferController) GetOutbound(w http.ResponseWriter, r *http.Request) {
+	c.getPending(w, r, "GetOutboundOffers")
+}
+
+// getPending queries the pending offers of the account with common name accountCN.
+func (c *OfferController) getPending(w http.ResponseWriter, r *http.Request, functionChaincode string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.URL.Query().Get("chaincodeid")
+	channelID := r.URL.Query().Get("channelid")
+	accountCN := r.URL.Query().Get("accountCN")
+
+	if chainCodeName == "" || channelID == "" || accountCN == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, or accountCN", http.StatusBadRequest)
+		return
+	}
+
+	account, err := accountID(accountCN, r.URL.Query().Get("accountMSP"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to get the offers
+	result, err := c.Service.EvaluateChaincode(channelID, chainCodeName, functionChaincode, []string{account})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get offers: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with the offers
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"offers": json.RawMessage(result),
+	})
+}
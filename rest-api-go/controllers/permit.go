@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"rest-api-go/services"
+)
+
+// PermitController handles requests for the signed permits of the token chaincode.
+// A permit lets an owner approve a spender off-chain; any client may then relay it.
+type PermitController struct {
+	Service *services.GatewayService
+}
+
+// NewPermitController creates a new PermitController instance.
+func NewPermitController(setup *services.OrgSetup) *PermitController {
+	return &PermitController{Service: services.NewGatewayService(setup)}
+}
+
+// RegisterKey handles registering the ECDSA P-256 public key, PEM encoded, that verifies the permits of the client.
+func (c *PermitController) RegisterKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	publicKey := r.FormValue("publickey")
+
+	if chainCodeName == "" || channelID == "" || publicKey == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, or publickey", http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to register the public key
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, "RegisterPublicKey", []string{publicKey})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to register public key: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "Public key registered. Transaction ID: %s", transactionID)
+}
+
+// GetPayload handles building the canonical payload an owner signs to permit a spender.
+// The owner signs the SHA-256 hash of the payload exactly as returned, with no added whitespace.
+func (c *PermitController) GetPayload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.URL.Query().Get("chaincodeid")
+	channelID := r.URL.Query().Get("channelid")
+	ownerCN := r.URL.Query().Get("ownerCN")
+	spenderCN := r.URL.Query().Get("spenderCN")
+	value := r.URL.Query().Get("value")
+	deadline := r.URL.Query().Get("deadline")
+
+	if chainCodeName == "" || channelID == "" || ownerCN == "" || spenderCN == "" || value == "" || deadline == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, ownerCN, spenderCN, value, or deadline", http.StatusBadRequest)
+		return
+	}
+
+	owner, err := accountID(ownerCN, r.URL.Query().Get("ownerMSP"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	spender, err := accountID(spenderCN, r.URL.Query().Get("spenderMSP"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// The chaincode builds the payload, so that it carries the current nonce and the names of the deployment
+	args := []string{owner, spender, value, deadline}
+	payload, err := c.Service.EvaluateChaincode(channelID, chainCodeName, "PermitPayload", args)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to build permit payload: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"payload": string(payload),
+	})
+}
+
+// Relay handles submitting a permit signed by its owner, setting the allowance of the spender.
+// signature is the base64 encoded ASN.1 ECDSA signature of the payload returned by GetPayload.
+func (c *PermitController) Relay(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	ownerCN := r.FormValue("ownerCN")
+	spenderCN := r.FormValue("spenderCN")
+	value := r.FormValue("value")
+	deadline := r.FormValue("deadline")
+	signature := r.FormValue("signature")
+
+	if chainCodeName == "" || channelID == "" || ownerCN == "" || spenderCN == "" || value == "" || deadline == "" || signature == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, ownerCN, spenderCN, value, deadline, or signature", http.StatusBadRequest)
+		return
+	}
+
+	owner, err := accountID(ownerCN, r.FormValue("ownerMSP"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	spender, err := accountID(spenderCN, r.FormValue("spenderMSP"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to relay the permit
+	args := []string{owner, spender, value, deadline, signature}
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, "Permit", args)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to relay permit: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "Permit relayed. Transaction ID: %s", transactionID)
+}
@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"rest-api-go/services"
+)
+
+// RecoveryController handles requests of recovery officers forcing transfers of the token chaincode.
+type RecoveryController struct {
+	Service *services.GatewayService
+}
+
+// NewRecoveryController creates a new RecoveryController instance.
+func NewRecoveryController(setup *services.OrgSetup) *RecoveryController {
+	return &RecoveryController{Service: services.NewGatewayService(setup)}
+}
+
+// justification returns the reason and the document hash justifying a forced transfer,
+// failing unless both are given and documenthash is a hex SHA-256 hash.
+func justification(r *http.Request) (string, string, error) {
+	reason := r.FormValue("reason")
+	documentHash := r.FormValue("documenthash")
+
+	if reason == "" || documentHash == "" {
+		return "", "", fmt.Errorf("a justification is required: reason and documenthash")
+	}
+	hash, err := hex.DecodeString(documentHash)
+	if err != nil || len(hash) != 32 {
+		return "", "", fmt.Errorf("documenthash must be the hex SHA-256 hash of the document ordering the transfer")
+	}
+
+	return reason, documentHash, nil
+}
+
+// ForceTransfer handles moving tokens between accounts without the consent of their owner.
+// It refuses to run without a reason and the documenthash of the order, which are recorded on chain.
+func (c *RecoveryController) ForceTransfer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	reason, documentHash, err := justification(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err = c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	fromCN := r.FormValue("fromCN")
+	toCN := r.FormValue("toCN")
+	amount := r.FormValue("amount")
+
+	if chainCodeName == "" || channelID == "" || fromCN == "" || toCN == "" || amount == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, fromCN, toCN, or amount", http.StatusBadRequest)
+		return
+	}
+
+	from, err := accountID(fromCN, r.FormValue("fromMSP"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	to, err := accountID(toCN, r.FormValue("toMSP"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to force the transfer, keeping an audit trail of every attempt
+	args := []string{from, to, amount, reason, documentHash}
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, "ForceTransfer", args)
+	log.Printf("Audit: forced transfer of %s tokens from %s to %s, reason %q, document %s, transaction %q, error %v", amount, fromCN, toCN, reason, documentHash, transactionID, err)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to force transfer: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "Transfer forced. Transaction ID: %s", transactionID)
+}
+
+// Clawback handles recovering tokens of an account into the account of the recovery officer.
+// It refuses to run without a reason and the documenthash of the order, which are recorded on chain.
+func (c *RecoveryController) Clawback(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	reason, documentHash, err := justification(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err = c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	accountCN := r.FormValue("accountCN")
+	amount := r.FormValue("amount")
+
+	if chainCodeName == "" || channelID == "" || accountCN == "" || amount == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, accountCN, or amount", http.StatusBadRequest)
+		return
+	}
+
+	account, err := accountID(accountCN, r.FormValue("accountMSP"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to claw the tokens back, keeping an audit trail of every attempt
+	args := []string{account, amount, reason, documentHash}
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, "Clawback", args)
+	log.Printf("Audit: clawback of %s tokens from %s, reason %q, document %s, transaction %q, error %v", amount, accountCN, reason, documentHash, transactionID, err)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to claw back tokens: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "Tokens clawed back. Transaction ID: %s", transactionID)
+}
+
+// Audit handles listing every forced transfer and clawback with its justification.
+func (c *RecoveryController) Audit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.URL.Query().Get("chaincodeid")
+	channelID := r.URL.Query().Get("channelid")
+
+	if chainCodeName == "" || channelID == "" {
+		http.Error(w, "Missing required fields: chaincodeid or channelid", http.StatusBadRequest)
+		return
+	}
+
+	result, err := c.Service.EvaluateChaincode(channelID, chainCodeName, "GetForcedTransfers", nil)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get forced transfers: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(result)
+}
@@ -0,0 +1,212 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"rest-api-go/services"
+)
+
+// ShareController handles requests for fractional ownership of basic-chaincode assets.
+type ShareController struct {
+	Service *services.GatewayService
+}
+
+// NewShareController creates a new ShareController instance.
+func NewShareController(setup *services.OrgSetup) *ShareController {
+	return &ShareController{Service: services.NewGatewayService(setup)}
+}
+
+// Fractionalize handles locking an asset of the client and issuing share tokens of it.
+func (c *ShareController) Fractionalize(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	assetID := r.FormValue("assetid")
+	totalShares := r.FormValue("shares")
+
+	if chainCodeName == "" || channelID == "" || assetID == "" || totalShares == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, assetid, or shares", http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to fractionalize the asset
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, "FractionalizeAsset", []string{assetID, totalShares})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fractionalize asset: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "Fractionalization successful. Transaction ID: %s", transactionID)
+}
+
+// Transfer handles moving shares of an asset from the client to another account.
+func (c *ShareController) Transfer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	assetID := r.FormValue("assetid")
+	recipientCN := r.FormValue("recipientCN")
+	amount := r.FormValue("amount")
+
+	if chainCodeName == "" || channelID == "" || assetID == "" || recipientCN == "" || amount == "" {
+		http.Error(w, "Missing required form data", http.StatusBadRequest)
+		return
+	}
+
+	recipient, err := accountID(recipientCN, r.FormValue("recipientMSP"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to transfer shares
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, "TransferShares", []string{assetID, recipient, amount})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to transfer shares: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "Transfer successful. Transaction ID: %s", transactionID)
+}
+
+// Redeem handles restoring sole ownership of an asset to a client holding all of its shares.
+func (c *ShareController) Redeem(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	assetID := r.FormValue("assetid")
+
+	if chainCodeName == "" || channelID == "" || assetID == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, or assetid", http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to redeem the asset
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, "RedeemAsset", []string{assetID})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to redeem asset: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "Redemption successful. Transaction ID: %s", transactionID)
+}
+
+// GetBalance handles the request to get the shares of an asset held by an account.
+func (c *ShareController) GetBalance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.URL.Query().Get("chaincodeid")
+	channelID := r.URL.Query().Get("channelid")
+	assetID := r.URL.Query().Get("assetid")
+	holderCN := r.URL.Query().Get("holderCN")
+
+	if chainCodeName == "" || channelID == "" || assetID == "" || holderCN == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, assetid, or holderCN", http.StatusBadRequest)
+		return
+	}
+
+	holder, err := accountID(holderCN, r.URL.Query().Get("holderMSP"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to get the share balance
+	result, err := c.Service.EvaluateChaincode(channelID, chainCodeName, "GetShareBalance", []string{assetID, holder})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get share balance: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with the balance
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"assetid": assetID,
+		"balance": json.RawMessage(result),
+	})
+}
+
+// GetHolders handles the request to get every holder of shares of an asset.
+func (c *ShareController) GetHolders(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.URL.Query().Get("chaincodeid")
+	channelID := r.URL.Query().Get("channelid")
+	assetID := r.URL.Query().Get("assetid")
+
+	if chainCodeName == "" || channelID == "" || assetID == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, or assetid", http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to get the share holders
+	result, err := c.Service.EvaluateChaincode(channelID, chainCodeName, "GetShareHolders", []string{assetID})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get share holders: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with the holders
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"assetid": assetID,
+		"holders": json.RawMessage(result),
+	})
+}
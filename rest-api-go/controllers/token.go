@@ -138,7 +138,7 @@
 // 	})
 // }
 
-// // Transfer handles chaincode invoke requests for transferring an asset.
+// // Transfer handles chaincode invoke requests for transferring tokens, reporting the fee charged on them.
 // func (c *TokenController) Transfer(w http.ResponseWriter, r *http.Request) {
 // 	if r.Method != http.MethodPost {
 // 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -236,7 +236,7 @@ func (c *TokenController) InitializeContract(w http.ResponseWriter, r *http.Requ
 	// Call the service to initialize the contract
 	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, "Initialize", []string{tokenName, symbol, decimals})
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to initialize contract: %v", err), http.StatusInternalServerError)
+		http.Error(w, fmt.Sprintf("Failed to initialize contract: %v", err), chaincodeErrorStatus(err))
 		return
 	}
 
@@ -276,7 +276,7 @@ func (c *TokenController) Mint(w http.ResponseWriter, r *http.Request) {
 	// Call the service to mint tokens
 	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, "Mint", []string{amount})
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to mint tokens: %v", err), http.StatusInternalServerError)
+		http.Error(w, fmt.Sprintf("Failed to mint tokens: %v", err), chaincodeErrorStatus(err))
 		return
 	}
 
@@ -284,7 +284,7 @@ func (c *TokenController) Mint(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "Minting successful. Transaction ID: %s", transactionID)
 }
 
-// Transfer handles chaincode invoke requests for transferring an asset.
+// Transfer handles chaincode invoke requests for transferring tokens, reporting the fee charged on them.
 func (c *TokenController) Transfer(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -314,18 +314,42 @@ func (c *TokenController) Transfer(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Construct recipient identity string
-	recipient := fmt.Sprintf("x509::CN=%s,OU=client,O=Hyperledger,ST=North Carolina,C=US::CN=ca.org1.example.com,O=org1.example.com,L=Durham,ST=North Carolina,C=US", recipientCN)
-	recipient = strings.TrimSpace(recipient)
+	recipient, err := accountID(recipientCN, r.FormValue("recipientMSP"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	// Call the service to transfer tokens
-	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, "Transfer", []string{recipient, amount})
+	result, transactionID, err := c.Service.SubmitChaincode(channelID, chainCodeName, "Transfer", []string{recipient, amount})
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to transfer tokens: %v", err), http.StatusInternalServerError)
+		http.Error(w, fmt.Sprintf("Failed to transfer tokens: %v", err), chaincodeErrorStatus(err))
 		return
 	}
 
-	// Respond with success message
-	fmt.Fprintf(w, "Transfer successful. Transaction ID: %s", transactionID)
+	// The chaincode reports the gross amount sent, the fee routed to the treasury and the net amount received,
+	// or the ID of the pending transfer when the transfer awaits compliance review
+	var transfer struct {
+		Gross   int    `json:"gross"`
+		Fee     int    `json:"fee"`
+		Net     int    `json:"net"`
+		Pending string `json:"pending"`
+	}
+	err = json.Unmarshal(result, &transfer)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to decode transfer result: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// Respond with the amounts of the transfer
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"transaction_id": transactionID,
+		"gross":          transfer.Gross,
+		"fee":            transfer.Fee,
+		"net":            transfer.Net,
+		"pending":        transfer.Pending,
+	})
 }
 
 // GetClientAccountBalance handles the request to get client account balance.
@@ -363,3 +387,49 @@ func (c *TokenController) GetClientAccountBalance(w http.ResponseWriter, r *http
 		"balance": result,
 	})
 }
+
+// errorCodeStatuses maps the codes prefixing chaincode error messages to the HTTP status reporting them.
+var errorCodeStatuses = map[string]int{
+	"UNAUTHORIZED":            http.StatusForbidden,
+	"INVALID_ARGUMENT":        http.StatusBadRequest,
+	"NOT_FOUND":               http.StatusNotFound,
+	"INSUFFICIENT_FUNDS":      http.StatusConflict,
+	"TRANSFER_LIMIT_EXCEEDED": http.StatusUnprocessableEntity,
+}
+
+// chaincodeErrorStatus returns the HTTP status reporting a failed chaincode call:
+// the status of the code of the chaincode error, 500 when it carries none.
+func chaincodeErrorStatus(err error) int {
+	for code, status := range errorCodeStatuses {
+		if services.HasErrorCode(err, code) {
+			return status
+		}
+	}
+
+	return http.StatusInternalServerError
+}
+
+// accountIssuers maps the MSP ID of each organization to the distinguished name of the CA issuing the certificates of its clients.
+var accountIssuers = map[string]string{
+	"Org1MSP": "CN=ca.org1.example.com,O=org1.example.com,L=Durham,ST=North Carolina,C=US",
+	"Org2MSP": "CN=ca.org2.example.com,O=org2.example.com,L=Hursley,ST=Hampshire,C=UK",
+}
+
+// defaultAccountMSP is the organization of clients named by common name alone.
+const defaultAccountMSP = "Org1MSP"
+
+// accountID builds the x509 identity string of a client of organization msp from its common name.
+// An empty msp names a client of defaultAccountMSP.
+func accountID(cn, msp string) (string, error) {
+	if msp == "" {
+		msp = defaultAccountMSP
+	}
+
+	issuer, ok := accountIssuers[msp]
+	if !ok {
+		return "", fmt.Errorf("unknown MSP %s of account %s", msp, cn)
+	}
+
+	id := fmt.Sprintf("x509::CN=%s,OU=client,O=Hyperledger,ST=North Carolina,C=US::%s", cn, issuer)
+	return strings.TrimSpace(id), nil
+}
@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"rest-api-go/services"
+	"strconv"
+)
+
+// UserController handles requests for the users of basic-chaincode, whose personal details
+// are sent as transient data and stored in a private data collection.
+type UserController struct {
+	Service *services.GatewayService
+}
+
+// NewUserController creates a new UserController instance.
+func NewUserController(setup *services.OrgSetup) *UserController {
+	return &UserController{Service: services.NewGatewayService(setup)}
+}
+
+// userDetails are the personal details of a user sent to the chaincode in the transient map.
+type userDetails struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+	Sex  string `json:"sex"`
+	Salt string `json:"salt"`
+}
+
+// CreateUser handles registering a user with its personal details.
+func (c *UserController) CreateUser(w http.ResponseWriter, r *http.Request) {
+	c.saveUser(w, r, "CreateUser", "create user", "Creation successful")
+}
+
+// UpdateUser handles replacing the personal details of a user. Only clients of the admin organization may update users.
+func (c *UserController) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	c.saveUser(w, r, "UpdateUser", "update user", "Update successful")
+}
+
+// saveUser submits the personal details of a user from the form as transient data,
+// salted with a fresh random salt so that their public hash cannot be guessed.
+func (c *UserController) saveUser(w http.ResponseWriter, r *http.Request, functionChaincode, action, success string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	id := r.FormValue("id")
+	name := r.FormValue("name")
+	age := r.FormValue("age")
+	sex := r.FormValue("sex")
+
+	if chainCodeName == "" || channelID == "" || id == "" || name == "" || age == "" || sex == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, id, name, age, or sex", http.StatusBadRequest)
+		return
+	}
+
+	ageValue, err := strconv.Atoi(age)
+	if err != nil || ageValue < 0 {
+		http.Error(w, "age must be a non-negative integer", http.StatusBadRequest)
+		return
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to generate salt: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	details, err := json.Marshal(userDetails{Name: name, Age: ageValue, Sex: sex, Salt: hex.EncodeToString(salt)})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to encode user details: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// Call the service with the details as transient data
+	transactionID, err := c.Service.CallChaincodeWithTransient(channelID, chainCodeName, functionChaincode, []string{id}, map[string][]byte{"user": details})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to %s: %v", action, err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "%s. Transaction ID: %s", success, transactionID)
+}
+
+// DeleteUser handles removing a user and its personal details. Only clients of the admin organization may delete users.
+func (c *UserController) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	c.submitUser(w, r, "DeleteUser", "delete user", "Deletion successful")
+}
+
+// PurgeUser handles erasing the personal details of a user from the private data of the peers.
+func (c *UserController) PurgeUser(w http.ResponseWriter, r *http.Request) {
+	c.submitUser(w, r, "PurgeUserDetails", "purge user details", "Purge successful")
+}
+
+// submitUser submits a chaincode function taking only the id of a user.
+func (c *UserController) submitUser(w http.ResponseWriter, r *http.Request, functionChaincode, action, success string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	id := r.FormValue("id")
+
+	if chainCodeName == "" || channelID == "" || id == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, or id", http.StatusBadRequest)
+		return
+	}
+
+	// Call the service
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, functionChaincode, []string{id})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to %s: %v", action, err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "%s. Transaction ID: %s", success, transactionID)
+}
+
+// GetUserDetails handles the request to get the personal details of a user.
+// Only clients of organizations in the private data collection can read them.
+func (c *UserController) GetUserDetails(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.URL.Query().Get("chaincodeid")
+	channelID := r.URL.Query().Get("channelid")
+	id := r.URL.Query().Get("id")
+
+	if chainCodeName == "" || channelID == "" || id == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, or id", http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to get the details
+	result, err := c.Service.EvaluateChaincode(channelID, chainCodeName, "ReadUserDetails", []string{id})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get user details: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with the details
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"user": json.RawMessage(result),
+	})
+}
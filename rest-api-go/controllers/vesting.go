@@ -0,0 +1,167 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"rest-api-go/services"
+	"strconv"
+)
+
+// VestingController handles requests for the vesting schedules of the token chaincode.
+type VestingController struct {
+	Service *services.GatewayService
+}
+
+// NewVestingController creates a new VestingController instance.
+func NewVestingController(setup *services.OrgSetup) *VestingController {
+	return &VestingController{Service: services.NewGatewayService(setup)}
+}
+
+// CreateSchedule handles locking tokens of the client in a vesting schedule for a beneficiary.
+// start is in seconds since the Unix epoch, cliff and duration in seconds.
+func (c *VestingController) CreateSchedule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	id := r.FormValue("id")
+	beneficiaryCN := r.FormValue("beneficiaryCN")
+	total := r.FormValue("total")
+	start := r.FormValue("start")
+	cliff := r.FormValue("cliff")
+	duration := r.FormValue("duration")
+	revocable := r.FormValue("revocable")
+
+	if chainCodeName == "" || channelID == "" || id == "" || beneficiaryCN == "" || total == "" || start == "" || cliff == "" || duration == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, id, beneficiaryCN, total, start, cliff, or duration", http.StatusBadRequest)
+		return
+	}
+	if revocable == "" {
+		revocable = "false"
+	}
+	if _, err := strconv.ParseBool(revocable); err != nil {
+		http.Error(w, "revocable must be true or false", http.StatusBadRequest)
+		return
+	}
+
+	beneficiary, err := accountID(beneficiaryCN, r.FormValue("beneficiaryMSP"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Call the service to create the vesting schedule
+	args := []string{id, beneficiary, total, start, cliff, duration, revocable}
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, "CreateVestingSchedule", args)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to create vesting schedule: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "Vesting schedule created. Transaction ID: %s", transactionID)
+}
+
+// GetSchedules handles listing the vesting schedules of a beneficiary, the client by default.
+func (c *VestingController) GetSchedules(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.URL.Query().Get("chaincodeid")
+	channelID := r.URL.Query().Get("channelid")
+	beneficiaryCN := r.URL.Query().Get("beneficiaryCN")
+
+	if chainCodeName == "" || channelID == "" {
+		http.Error(w, "Missing required fields: chaincodeid or channelid", http.StatusBadRequest)
+		return
+	}
+
+	var beneficiary string
+	if beneficiaryCN != "" {
+		beneficiary, err = accountID(beneficiaryCN, r.URL.Query().Get("beneficiaryMSP"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	} else {
+		clientID, err := c.Service.EvaluateChaincode(channelID, chainCodeName, "ClientAccountID", nil)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to get client account ID: %v", err), chaincodeErrorStatus(err))
+			return
+		}
+		beneficiary = string(clientID)
+	}
+
+	// Call the service to get the vesting schedules
+	result, err := c.Service.EvaluateChaincode(channelID, chainCodeName, "GetVestingSchedules", []string{beneficiary})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get vesting schedules: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with the schedules as returned by the chaincode
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(result)
+}
+
+// Release handles paying the vested tokens of a schedule to its beneficiary.
+func (c *VestingController) Release(w http.ResponseWriter, r *http.Request) {
+	c.scheduleTransaction(w, r, "Release", "release vested tokens", "Release successful")
+}
+
+// Revoke handles revoking a vesting schedule, refunding its unvested tokens to the funder.
+func (c *VestingController) Revoke(w http.ResponseWriter, r *http.Request) {
+	c.scheduleTransaction(w, r, "RevokeVestingSchedule", "revoke vesting schedule", "Revocation successful")
+}
+
+// scheduleTransaction submits a chaincode function taking the ID of a vesting schedule as its only argument.
+func (c *VestingController) scheduleTransaction(w http.ResponseWriter, r *http.Request, function string, action string, success string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	id := r.FormValue("id")
+
+	if chainCodeName == "" || channelID == "" || id == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, or id", http.StatusBadRequest)
+		return
+	}
+
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, function, []string{id})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to %s: %v", action, err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "%s. Transaction ID: %s", success, transactionID)
+}
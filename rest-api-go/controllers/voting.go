@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"rest-api-go/services"
+	"strings"
+)
+
+// VotingController handles requests for the token holder proposals of the token chaincode.
+type VotingController struct {
+	Service *services.GatewayService
+}
+
+// NewVotingController creates a new VotingController instance.
+func NewVotingController(setup *services.OrgSetup) *VotingController {
+	return &VotingController{Service: services.NewGatewayService(setup)}
+}
+
+// submit handles a POST request submitting function with the values of the form fields as its arguments,
+// all of them required.
+func (c *VotingController) submit(w http.ResponseWriter, r *http.Request, function string, action string, success string, fields ...string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+
+	args := []string{}
+	missing := chainCodeName == "" || channelID == ""
+	for _, field := range fields {
+		value := r.FormValue(field)
+		missing = missing || value == ""
+		args = append(args, value)
+	}
+	if missing {
+		http.Error(w, fmt.Sprintf("Missing required fields: chaincodeid, channelid, or %s", strings.Join(fields, ", ")), http.StatusBadRequest)
+		return
+	}
+
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, function, args)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to %s: %v", action, err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "%s. Transaction ID: %s", success, transactionID)
+}
+
+// Enable handles setting the rules of proposals: quorum and majority in basis points and period in seconds.
+func (c *VotingController) Enable(w http.ResponseWriter, r *http.Request) {
+	c.submit(w, r, "EnableVoting", "enable voting", "Voting enabled", "quorum", "majority", "period")
+}
+
+// CreateProposal handles a token holder putting a proposal to the vote.
+func (c *VotingController) CreateProposal(w http.ResponseWriter, r *http.Request) {
+	c.submit(w, r, "CreateProposal", "create proposal", "Proposal created", "id", "description")
+}
+
+// Vote handles voting on a proposal. support is "for", "against" or "abstain".
+func (c *VotingController) Vote(w http.ResponseWriter, r *http.Request) {
+	c.submit(w, r, "CastVote", "cast vote", "Vote cast", "id", "support")
+}
+
+// Tally handles counting the votes of a proposal whose voting period has ended.
+func (c *VotingController) Tally(w http.ResponseWriter, r *http.Request) {
+	c.submit(w, r, "TallyProposal", "tally proposal", "Proposal tallied", "id")
+}
+
+// Delegate handles giving the voting power of the client to the account of delegateeCN.
+func (c *VotingController) Delegate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.FormValue("chaincodeid")
+	channelID := r.FormValue("channelid")
+	delegateeCN := r.FormValue("delegateeCN")
+
+	if chainCodeName == "" || channelID == "" || delegateeCN == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, or delegateeCN", http.StatusBadRequest)
+		return
+	}
+
+	delegatee, err := accountID(delegateeCN, r.FormValue("delegateeMSP"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	transactionID, err := c.Service.CallChaincode(channelID, chainCodeName, "Delegate", []string{delegatee})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to delegate: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	// Respond with success message
+	fmt.Fprintf(w, "Voting power delegated. Transaction ID: %s", transactionID)
+}
+
+// GetProposal handles reading a proposal with its votes, counted so far while it is active.
+func (c *VotingController) GetProposal(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Initialize the service with the cert and key from the request
+	err := c.Service.InitializeWithCertAndKey(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to initialize with certificate and key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chainCodeName := r.URL.Query().Get("chaincodeid")
+	channelID := r.URL.Query().Get("channelid")
+	id := r.URL.Query().Get("id")
+
+	if chainCodeName == "" || channelID == "" || id == "" {
+		http.Error(w, "Missing required fields: chaincodeid, channelid, or id", http.StatusBadRequest)
+		return
+	}
+
+	result, err := c.Service.EvaluateChaincode(channelID, chainCodeName, "ReadProposal", []string{id})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to read proposal: %v", err), chaincodeErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(result)
+}
@@ -52,12 +52,124 @@ func main() {
 	orgConfig := &services.OrgSetup{}
 
 	tokenController := controllers.NewTokenController(orgConfig)
+	vestingController := controllers.NewVestingController(orgConfig)
+	holdController := controllers.NewHoldController(orgConfig)
+	permitController := controllers.NewPermitController(orgConfig)
+	feeController := controllers.NewFeeController(orgConfig)
+	governanceController := controllers.NewGovernanceController(orgConfig)
+	balanceModelController := controllers.NewBalanceModelController(orgConfig)
+	holderController := controllers.NewHolderController(orgConfig)
+	limitController := controllers.NewLimitController(orgConfig)
+	complianceController := controllers.NewComplianceController(orgConfig)
+	recoveryController := controllers.NewRecoveryController(orgConfig)
+	votingController := controllers.NewVotingController(orgConfig)
+	multiTokenController := controllers.NewMultiTokenController(orgConfig)
+	marketplaceController := controllers.NewMarketplaceController(orgConfig)
+	shareController := controllers.NewShareController(orgConfig)
+	assetController := controllers.NewAssetController(orgConfig)
+	offerController := controllers.NewOfferController(orgConfig)
+	userController := controllers.NewUserController(orgConfig)
 
 	http.HandleFunc("/transfer", tokenController.Transfer)
 	http.HandleFunc("/balance", tokenController.GetClientAccountBalance)
 	http.HandleFunc("/invoke", tokenController.InitializeContract)
 	http.HandleFunc("/mint", tokenController.Mint)
 
+	http.HandleFunc("/vesting/create", vestingController.CreateSchedule)
+	http.HandleFunc("/vesting/schedules", vestingController.GetSchedules)
+	http.HandleFunc("/vesting/release", vestingController.Release)
+	http.HandleFunc("/vesting/revoke", vestingController.Revoke)
+
+	http.HandleFunc("/holds/place", holdController.Place)
+	http.HandleFunc("/holds/execute", holdController.Execute)
+	http.HandleFunc("/holds/release", holdController.Release)
+	http.HandleFunc("/holds/hold", holdController.GetHold)
+	http.HandleFunc("/holds/balance", holdController.GetBalance)
+
+	http.HandleFunc("/permit/register-key", permitController.RegisterKey)
+	http.HandleFunc("/permit/payload", permitController.GetPayload)
+	http.HandleFunc("/permit", permitController.Relay)
+
+	http.HandleFunc("/fees/config", feeController.Config)
+	http.HandleFunc("/fees/exemption", feeController.SetExemption)
+
+	http.HandleFunc("/governance/initialize", governanceController.Initialize)
+	http.HandleFunc("/governance/propose-mint", governanceController.ProposeMint)
+	http.HandleFunc("/governance/propose-role", governanceController.ProposeRoleChange)
+	http.HandleFunc("/governance/approve", governanceController.Approve)
+	http.HandleFunc("/governance/reject", governanceController.Reject)
+	http.HandleFunc("/governance/pending", governanceController.GetPending)
+
+	http.HandleFunc("/balance-model", balanceModelController.Model)
+	http.HandleFunc("/consolidate", balanceModelController.Consolidate)
+
+	http.HandleFunc("/holders", holderController.List)
+	http.HandleFunc("/holders/top", holderController.Top)
+	http.HandleFunc("/holders/concentration", holderController.Concentration)
+
+	http.HandleFunc("/limits", limitController.Account)
+	http.HandleFunc("/limits/default", limitController.SetDefault)
+
+	http.HandleFunc("/compliance/config", complianceController.Config)
+	http.HandleFunc("/compliance/pending", complianceController.GetPending)
+	http.HandleFunc("/compliance/approve", complianceController.Approve)
+	http.HandleFunc("/compliance/reject", complianceController.Reject)
+	http.HandleFunc("/compliance/refund", complianceController.Refund)
+	http.HandleFunc("/compliance/events", complianceController.Events)
+
+	http.HandleFunc("/recovery/force-transfer", recoveryController.ForceTransfer)
+	http.HandleFunc("/recovery/clawback", recoveryController.Clawback)
+	http.HandleFunc("/recovery/audit", recoveryController.Audit)
+
+	http.HandleFunc("/voting/enable", votingController.Enable)
+	http.HandleFunc("/voting/proposals", votingController.CreateProposal)
+	http.HandleFunc("/voting/proposal", votingController.GetProposal)
+	http.HandleFunc("/voting/delegate", votingController.Delegate)
+	http.HandleFunc("/voting/vote", votingController.Vote)
+	http.HandleFunc("/voting/tally", votingController.Tally)
+
+	http.HandleFunc("/multitoken/mint", multiTokenController.Mint)
+	http.HandleFunc("/multitoken/mint-batch", multiTokenController.MintBatch)
+	http.HandleFunc("/multitoken/balance", multiTokenController.GetBalance)
+	http.HandleFunc("/multitoken/balance-batch", multiTokenController.GetBalanceBatch)
+	http.HandleFunc("/multitoken/transfer", multiTokenController.Transfer)
+	http.HandleFunc("/multitoken/transfer-batch", multiTokenController.TransferBatch)
+	http.HandleFunc("/multitoken/approval", multiTokenController.SetApprovalForAll)
+	http.HandleFunc("/multitoken/uri", multiTokenController.URI)
+
+	http.HandleFunc("/marketplace/list", marketplaceController.ListAssetForSale)
+	http.HandleFunc("/marketplace/cancel", marketplaceController.CancelListing)
+	http.HandleFunc("/marketplace/buy", marketplaceController.BuyAsset)
+	http.HandleFunc("/marketplace/listings", marketplaceController.GetListings)
+
+	http.HandleFunc("/shares/fractionalize", shareController.Fractionalize)
+	http.HandleFunc("/shares/transfer", shareController.Transfer)
+	http.HandleFunc("/shares/redeem", shareController.Redeem)
+	http.HandleFunc("/shares/balance", shareController.GetBalance)
+	http.HandleFunc("/shares/holders", shareController.GetHolders)
+
+	http.HandleFunc("/assets", assetController.GetAssets)
+	http.HandleFunc("/users", assetController.GetUsers)
+	http.HandleFunc("/assets/history", assetController.GetAssetHistory)
+	http.HandleFunc("/users/history", assetController.GetUserHistory)
+	http.HandleFunc("/assets/create", assetController.CreateAsset)
+	http.HandleFunc("/assets/update", assetController.UpdateAsset)
+	http.HandleFunc("/assets/delete", assetController.DeleteAsset)
+	http.HandleFunc("/assets/transfer", assetController.TransferAsset)
+
+	http.HandleFunc("/offers/create", offerController.Create)
+	http.HandleFunc("/offers/accept", offerController.Accept)
+	http.HandleFunc("/offers/reject", offerController.Reject)
+	http.HandleFunc("/offers/cancel", offerController.Cancel)
+	http.HandleFunc("/offers/inbound", offerController.GetInbound)
+	http.HandleFunc("/offers/outbound", offerController.GetOutbound)
+
+	http.HandleFunc("/users/create", userController.CreateUser)
+	http.HandleFunc("/users/update", userController.UpdateUser)
+	http.HandleFunc("/users/delete", userController.DeleteUser)
+	http.HandleFunc("/users/purge", userController.PurgeUser)
+	http.HandleFunc("/users/details", userController.GetUserDetails)
+
 	log.Println("Starting server on port 8080")
 	if err := http.ListenAndServe(":8080", nil); err != nil {
 		log.Fatalf("Server failed: %v", err)
@@ -1,14 +1,18 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 	"path/filepath"
 	"rest-api-go/utils"
 	"strconv"
+	"strings"
 
 	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"google.golang.org/grpc/status"
 )
 
 // GatewayService provides access to the blockchain network and contracts.
@@ -34,6 +38,12 @@ func (g *GatewayService) GetContract(chainCodeName string) *client.Contract {
 
 // CallChaincode calls a chaincode function with specified arguments.
 func (g *GatewayService) CallChaincode(channelID, chainCodeName, functionChaincode string, args []string) (string, error) {
+	return g.CallChaincodeWithTransient(channelID, chainCodeName, functionChaincode, args, nil)
+}
+
+// CallChaincodeWithTransient calls a chaincode function with specified arguments and transient data.
+// Transient data reaches the chaincode without being recorded in the transaction, e.g. private data.
+func (g *GatewayService) CallChaincodeWithTransient(channelID, chainCodeName, functionChaincode string, args []string, transient map[string][]byte) (string, error) {
 	// Retrieve the network and contract
 	network := g.GetNetwork(channelID)
 	if network == nil {
@@ -45,7 +55,7 @@ func (g *GatewayService) CallChaincode(channelID, chainCodeName, functionChainco
 	}
 
 	// Call the specified function on the chaincode
-	txn_proposal, err := contract.NewProposal(functionChaincode, client.WithArguments(args...))
+	txn_proposal, err := contract.NewProposal(functionChaincode, client.WithArguments(args...), client.WithTransient(transient))
 	if err != nil {
 		return "", fmt.Errorf("error creating transaction proposal: %v", err)
 	}
@@ -53,18 +63,60 @@ func (g *GatewayService) CallChaincode(channelID, chainCodeName, functionChainco
 	// Endorse the transaction proposal
 	txn_endorsed, err := txn_proposal.Endorse()
 	if err != nil {
-		return "", fmt.Errorf("error endorsing transaction: %v", err)
+		return "", fmt.Errorf("error endorsing transaction: %w", err)
 	}
 
 	// Submit the endorsed transaction
 	txn_committed, err := txn_endorsed.Submit()
 	if err != nil {
-		return "", fmt.Errorf("error submitting transaction: %v", err)
+		return "", fmt.Errorf("error submitting transaction: %w", err)
 	}
 
 	return txn_committed.TransactionID(), nil
 }
 
+// SubmitChaincode submits a chaincode function with specified arguments and returns
+// the result of the function together with the transaction ID once it is committed.
+func (g *GatewayService) SubmitChaincode(channelID, chainCodeName, functionChaincode string, args []string) ([]byte, string, error) {
+	// Retrieve the network and contract
+	network := g.GetNetwork(channelID)
+	if network == nil {
+		return nil, "", fmt.Errorf("network %s does not exist", channelID)
+	}
+	contract := network.GetContract(chainCodeName)
+	if contract == nil {
+		return nil, "", fmt.Errorf("contract %s does not exist", chainCodeName)
+	}
+
+	// Call the specified function on the chaincode
+	txn_proposal, err := contract.NewProposal(functionChaincode, client.WithArguments(args...))
+	if err != nil {
+		return nil, "", fmt.Errorf("error creating transaction proposal: %v", err)
+	}
+
+	// Endorse the transaction proposal
+	txn_endorsed, err := txn_proposal.Endorse()
+	if err != nil {
+		return nil, "", fmt.Errorf("error endorsing transaction: %w", err)
+	}
+
+	// Submit the endorsed transaction and wait for it to be committed
+	txn_committed, err := txn_endorsed.Submit()
+	if err != nil {
+		return nil, "", fmt.Errorf("error submitting transaction: %w", err)
+	}
+
+	txn_status, err := txn_committed.Status()
+	if err != nil {
+		return nil, "", fmt.Errorf("error getting transaction status: %w", err)
+	}
+	if !txn_status.Successful {
+		return nil, "", fmt.Errorf("transaction %s failed to commit with status code %d", txn_status.TransactionID, int32(txn_status.Code))
+	}
+
+	return txn_endorsed.Result(), txn_committed.TransactionID(), nil
+}
+
 // CallChaincodeGET queries the chaincode and returns the result as an integer.
 func (g *GatewayService) CallChaincodeGET(channelID, chainCodeName, functionChaincode string) (int, error) {
 	// Retrieve the network and contract
@@ -92,6 +144,65 @@ func (g *GatewayService) CallChaincodeGET(channelID, chainCodeName, functionChai
 	return balance, nil
 }
 
+// EvaluateChaincode queries a chaincode function with specified arguments and returns the raw result.
+func (g *GatewayService) EvaluateChaincode(channelID, chainCodeName, functionChaincode string, args []string) ([]byte, error) {
+	// Retrieve the network and contract
+	network := g.GetNetwork(channelID)
+	if network == nil {
+		return nil, fmt.Errorf("network %s does not exist", channelID)
+	}
+	contract := network.GetContract(chainCodeName)
+	if contract == nil {
+		return nil, fmt.Errorf("contract %s does not exist", chainCodeName)
+	}
+
+	// Evaluate the transaction
+	result, err := contract.EvaluateTransaction(functionChaincode, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate transaction: %w", err)
+	}
+
+	return result, nil
+}
+
+// ChaincodeEvents returns the events emitted by a chaincode from now on, until ctx is done.
+func (g *GatewayService) ChaincodeEvents(ctx context.Context, channelID, chainCodeName string) (<-chan *client.ChaincodeEvent, error) {
+	// Retrieve the network
+	network := g.GetNetwork(channelID)
+	if network == nil {
+		return nil, fmt.Errorf("network %s does not exist", channelID)
+	}
+
+	events, err := network.ChaincodeEvents(ctx, chainCodeName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for chaincode events: %w", err)
+	}
+
+	return events, nil
+}
+
+// HasErrorCode reports whether err was caused by chaincode failing with an error coded code,
+// i.e. whose message contains e.g. "NOT_FOUND: ". The chaincode message is carried either in the
+// error itself or in the details of the gRPC status returned by the gateway.
+func HasErrorCode(err error, code string) bool {
+	if strings.Contains(err.Error(), code+": ") {
+		return true
+	}
+
+	grpcStatus, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+	for _, detail := range grpcStatus.Details() {
+		errorDetail, ok := detail.(*gateway.ErrorDetail)
+		if ok && strings.Contains(errorDetail.Message, code+": ") {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (s *GatewayService) InitializeWithCertAndKey(r *http.Request) error {
 	// Extract certificate and key from the request
 	certPEM, keyPEM, err := utils.GetCertificateAndPrivateKeyFromForm(r)
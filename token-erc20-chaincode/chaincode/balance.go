@@ -0,0 +1,220 @@
+package chaincode
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// Keys of the token metadata. In the account model balances are stored under the account ID itself and
+// every other record under a composite key, so simple keys are only metadata and balances.
+const (
+	nameKey        = "name"
+	symbolKey      = "symbol"
+	decimalsKey    = "decimals"
+	totalSupplyKey = "totalSupply"
+)
+
+// allowancePrefix is the object type of the ("allowance", owner, spender) keys
+const allowancePrefix = "allowance"
+
+// readInt returns the integer stored at key, zero if the key does not exist
+func readInt(ctx contractapi.TransactionContextInterface, key string) (int, error) {
+	valueBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if valueBytes == nil {
+		return 0, nil
+	}
+
+	value, err := strconv.Atoi(string(valueBytes))
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse value at key %s: %v", key, err)
+	}
+
+	return value, nil
+}
+
+// writeInt stores value at key, removing the key when value drops to zero
+func writeInt(ctx contractapi.TransactionContextInterface, key string, value int) error {
+	if value == 0 {
+		return ctx.GetStub().DelState(key)
+	}
+
+	err := ctx.GetStub().PutState(key, []byte(strconv.Itoa(value)))
+	if err != nil {
+		return fmt.Errorf("failed to put value at key %s into world state: %v", key, err)
+	}
+
+	return nil
+}
+
+// add returns a+b, failing instead of overflowing
+func add(a int, b int) (int, error) {
+	if b > 0 && a > math.MaxInt-b {
+		return 0, codedError(invalidArgumentCode, "addition of %d and %d overflows", a, b)
+	}
+
+	return a + b, nil
+}
+
+// checkAmount returns an error unless amount is a positive number of tokens
+func checkAmount(amount int) error {
+	if amount <= 0 {
+		return codedError(invalidArgumentCode, "amount must be a positive integer")
+	}
+
+	return nil
+}
+
+// readBalance returns the balance of account, zero if it holds no tokens
+func readBalance(ctx contractapi.TransactionContextInterface, account string) (int, error) {
+	utxo, err := isUTXOModel(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if utxo {
+		_, balance, err := readOutputs(ctx, account)
+		return balance, err
+	}
+
+	return readInt(ctx, account)
+}
+
+// credit adds amount tokens to the balance of account
+func credit(ctx contractapi.TransactionContextInterface, account string, amount int) error {
+	err := trackPower(ctx, account, amount)
+	if err != nil {
+		return err
+	}
+
+	utxo, err := isUTXOModel(ctx)
+	if err != nil {
+		return err
+	}
+	if utxo {
+		err = writeOutput(ctx, account, amount)
+		if err != nil {
+			return err
+		}
+		return indexHolder(ctx, account, true)
+	}
+
+	balance, err := readBalance(ctx, account)
+	if err != nil {
+		return err
+	}
+	if balance == 0 {
+		err = indexHolder(ctx, account, true)
+		if err != nil {
+			return err
+		}
+	}
+
+	balance, err = add(balance, amount)
+	if err != nil {
+		return err
+	}
+
+	return writeInt(ctx, account, balance)
+}
+
+// debit removes amount tokens from the available balance of account, the tokens not reserved by holds
+func debit(ctx contractapi.TransactionContextInterface, account string, amount int) error {
+	balance, err := readBalance(ctx, account)
+	if err != nil {
+		return err
+	}
+	held, err := readHeld(ctx, account)
+	if err != nil {
+		return err
+	}
+	if balance-held < amount {
+		return codedError(insufficientFundsCode, "account %s has insufficient funds", account)
+	}
+
+	return withdraw(ctx, account, amount)
+}
+
+// withdraw removes amount tokens from the balance of account, including tokens reserved by holds
+func withdraw(ctx contractapi.TransactionContextInterface, account string, amount int) error {
+	err := trackPower(ctx, account, -amount)
+	if err != nil {
+		return err
+	}
+
+	utxo, err := isUTXOModel(ctx)
+	if err != nil {
+		return err
+	}
+	if utxo {
+		return spendOutputs(ctx, account, amount)
+	}
+
+	balance, err := readInt(ctx, account)
+	if err != nil {
+		return err
+	}
+	if balance < amount {
+		return codedError(insufficientFundsCode, "account %s has insufficient funds", account)
+	}
+	if balance == amount {
+		err = indexHolder(ctx, account, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	return writeInt(ctx, account, balance-amount)
+}
+
+// move transfers amount tokens from one account to another.
+// Fabric does not let a transaction read its own writes, so from and to must differ.
+func move(ctx contractapi.TransactionContextInterface, from string, to string, amount int) error {
+	if from == to {
+		return codedError(invalidArgumentCode, "cannot transfer to and from the same account")
+	}
+	if err := checkAmount(amount); err != nil {
+		return err
+	}
+
+	err := debit(ctx, from, amount)
+	if err != nil {
+		return err
+	}
+
+	return credit(ctx, to, amount)
+}
+
+// allowanceKey builds the key of the allowance of spender over the tokens of owner
+func allowanceKey(ctx contractapi.TransactionContextInterface, owner string, spender string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(allowancePrefix, []string{owner, spender})
+	if err != nil {
+		return "", fmt.Errorf("failed to create allowance key: %v", err)
+	}
+
+	return key, nil
+}
+
+// readAllowance returns the amount spender may still transfer from owner
+func readAllowance(ctx contractapi.TransactionContextInterface, owner string, spender string) (int, error) {
+	key, err := allowanceKey(ctx, owner, spender)
+	if err != nil {
+		return 0, err
+	}
+
+	return readInt(ctx, key)
+}
+
+// writeAllowance sets the amount spender may transfer from owner
+func writeAllowance(ctx contractapi.TransactionContextInterface, owner string, spender string, value int) error {
+	key, err := allowanceKey(ctx, owner, spender)
+	if err != nil {
+		return err
+	}
+
+	return writeInt(ctx, key, value)
+}
@@ -0,0 +1,431 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+const (
+	complianceConfigPrefix = "complianceconfig"
+	pendingTransferPrefix  = "pendingtransfer"
+)
+
+// Statuses of a pending transfer
+const (
+	transferPending  = "pending"
+	transferApproved = "approved"
+	transferRejected = "rejected"
+	transferRefunded = "refunded"
+)
+
+// ComplianceConfig sends transfers of more than Threshold tokens to compliance review.
+// A reviewer has ReviewPeriod seconds to approve or reject such a transfer before it can be refunded.
+// A zero Threshold reviews no transfer.
+type ComplianceConfig struct {
+	Threshold    int   `json:"threshold"`
+	ReviewPeriod int64 `json:"review_period"`
+}
+
+// PendingTransfer is a transfer over the compliance threshold. Its Amount stays reserved in the
+// balance of From, like a hold, until a holder of the compliance role approves it, which settles it
+// as a transfer with fee, or rejects it, or it expires at Expiration and is refunded.
+type PendingTransfer struct {
+	ID         string `json:"id"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+	Amount     int    `json:"amount"`
+	Fee        int    `json:"fee"`
+	Expiration int64  `json:"expiration"`
+	Status     string `json:"status"`
+	Reviewer   string `json:"reviewer,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// complianceConfigKey builds the key of the compliance configuration
+func complianceConfigKey(ctx contractapi.TransactionContextInterface) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(complianceConfigPrefix, []string{})
+	if err != nil {
+		return "", fmt.Errorf("failed to create compliance config key: %v", err)
+	}
+
+	return key, nil
+}
+
+// readComplianceConfig returns the compliance configuration, a zero configuration reviewing nothing if none was set
+func readComplianceConfig(ctx contractapi.TransactionContextInterface) (*ComplianceConfig, error) {
+	key, err := complianceConfigKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	configJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+
+	config := &ComplianceConfig{}
+	if configJSON == nil {
+		return config, nil
+	}
+
+	err = json.Unmarshal(configJSON, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal compliance config: %v", err)
+	}
+
+	return config, nil
+}
+
+// needsReview reports whether a transfer of amount tokens goes to compliance review
+func needsReview(ctx contractapi.TransactionContextInterface, amount int) (bool, error) {
+	config, err := readComplianceConfig(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return config.Threshold > 0 && amount > config.Threshold, nil
+}
+
+// pendingTransferKey builds the key of the pending transfer with the given ID
+func pendingTransferKey(ctx contractapi.TransactionContextInterface, id string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(pendingTransferPrefix, []string{id})
+	if err != nil {
+		return "", fmt.Errorf("failed to create pending transfer key: %v", err)
+	}
+
+	return key, nil
+}
+
+// readPendingTransfer returns the pending transfer with the given ID
+func readPendingTransfer(ctx contractapi.TransactionContextInterface, id string) (*PendingTransfer, error) {
+	key, err := pendingTransferKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	transferJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if transferJSON == nil {
+		return nil, codedError(notFoundCode, "pending transfer %s does not exist", id)
+	}
+
+	var transfer PendingTransfer
+	err = json.Unmarshal(transferJSON, &transfer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal pending transfer %s: %v", id, err)
+	}
+
+	return &transfer, nil
+}
+
+// putPendingTransfer writes transfer to the world state
+func putPendingTransfer(ctx contractapi.TransactionContextInterface, transfer *PendingTransfer) error {
+	key, err := pendingTransferKey(ctx, transfer.ID)
+	if err != nil {
+		return err
+	}
+
+	transferJSON, err := json.Marshal(transfer)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	err = ctx.GetStub().PutState(key, transferJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put pending transfer %s into world state: %v", transfer.ID, err)
+	}
+
+	return nil
+}
+
+// escrowTransfer reserves amount tokens of from for a transfer to to awaiting compliance review,
+// identified by the ID of the transaction
+func escrowTransfer(ctx contractapi.TransactionContextInterface, from string, to string, amount int) (*TransferResult, error) {
+	config, err := readComplianceConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now, err := txTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := readBalance(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	held, err := readHeld(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	if balance-held < amount {
+		return nil, codedError(insufficientFundsCode, "account %s has insufficient funds", from)
+	}
+
+	err = writeHeld(ctx, from, held+amount)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer := &PendingTransfer{
+		ID:         ctx.GetStub().GetTxID(),
+		From:       from,
+		To:         to,
+		Amount:     amount,
+		Expiration: now + config.ReviewPeriod,
+		Status:     transferPending,
+	}
+	err = putPendingTransfer(ctx, transfer)
+	if err != nil {
+		return nil, err
+	}
+
+	err = emitEvent(ctx, "TransferPending", transfer)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TransferResult{Gross: amount, Pending: transfer.ID}, nil
+}
+
+// readReviewableTransfer returns the pending transfer with the given ID and the client reviewing it,
+// failing unless the client holds the compliance role, is not the sender and the transfer awaits review
+func readReviewableTransfer(ctx contractapi.TransactionContextInterface, id string) (*PendingTransfer, string, error) {
+	if err := checkRoleOrAdmin(ctx, complianceRole); err != nil {
+		return nil, "", err
+	}
+
+	transfer, err := readPendingTransfer(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+	if transfer.Status != transferPending {
+		return nil, "", codedError(invalidArgumentCode, "transfer %s is already %s", id, transfer.Status)
+	}
+
+	reviewer, err := getClientAccountID(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	if reviewer == transfer.From {
+		return nil, "", codedError(unauthorizedCode, "client cannot review its own transfer %s", id)
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	if now >= transfer.Expiration {
+		return nil, "", codedError(invalidArgumentCode, "review period of transfer %s has expired", id)
+	}
+
+	return transfer, reviewer, nil
+}
+
+// unreserve returns the tokens of a pending transfer to the available balance of its sender
+func unreserve(ctx contractapi.TransactionContextInterface, transfer *PendingTransfer) error {
+	held, err := readHeld(ctx, transfer.From)
+	if err != nil {
+		return err
+	}
+
+	return writeHeld(ctx, transfer.From, held-transfer.Amount)
+}
+
+// SetComplianceConfig sends transfers of more than threshold tokens to compliance review, to be reviewed
+// within reviewPeriod seconds. A zero threshold reviews no transfer. Only the admin organization may set it.
+func (s *SmartContract) SetComplianceConfig(ctx contractapi.TransactionContextInterface, threshold int, reviewPeriod int64) error {
+	if err := checkInitialized(ctx); err != nil {
+		return err
+	}
+	if err := checkAdmin(ctx); err != nil {
+		return err
+	}
+	if threshold < 0 {
+		return codedError(invalidArgumentCode, "threshold must not be negative")
+	}
+	if threshold > 0 && reviewPeriod <= 0 {
+		return codedError(invalidArgumentCode, "review period must be a positive number of seconds")
+	}
+
+	config := ComplianceConfig{Threshold: threshold, ReviewPeriod: reviewPeriod}
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	key, err := complianceConfigKey(ctx)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(key, configJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put compliance config into world state: %v", err)
+	}
+
+	return emitEvent(ctx, "ComplianceConfigChanged", config)
+}
+
+// GetComplianceConfig returns the threshold over which transfers are reviewed and the review period
+func (s *SmartContract) GetComplianceConfig(ctx contractapi.TransactionContextInterface) (*ComplianceConfig, error) {
+	if err := checkInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	return readComplianceConfig(ctx)
+}
+
+// ApproveTransfer settles a pending transfer, charging the transfer fee in force.
+// Only holders of the compliance role, the admin organization until governance is initialized,
+// may approve a transfer, and only within its review period.
+func (s *SmartContract) ApproveTransfer(ctx contractapi.TransactionContextInterface, id string) error {
+	if err := checkInitialized(ctx); err != nil {
+		return err
+	}
+
+	transfer, reviewer, err := readReviewableTransfer(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// The reserved tokens are part of the balance of the sender but not of its available balance,
+	// so spend them without going through debit
+	err = unreserve(ctx, transfer)
+	if err != nil {
+		return err
+	}
+	err = withdraw(ctx, transfer.From, transfer.Amount)
+	if err != nil {
+		return err
+	}
+	result, err := settle(ctx, transfer.From, transfer.To, transfer.Amount)
+	if err != nil {
+		return err
+	}
+
+	transfer.Fee = result.Fee
+	transfer.Status = transferApproved
+	transfer.Reviewer = reviewer
+	err = putPendingTransfer(ctx, transfer)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, "TransferApproved", transfer)
+}
+
+// RejectTransfer returns the tokens of a pending transfer to its sender, recording the reason.
+// The allowance spent by a TransferFrom is not restored.
+// Only holders of the compliance role, the admin organization until governance is initialized,
+// may reject a transfer.
+func (s *SmartContract) RejectTransfer(ctx contractapi.TransactionContextInterface, id string, reason string) error {
+	if err := checkInitialized(ctx); err != nil {
+		return err
+	}
+	if reason == "" {
+		return codedError(invalidArgumentCode, "a reason is required to reject a transfer")
+	}
+
+	transfer, reviewer, err := readReviewableTransfer(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = unreserve(ctx, transfer)
+	if err != nil {
+		return err
+	}
+
+	transfer.Status = transferRejected
+	transfer.Reviewer = reviewer
+	transfer.Reason = reason
+	err = putPendingTransfer(ctx, transfer)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, "TransferRejected", transfer)
+}
+
+// RefundTransfer returns the tokens of a pending transfer nobody reviewed in time to its sender.
+// Any client may refund a transfer once its review period has expired.
+func (s *SmartContract) RefundTransfer(ctx contractapi.TransactionContextInterface, id string) error {
+	if err := checkInitialized(ctx); err != nil {
+		return err
+	}
+
+	transfer, err := readPendingTransfer(ctx, id)
+	if err != nil {
+		return err
+	}
+	if transfer.Status != transferPending {
+		return codedError(invalidArgumentCode, "transfer %s is already %s", id, transfer.Status)
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+	if now < transfer.Expiration {
+		return codedError(invalidArgumentCode, "transfer %s is still under review", id)
+	}
+
+	err = unreserve(ctx, transfer)
+	if err != nil {
+		return err
+	}
+
+	transfer.Status = transferRefunded
+	err = putPendingTransfer(ctx, transfer)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, "TransferRefunded", transfer)
+}
+
+// ReadPendingTransfer returns the pending transfer with the given ID, whatever its status
+func (s *SmartContract) ReadPendingTransfer(ctx contractapi.TransactionContextInterface, id string) (*PendingTransfer, error) {
+	if err := checkInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	return readPendingTransfer(ctx, id)
+}
+
+// GetPendingTransfers returns the review queue: the transfers awaiting review, including the
+// expired ones whose tokens are still reserved until they are refunded
+func (s *SmartContract) GetPendingTransfers(ctx contractapi.TransactionContextInterface) ([]*PendingTransfer, error) {
+	if err := checkInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(pendingTransferPrefix, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending transfers: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	transfers := []*PendingTransfer{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var transfer PendingTransfer
+		err = json.Unmarshal(queryResponse.Value, &transfer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal pending transfer: %v", err)
+		}
+		if transfer.Status == transferPending {
+			transfers = append(transfers, &transfer)
+		}
+	}
+
+	return transfers, nil
+}
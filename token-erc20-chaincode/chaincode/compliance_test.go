@@ -0,0 +1,127 @@
+package chaincode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// heldOf returns the committed amount of tokens of account reserved by holds and pending transfers
+func heldOf(t *testing.T, stub *mockStub, account string) int {
+	t.Helper()
+
+	var held int
+	mustInvoke(t, stub, newIdentity("reader", "Org2MSP"), func(ctx contractapi.TransactionContextInterface) error {
+		var err error
+		held, err = readHeld(ctx, account)
+		return err
+	})
+	return held
+}
+
+func TestComplianceReview(t *testing.T) {
+	contract := &SmartContract{}
+	admin := newIdentity("admin", "Org1MSP")
+	alice := newIdentity("alice", "Org2MSP")
+	bob := newIdentity("bob", "Org2MSP")
+	stub := newToken(t, admin, 1000)
+
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.Transfer(ctx, alice.id, 1000)
+		return err
+	})
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.SetComplianceConfig(ctx, 100, 3600)
+	})
+
+	// transfer sends amount tokens from alice to bob and returns the ID of the pending transfer
+	transfer := func(amount int) string {
+		var result *TransferResult
+		mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+			var err error
+			result, err = contract.Transfer(ctx, bob.id, amount)
+			return err
+		})
+		return result.Pending
+	}
+
+	if id := transfer(100); id != "" {
+		t.Fatalf("got pending transfer %s at the threshold, want none", id)
+	}
+
+	id := transfer(300)
+	if id == "" || stub.event.name != "TransferPending" {
+		t.Fatalf("got pending transfer %q and event %v, want a pending transfer", id, stub.event)
+	}
+	if balance, held := balanceOf(t, stub, alice.id), heldOf(t, stub, alice.id); balance != 900 || held != 300 {
+		t.Fatalf("got balance %d with %d held, want 900 with 300 held", balance, held)
+	}
+	mustInvoke(t, stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		transfers, err := contract.GetPendingTransfers(ctx)
+		if len(transfers) != 1 {
+			t.Fatalf("got %d pending transfers, want 1", len(transfers))
+		}
+		return err
+	})
+
+	// The reserved tokens cannot be spent again while under review
+	err := invoke(stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.Transfer(ctx, bob.id, 700)
+		return err
+	})
+	assertCode(t, err, insufficientFundsCode)
+
+	err = invoke(stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.ApproveTransfer(ctx, id)
+	})
+	assertCode(t, err, unauthorizedCode)
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.ApproveTransfer(ctx, id)
+	})
+	if balance, held := balanceOf(t, stub, alice.id), heldOf(t, stub, alice.id); balance != 600 || held != 0 {
+		t.Fatalf("got balance %d with %d held after approval, want 600 with none held", balance, held)
+	}
+	if balance := balanceOf(t, stub, bob.id); balance != 400 {
+		t.Fatalf("got recipient balance %d, want 400", balance)
+	}
+
+	// A rejected transfer returns the tokens and records the reason
+	id = transfer(200)
+	err = invoke(stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.RejectTransfer(ctx, id, "")
+	})
+	assertCode(t, err, invalidArgumentCode)
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.RejectTransfer(ctx, id, "sanctioned recipient")
+	})
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		pending, err := contract.ReadPendingTransfer(ctx, id)
+		if pending.Status != transferRejected || pending.Reason != "sanctioned recipient" {
+			t.Fatalf("got pending transfer %+v, want it rejected with its reason", pending)
+		}
+		return err
+	})
+	if balance, held := balanceOf(t, stub, alice.id), heldOf(t, stub, alice.id); balance != 600 || held != 0 {
+		t.Fatalf("got balance %d with %d held after rejection, want 600 with none held", balance, held)
+	}
+
+	// A transfer nobody reviews in time can no longer be approved and is refunded
+	id = transfer(200)
+	err = invoke(stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.RefundTransfer(ctx, id)
+	})
+	assertCode(t, err, invalidArgumentCode)
+
+	stub.timestamp = stub.timestamp.Add(time.Hour)
+	err = invoke(stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.ApproveTransfer(ctx, id)
+	})
+	assertCode(t, err, invalidArgumentCode)
+	mustInvoke(t, stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.RefundTransfer(ctx, id)
+	})
+	if balance, held := balanceOf(t, stub, alice.id), heldOf(t, stub, alice.id); balance != 600 || held != 0 {
+		t.Fatalf("got balance %d with %d held after refund, want 600 with none held", balance, held)
+	}
+}
@@ -0,0 +1,292 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+const (
+	feeConfigPrefix    = "feeconfig"
+	feeExemptionPrefix = "feeexempt"
+)
+
+// maxFeeRate is a fee of 100%, in basis points
+const maxFeeRate = 10000
+
+// FeeConfig is the fee charged on Transfer and TransferFrom and routed to Treasury.
+// Rate is in basis points of the transferred amount; the fee is then raised to MinFee and,
+// unless MaxFee is zero, capped at MaxFee. It never exceeds the transferred amount.
+type FeeConfig struct {
+	Rate     int    `json:"rate"`
+	MinFee   int    `json:"min_fee"`
+	MaxFee   int    `json:"max_fee"`
+	Treasury string `json:"treasury"`
+}
+
+// FeeExemption is emitted when an account is exempted from fees or the exemption is lifted
+type FeeExemption struct {
+	Account string `json:"account"`
+	Exempt  bool   `json:"exempt"`
+}
+
+// TransferResult is returned by Transfer and TransferFrom: Gross tokens left the sender,
+// Fee of them went to the treasury and Net reached the recipient. A transfer awaiting compliance
+// review only reserves Gross tokens of the sender, and Pending is the ID of the pending transfer.
+type TransferResult struct {
+	Gross   int    `json:"gross"`
+	Fee     int    `json:"fee"`
+	Net     int    `json:"net"`
+	Pending string `json:"pending,omitempty"`
+}
+
+// feeConfigKey builds the key of the fee configuration
+func feeConfigKey(ctx contractapi.TransactionContextInterface) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(feeConfigPrefix, []string{})
+	if err != nil {
+		return "", fmt.Errorf("failed to create fee config key: %v", err)
+	}
+
+	return key, nil
+}
+
+// readFeeConfig returns the fee configuration, a zero configuration charging no fee if none was set
+func readFeeConfig(ctx contractapi.TransactionContextInterface) (*FeeConfig, error) {
+	key, err := feeConfigKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	configJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+
+	config := &FeeConfig{}
+	if configJSON == nil {
+		return config, nil
+	}
+
+	err = json.Unmarshal(configJSON, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal fee config: %v", err)
+	}
+
+	return config, nil
+}
+
+// feeExemptionKey builds the key marking account as exempt from fees
+func feeExemptionKey(ctx contractapi.TransactionContextInterface, account string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(feeExemptionPrefix, []string{account})
+	if err != nil {
+		return "", fmt.Errorf("failed to create fee exemption key: %v", err)
+	}
+
+	return key, nil
+}
+
+// isFeeExempt reports whether account is exempt from fees
+func isFeeExempt(ctx contractapi.TransactionContextInterface, account string) (bool, error) {
+	key, err := feeExemptionKey(ctx, account)
+	if err != nil {
+		return false, err
+	}
+
+	exemptBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return false, fmt.Errorf("failed to read from world state: %v", err)
+	}
+
+	return exemptBytes != nil, nil
+}
+
+// fee returns the fee config charges on amount tokens
+func (config *FeeConfig) fee(amount int) int {
+	// Split amount so that multiplying by the rate cannot overflow
+	fee := amount/maxFeeRate*config.Rate + amount%maxFeeRate*config.Rate/maxFeeRate
+	if fee < config.MinFee {
+		fee = config.MinFee
+	}
+	if config.MaxFee > 0 && fee > config.MaxFee {
+		fee = config.MaxFee
+	}
+	if fee > amount {
+		fee = amount
+	}
+
+	return fee
+}
+
+// transferFee returns the fee charged on a transfer of amount tokens from one account to another.
+// Transfers from or to the treasury or an exempt account are free.
+func transferFee(ctx contractapi.TransactionContextInterface, from string, to string, amount int) (int, *FeeConfig, error) {
+	config, err := readFeeConfig(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+	if config.Treasury == "" || from == config.Treasury || to == config.Treasury {
+		return 0, config, nil
+	}
+
+	for _, account := range []string{from, to} {
+		exempt, err := isFeeExempt(ctx, account)
+		if err != nil {
+			return 0, nil, err
+		}
+		if exempt {
+			return 0, config, nil
+		}
+	}
+
+	return config.fee(amount), config, nil
+}
+
+// transferWithFee moves amount tokens from one account to another, routing the fee to the treasury.
+// The amount counts against the daily transfer limit of the sender. Transfers over the compliance
+// threshold are not settled but reserved until a compliance officer reviews them.
+func transferWithFee(ctx contractapi.TransactionContextInterface, from string, to string, amount int) (*TransferResult, error) {
+	if from == to {
+		return nil, codedError(invalidArgumentCode, "cannot transfer to and from the same account")
+	}
+	if err := checkAmount(amount); err != nil {
+		return nil, err
+	}
+
+	err := spend(ctx, from, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	review, err := needsReview(ctx, amount)
+	if err != nil {
+		return nil, err
+	}
+	if review {
+		return escrowTransfer(ctx, from, to, amount)
+	}
+
+	err = debit(ctx, from, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return settle(ctx, from, to, amount)
+}
+
+// settle credits amount tokens, already removed from the balance of from, to the recipient and the treasury.
+// The treasury is never the sender or the recipient of a charged transfer, so the three balances differ.
+func settle(ctx contractapi.TransactionContextInterface, from string, to string, amount int) (*TransferResult, error) {
+	fee, config, err := transferFee(ctx, from, to, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TransferResult{Gross: amount, Fee: fee, Net: amount - fee}
+	if result.Net == 0 {
+		return nil, codedError(invalidArgumentCode, "the fee of %d takes the whole amount of the transfer", fee)
+	}
+
+	err = credit(ctx, to, result.Net)
+	if err != nil {
+		return nil, err
+	}
+
+	if fee > 0 {
+		err = credit(ctx, config.Treasury, fee)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// SetFeeConfig sets the fee charged on transfers: rate in basis points of the amount, raised to minFee and
+// capped at maxFee unless it is zero, routed to treasury. A zero rate and minFee charge no fee.
+// Only the admin organization may set the fees.
+func (s *SmartContract) SetFeeConfig(ctx contractapi.TransactionContextInterface, rate int, minFee int, maxFee int, treasury string) error {
+	if err := checkInitialized(ctx); err != nil {
+		return err
+	}
+	if err := checkAdmin(ctx); err != nil {
+		return err
+	}
+	if rate < 0 || rate > maxFeeRate {
+		return codedError(invalidArgumentCode, "fee rate must be between 0 and %d basis points", maxFeeRate)
+	}
+	if minFee < 0 || maxFee < 0 {
+		return codedError(invalidArgumentCode, "minimum and maximum fees must not be negative")
+	}
+	if maxFee > 0 && maxFee < minFee {
+		return codedError(invalidArgumentCode, "maximum fee %d is below the minimum fee %d", maxFee, minFee)
+	}
+	if treasury == "" && (rate > 0 || minFee > 0) {
+		return codedError(invalidArgumentCode, "a treasury account is required to charge fees")
+	}
+
+	config := FeeConfig{Rate: rate, MinFee: minFee, MaxFee: maxFee, Treasury: treasury}
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	key, err := feeConfigKey(ctx)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(key, configJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put fee config into world state: %v", err)
+	}
+
+	return emitEvent(ctx, "FeeConfigChanged", config)
+}
+
+// GetFeeConfig returns the fee charged on transfers
+func (s *SmartContract) GetFeeConfig(ctx contractapi.TransactionContextInterface) (*FeeConfig, error) {
+	if err := checkInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	return readFeeConfig(ctx)
+}
+
+// SetFeeExemption exempts account from transfer fees, or lifts its exemption.
+// Transfers from or to an exempt account, such as a system account, are free.
+// Only the admin organization may exempt accounts.
+func (s *SmartContract) SetFeeExemption(ctx contractapi.TransactionContextInterface, account string, exempt bool) error {
+	if err := checkInitialized(ctx); err != nil {
+		return err
+	}
+	if err := checkAdmin(ctx); err != nil {
+		return err
+	}
+	if account == "" {
+		return codedError(invalidArgumentCode, "account must not be empty")
+	}
+
+	key, err := feeExemptionKey(ctx, account)
+	if err != nil {
+		return err
+	}
+	if exempt {
+		err = ctx.GetStub().PutState(key, []byte{0x00})
+	} else {
+		err = ctx.GetStub().DelState(key)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update fee exemption of %s: %v", account, err)
+	}
+
+	return emitEvent(ctx, "FeeExemptionChanged", FeeExemption{Account: account, Exempt: exempt})
+}
+
+// IsFeeExempt reports whether account is exempt from transfer fees
+func (s *SmartContract) IsFeeExempt(ctx contractapi.TransactionContextInterface, account string) (bool, error) {
+	if err := checkInitialized(ctx); err != nil {
+		return false, err
+	}
+
+	return isFeeExempt(ctx, account)
+}
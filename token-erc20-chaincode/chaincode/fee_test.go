@@ -0,0 +1,141 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+func TestFeeConfig(t *testing.T) {
+	contract := &SmartContract{}
+	admin := newIdentity("admin", "Org1MSP")
+	treasury := newIdentity("treasury", "Org1MSP")
+	stub := newToken(t, admin, 100)
+
+	err := invoke(stub, newIdentity("bob", "Org2MSP"), func(ctx contractapi.TransactionContextInterface) error {
+		return contract.SetFeeConfig(ctx, 100, 0, 0, treasury.id)
+	})
+	assertCode(t, err, unauthorizedCode)
+
+	tests := []struct {
+		name     string
+		rate     int
+		minFee   int
+		maxFee   int
+		treasury string
+	}{
+		{name: "rate above 100%", rate: maxFeeRate + 1, treasury: treasury.id},
+		{name: "negative minimum", rate: 100, minFee: -1, treasury: treasury.id},
+		{name: "maximum below minimum", rate: 100, minFee: 5, maxFee: 4, treasury: treasury.id},
+		{name: "missing treasury", rate: 100},
+	}
+	for _, test := range tests {
+		err := invoke(stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+			return contract.SetFeeConfig(ctx, test.rate, test.minFee, test.maxFee, test.treasury)
+		})
+		assertCode(t, err, invalidArgumentCode)
+	}
+
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.SetFeeConfig(ctx, 250, 1, 10, treasury.id)
+	})
+	if stub.event == nil || stub.event.name != "FeeConfigChanged" {
+		t.Fatalf("got event %v, want a FeeConfigChanged event", stub.event)
+	}
+}
+
+func TestFee(t *testing.T) {
+	config := FeeConfig{Rate: 250, MinFee: 1, MaxFee: 10, Treasury: "treasury"}
+
+	tests := []struct {
+		amount int
+		fee    int
+	}{
+		{amount: 1, fee: 1},
+		{amount: 39, fee: 1},
+		{amount: 200, fee: 5},
+		{amount: 1000, fee: 10},
+		{amount: 1 << 62, fee: 10},
+	}
+	for _, test := range tests {
+		if fee := config.fee(test.amount); fee != test.fee {
+			t.Errorf("got fee %d on %d tokens, want %d", fee, test.amount, test.fee)
+		}
+	}
+
+	uncapped := FeeConfig{Rate: maxFeeRate / 2, Treasury: "treasury"}
+	if fee := uncapped.fee(1 << 62); fee != 1<<61 {
+		t.Errorf("got fee %d on %d tokens, want %d", fee, 1<<62, 1<<61)
+	}
+}
+
+func TestTransferWithFee(t *testing.T) {
+	contract := &SmartContract{}
+	admin := newIdentity("admin", "Org1MSP")
+	treasury := newIdentity("treasury", "Org1MSP")
+	bob := newIdentity("bob", "Org2MSP")
+	carol := newIdentity("carol", "Org2MSP")
+	stub := newToken(t, admin, 1000)
+
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.SetFeeConfig(ctx, 100, 2, 0, treasury.id)
+	})
+
+	var result *TransferResult
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		var err error
+		result, err = contract.Transfer(ctx, bob.id, 500)
+		return err
+	})
+	if *result != (TransferResult{Gross: 500, Fee: 5, Net: 495}) {
+		t.Fatalf("got result %+v", result)
+	}
+	var transfer Transfer
+	if err := json.Unmarshal(stub.event.payload, &transfer); err != nil {
+		t.Fatalf("failed to unmarshal event: %v", err)
+	}
+	if transfer != (Transfer{From: admin.id, To: bob.id, Value: 495, Fee: 5}) {
+		t.Fatalf("got transfer %+v", transfer)
+	}
+
+	// The minimum fee applies, and the sender must cover the gross amount
+	err := invoke(stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.Transfer(ctx, carol.id, 2)
+		return err
+	})
+	assertCode(t, err, invalidArgumentCode)
+	err = invoke(stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.Transfer(ctx, carol.id, 496)
+		return err
+	})
+	assertCode(t, err, insufficientFundsCode)
+
+	// TransferFrom spends the gross amount of the allowance
+	mustInvoke(t, stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.Approve(ctx, carol.id, 100)
+	})
+	mustInvoke(t, stub, carol, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.TransferFrom(ctx, bob.id, carol.id, 100)
+		return err
+	})
+
+	// Transfers involving an exempt account are free
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.SetFeeExemption(ctx, carol.id, true)
+	})
+	mustInvoke(t, stub, carol, func(ctx contractapi.TransactionContextInterface) error {
+		result, err := contract.Transfer(ctx, bob.id, 8)
+		if err == nil && result.Fee != 0 {
+			t.Fatalf("got fee %d from an exempt account", result.Fee)
+		}
+		return err
+	})
+
+	wants := map[string]int{admin.id: 500, bob.id: 403, carol.id: 90, treasury.id: 7}
+	for account, want := range wants {
+		if balance := balanceOf(t, stub, account); balance != want {
+			t.Errorf("got balance %d of %s, want %d", balance, account, want)
+		}
+	}
+}
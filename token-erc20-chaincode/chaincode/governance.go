@@ -0,0 +1,588 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+const (
+	governancePrefix = "governance"
+	operationPrefix  = "operation"
+	rolePrefix       = "role"
+)
+
+// governorAttribute is the certificate attribute, set to "true", of the identities that may
+// propose, approve and reject governance operations on behalf of their organization
+const governorAttribute = "token.governor"
+
+// Roles granted to accounts by governance operations
+const (
+	burnerRole     = "burner"
+	complianceRole = "compliance"
+	recoveryRole   = "recovery"
+)
+
+// knownRoles are the roles governance operations may grant and revoke
+var knownRoles = map[string]bool{
+	burnerRole:     true,
+	complianceRole: true,
+	recoveryRole:   true,
+}
+
+// Types of governance operations
+const (
+	mintOperation       = "mint"
+	grantRoleOperation  = "grantRole"
+	revokeRoleOperation = "revokeRole"
+)
+
+// Statuses of a governance operation. An operation still pending after its expiration has expired.
+const (
+	operationPending  = "pending"
+	operationExecuted = "executed"
+	operationRejected = "rejected"
+)
+
+// Governance lists the organizations whose governors approve operations and how many of them must
+// approve an operation before it executes
+type Governance struct {
+	Orgs      []string `json:"orgs"`
+	Threshold int      `json:"threshold"`
+}
+
+// Operation is a mint or role change that executes once Threshold distinct organizations of the
+// governance approved it, unless enough of them reject it first or it expires. Mints credit Amount
+// tokens to Account; role changes grant or revoke Role to Account. Expiration is in seconds since
+// the Unix epoch.
+type Operation struct {
+	ID         string   `json:"id"`
+	Type       string   `json:"type"`
+	Account    string   `json:"account"`
+	Amount     int      `json:"amount,omitempty"`
+	Role       string   `json:"role,omitempty"`
+	Proposer   string   `json:"proposer"`
+	Approvals  []string `json:"approvals"`
+	Rejections []string `json:"rejections"`
+	Expiration int64    `json:"expiration"`
+	Status     string   `json:"status"`
+}
+
+// governanceKey builds the key of the governance configuration
+func governanceKey(ctx contractapi.TransactionContextInterface) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(governancePrefix, []string{})
+	if err != nil {
+		return "", fmt.Errorf("failed to create governance key: %v", err)
+	}
+
+	return key, nil
+}
+
+// readGovernance returns the governance configuration, nil if governance is not initialized
+func readGovernance(ctx contractapi.TransactionContextInterface) (*Governance, error) {
+	key, err := governanceKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	governanceJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if governanceJSON == nil {
+		return nil, nil
+	}
+
+	var governance Governance
+	err = json.Unmarshal(governanceJSON, &governance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal governance: %v", err)
+	}
+
+	return &governance, nil
+}
+
+// isGoverned reports whether governance is initialized, so that minting and role changes go through operations
+func isGoverned(ctx contractapi.TransactionContextInterface) (bool, error) {
+	governance, err := readGovernance(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return governance != nil, nil
+}
+
+// roleKey builds the key marking account as holding role
+func roleKey(ctx contractapi.TransactionContextInterface, role string, account string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(rolePrefix, []string{role, account})
+	if err != nil {
+		return "", fmt.Errorf("failed to create role key: %v", err)
+	}
+
+	return key, nil
+}
+
+// hasRole reports whether account holds role
+func hasRole(ctx contractapi.TransactionContextInterface, role string, account string) (bool, error) {
+	key, err := roleKey(ctx, role, account)
+	if err != nil {
+		return false, err
+	}
+
+	roleBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return false, fmt.Errorf("failed to read from world state: %v", err)
+	}
+
+	return roleBytes != nil, nil
+}
+
+// checkRole returns an error unless the client holds role
+func checkRole(ctx contractapi.TransactionContextInterface, role string) error {
+	clientID, err := getClientAccountID(ctx)
+	if err != nil {
+		return err
+	}
+
+	granted, err := hasRole(ctx, role, clientID)
+	if err != nil {
+		return err
+	}
+	if !granted {
+		return codedError(unauthorizedCode, "client does not hold the %s role", role)
+	}
+
+	return nil
+}
+
+// checkRoleOrAdmin returns an error unless the client holds role once governance is initialized,
+// or belongs to the admin organization until then
+func checkRoleOrAdmin(ctx contractapi.TransactionContextInterface, role string) error {
+	governed, err := isGoverned(ctx)
+	if err != nil {
+		return err
+	}
+	if governed {
+		return checkRole(ctx, role)
+	}
+
+	return checkAdmin(ctx)
+}
+
+// checkGovernor returns the governance and the organization of the client,
+// failing unless the client is a governor of one of the organizations of the governance
+func checkGovernor(ctx contractapi.TransactionContextInterface) (*Governance, string, error) {
+	governance, err := readGovernance(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	if governance == nil {
+		return nil, "", codedError(invalidArgumentCode, "governance is not initialized")
+	}
+
+	err = ctx.GetClientIdentity().AssertAttributeValue(governorAttribute, "true")
+	if err != nil {
+		return nil, "", codedError(unauthorizedCode, "client is not a governor: %v", err)
+	}
+
+	clientMSPID, err := getClientMSPID(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	if !contains(governance.Orgs, clientMSPID) {
+		return nil, "", codedError(unauthorizedCode, "organization %s does not take part in governance", clientMSPID)
+	}
+
+	return governance, clientMSPID, nil
+}
+
+// contains reports whether values contains value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// operationKey builds the key of the governance operation with the given ID
+func operationKey(ctx contractapi.TransactionContextInterface, id string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(operationPrefix, []string{id})
+	if err != nil {
+		return "", fmt.Errorf("failed to create operation key: %v", err)
+	}
+
+	return key, nil
+}
+
+// readOperation returns the governance operation with the given ID
+func readOperation(ctx contractapi.TransactionContextInterface, id string) (*Operation, error) {
+	key, err := operationKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	operationJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if operationJSON == nil {
+		return nil, codedError(notFoundCode, "operation %s does not exist", id)
+	}
+
+	var operation Operation
+	err = json.Unmarshal(operationJSON, &operation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal operation %s: %v", id, err)
+	}
+
+	return &operation, nil
+}
+
+// putOperation writes operation to the world state
+func putOperation(ctx contractapi.TransactionContextInterface, operation *Operation) error {
+	key, err := operationKey(ctx, operation.ID)
+	if err != nil {
+		return err
+	}
+
+	operationJSON, err := json.Marshal(operation)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	err = ctx.GetStub().PutState(key, operationJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put operation %s into world state: %v", operation.ID, err)
+	}
+
+	return nil
+}
+
+// readPendingOperation returns the operation with the given ID, failing unless it is still pending and not expired
+func readPendingOperation(ctx contractapi.TransactionContextInterface, id string) (*Operation, error) {
+	operation, err := readOperation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if operation.Status != operationPending {
+		return nil, codedError(invalidArgumentCode, "operation %s is already %s", id, operation.Status)
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if now >= operation.Expiration {
+		return nil, codedError(invalidArgumentCode, "operation %s has expired", id)
+	}
+
+	return operation, nil
+}
+
+// executeOperation applies an operation approved by enough organizations
+func executeOperation(ctx contractapi.TransactionContextInterface, operation *Operation) error {
+	switch operation.Type {
+	case mintOperation:
+		err := mint(ctx, operation.Account, operation.Amount)
+		if err != nil {
+			return err
+		}
+	case grantRoleOperation, revokeRoleOperation:
+		key, err := roleKey(ctx, operation.Role, operation.Account)
+		if err != nil {
+			return err
+		}
+		if operation.Type == grantRoleOperation {
+			err = ctx.GetStub().PutState(key, []byte{0x00})
+		} else {
+			err = ctx.GetStub().DelState(key)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update role %s of %s: %v", operation.Role, operation.Account, err)
+		}
+	default:
+		return fmt.Errorf("unknown operation type %s", operation.Type)
+	}
+
+	operation.Status = operationExecuted
+	return nil
+}
+
+// propose stores a new operation approved by the organization of the proposing governor,
+// executing it at once if the threshold is a single organization
+func propose(ctx contractapi.TransactionContextInterface, operation *Operation) error {
+	if err := checkInitialized(ctx); err != nil {
+		return err
+	}
+	if operation.ID == "" || operation.Account == "" {
+		return codedError(invalidArgumentCode, "operation ID and account must not be empty")
+	}
+
+	governance, clientMSPID, err := checkGovernor(ctx)
+	if err != nil {
+		return err
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+	if operation.Expiration <= now {
+		return codedError(invalidArgumentCode, "operation expiration must be in the future")
+	}
+
+	key, err := operationKey(ctx, operation.ID)
+	if err != nil {
+		return err
+	}
+	existing, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if existing != nil {
+		return codedError(invalidArgumentCode, "operation %s already exists", operation.ID)
+	}
+
+	operation.Proposer, err = getClientAccountID(ctx)
+	if err != nil {
+		return err
+	}
+	operation.Approvals = []string{clientMSPID}
+	operation.Rejections = []string{}
+	operation.Status = operationPending
+
+	event := "OperationProposed"
+	if len(operation.Approvals) >= governance.Threshold {
+		err = executeOperation(ctx, operation)
+		if err != nil {
+			return err
+		}
+		event = "OperationExecuted"
+	}
+
+	err = putOperation(ctx, operation)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, event, operation)
+}
+
+// InitializeGovernance hands minting and role changes over to governance operations: from now on they
+// execute once governors of threshold distinct organizations of orgs approved them. It can only be called once.
+// Only the admin organization may initialize governance.
+func (s *SmartContract) InitializeGovernance(ctx contractapi.TransactionContextInterface, orgs []string, threshold int) error {
+	if err := checkInitialized(ctx); err != nil {
+		return err
+	}
+	if err := checkAdmin(ctx); err != nil {
+		return err
+	}
+
+	governance, err := readGovernance(ctx)
+	if err != nil {
+		return err
+	}
+	if governance != nil {
+		return codedError(invalidArgumentCode, "governance is already initialized")
+	}
+
+	for i, org := range orgs {
+		if org == "" || contains(orgs[:i], org) {
+			return codedError(invalidArgumentCode, "organizations must be distinct and not empty")
+		}
+	}
+	if threshold < 1 || threshold > len(orgs) {
+		return codedError(invalidArgumentCode, "threshold must be between 1 and the number of organizations, %d", len(orgs))
+	}
+
+	governance = &Governance{Orgs: orgs, Threshold: threshold}
+	governanceJSON, err := json.Marshal(governance)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	key, err := governanceKey(ctx)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(key, governanceJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put governance into world state: %v", err)
+	}
+
+	return emitEvent(ctx, "GovernanceInitialized", governance)
+}
+
+// GetGovernance returns the organizations taking part in governance and the approval threshold
+func (s *SmartContract) GetGovernance(ctx contractapi.TransactionContextInterface) (*Governance, error) {
+	if err := checkInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	governance, err := readGovernance(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if governance == nil {
+		return nil, codedError(notFoundCode, "governance is not initialized")
+	}
+
+	return governance, nil
+}
+
+// ProposeMint proposes minting amount tokens to recipient. The operation expires at expiration,
+// in seconds since the Unix epoch. The organization of the proposing governor approves it.
+func (s *SmartContract) ProposeMint(ctx contractapi.TransactionContextInterface, id string, recipient string, amount int, expiration int64) error {
+	if err := checkAmount(amount); err != nil {
+		return err
+	}
+
+	return propose(ctx, &Operation{ID: id, Type: mintOperation, Account: recipient, Amount: amount, Expiration: expiration})
+}
+
+// ProposeRoleChange proposes granting role to account, or revoking it. The operation expires at expiration,
+// in seconds since the Unix epoch. The organization of the proposing governor approves it.
+func (s *SmartContract) ProposeRoleChange(ctx contractapi.TransactionContextInterface, id string, role string, account string, grant bool, expiration int64) error {
+	if !knownRoles[role] {
+		return codedError(invalidArgumentCode, "unknown role %s", role)
+	}
+
+	operationType := revokeRoleOperation
+	if grant {
+		operationType = grantRoleOperation
+	}
+
+	return propose(ctx, &Operation{ID: id, Type: operationType, Account: account, Role: role, Expiration: expiration})
+}
+
+// ApproveOperation approves a pending operation on behalf of the organization of the client, a governor.
+// The operation executes in this transaction once the approving organizations reach the threshold.
+func (s *SmartContract) ApproveOperation(ctx contractapi.TransactionContextInterface, id string) error {
+	if err := checkInitialized(ctx); err != nil {
+		return err
+	}
+
+	governance, clientMSPID, err := checkGovernor(ctx)
+	if err != nil {
+		return err
+	}
+
+	operation, err := readPendingOperation(ctx, id)
+	if err != nil {
+		return err
+	}
+	if contains(operation.Approvals, clientMSPID) || contains(operation.Rejections, clientMSPID) {
+		return codedError(invalidArgumentCode, "organization %s already voted on operation %s", clientMSPID, id)
+	}
+
+	operation.Approvals = append(operation.Approvals, clientMSPID)
+
+	event := "OperationApproved"
+	if len(operation.Approvals) >= governance.Threshold {
+		err = executeOperation(ctx, operation)
+		if err != nil {
+			return err
+		}
+		event = "OperationExecuted"
+	}
+
+	err = putOperation(ctx, operation)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, event, operation)
+}
+
+// RejectOperation rejects a pending operation on behalf of the organization of the client, a governor.
+// The operation is rejected for good once too few organizations are left to reach the threshold.
+func (s *SmartContract) RejectOperation(ctx contractapi.TransactionContextInterface, id string) error {
+	if err := checkInitialized(ctx); err != nil {
+		return err
+	}
+
+	governance, clientMSPID, err := checkGovernor(ctx)
+	if err != nil {
+		return err
+	}
+
+	operation, err := readPendingOperation(ctx, id)
+	if err != nil {
+		return err
+	}
+	if contains(operation.Approvals, clientMSPID) || contains(operation.Rejections, clientMSPID) {
+		return codedError(invalidArgumentCode, "organization %s already voted on operation %s", clientMSPID, id)
+	}
+
+	operation.Rejections = append(operation.Rejections, clientMSPID)
+	if len(governance.Orgs)-len(operation.Rejections) < governance.Threshold {
+		operation.Status = operationRejected
+	}
+
+	err = putOperation(ctx, operation)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, "OperationRejected", operation)
+}
+
+// ReadOperation returns the governance operation with the given ID
+func (s *SmartContract) ReadOperation(ctx contractapi.TransactionContextInterface, id string) (*Operation, error) {
+	if err := checkInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	return readOperation(ctx, id)
+}
+
+// GetPendingOperations returns the operations still awaiting approval that have not expired
+func (s *SmartContract) GetPendingOperations(ctx contractapi.TransactionContextInterface) ([]*Operation, error) {
+	if err := checkInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(operationPrefix, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get operations: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	operations := []*Operation{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var operation Operation
+		err = json.Unmarshal(queryResponse.Value, &operation)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal operation: %v", err)
+		}
+		if operation.Status == operationPending && now < operation.Expiration {
+			operations = append(operations, &operation)
+		}
+	}
+
+	return operations, nil
+}
+
+// HasRole reports whether account holds role
+func (s *SmartContract) HasRole(ctx contractapi.TransactionContextInterface, role string, account string) (bool, error) {
+	if err := checkInitialized(ctx); err != nil {
+		return false, err
+	}
+
+	return hasRole(ctx, role, account)
+}
@@ -0,0 +1,218 @@
+package chaincode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// newGovernor returns the identity of a governor of organization mspID
+func newGovernor(cn string, mspID string) *mockIdentity {
+	governor := newIdentity(cn, mspID)
+	governor.attributes[governorAttribute] = "true"
+	return governor
+}
+
+// newGovernedToken returns a stub holding a token whose admin minted supply tokens
+// and then handed minting over to governance by Org1, Org2 and Org3, two of which must approve
+func newGovernedToken(t *testing.T, admin *mockIdentity, supply int) *mockStub {
+	t.Helper()
+
+	contract := &SmartContract{}
+	stub := newToken(t, admin, supply)
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.InitializeGovernance(ctx, []string{"Org1MSP", "Org2MSP", "Org3MSP"}, 2)
+	})
+	return stub
+}
+
+func TestInitializeGovernance(t *testing.T) {
+	contract := &SmartContract{}
+	admin := newIdentity("admin", "Org1MSP")
+	stub := newToken(t, admin, 0)
+
+	tests := []struct {
+		name      string
+		orgs      []string
+		threshold int
+	}{
+		{name: "threshold above orgs", orgs: []string{"Org1MSP", "Org2MSP"}, threshold: 3},
+		{name: "zero threshold", orgs: []string{"Org1MSP", "Org2MSP"}, threshold: 0},
+		{name: "repeated org", orgs: []string{"Org1MSP", "Org1MSP"}, threshold: 2},
+	}
+	for _, test := range tests {
+		err := invoke(stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+			return contract.InitializeGovernance(ctx, test.orgs, test.threshold)
+		})
+		assertCode(t, err, invalidArgumentCode)
+	}
+
+	err := invoke(stub, newIdentity("bob", "Org2MSP"), func(ctx contractapi.TransactionContextInterface) error {
+		return contract.InitializeGovernance(ctx, []string{"Org1MSP", "Org2MSP"}, 2)
+	})
+	assertCode(t, err, unauthorizedCode)
+
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.InitializeGovernance(ctx, []string{"Org1MSP", "Org2MSP"}, 2)
+	})
+	err = invoke(stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.InitializeGovernance(ctx, []string{"Org1MSP"}, 1)
+	})
+	assertCode(t, err, invalidArgumentCode)
+
+	// The admin can no longer mint on its own
+	err = invoke(stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.Mint(ctx, 10)
+	})
+	assertCode(t, err, unauthorizedCode)
+}
+
+func TestProposeMint(t *testing.T) {
+	contract := &SmartContract{}
+	admin := newIdentity("admin", "Org1MSP")
+	governor1 := newGovernor("governor1", "Org1MSP")
+	otherGovernor1 := newGovernor("governor1b", "Org1MSP")
+	governor2 := newGovernor("governor2", "Org2MSP")
+	bob := newIdentity("bob", "Org2MSP")
+	stub := newGovernedToken(t, admin, 100)
+	expiration := stub.timestamp.Add(time.Hour).Unix()
+
+	proposeMint := func(identity *mockIdentity, id string) error {
+		return invoke(stub, identity, func(ctx contractapi.TransactionContextInterface) error {
+			return contract.ProposeMint(ctx, id, bob.id, 50, expiration)
+		})
+	}
+
+	assertCode(t, proposeMint(bob, "op1"), unauthorizedCode)
+	assertCode(t, proposeMint(newGovernor("outsider", "Org4MSP"), "op1"), unauthorizedCode)
+
+	if err := proposeMint(governor1, "op1"); err != nil {
+		t.Fatalf("ProposeMint failed: %v", err)
+	}
+	assertCode(t, proposeMint(governor1, "op1"), invalidArgumentCode)
+
+	// A second governor of the same organization does not count towards the threshold
+	err := invoke(stub, otherGovernor1, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.ApproveOperation(ctx, "op1")
+	})
+	assertCode(t, err, invalidArgumentCode)
+	if balance := balanceOf(t, stub, bob.id); balance != 0 {
+		t.Fatalf("got bob balance %d before approval, want 0", balance)
+	}
+
+	mustInvoke(t, stub, governor2, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.ApproveOperation(ctx, "op1")
+	})
+	if stub.event == nil || stub.event.name != "OperationExecuted" {
+		t.Fatalf("got event %v, want an OperationExecuted event", stub.event)
+	}
+	if balance := balanceOf(t, stub, bob.id); balance != 50 {
+		t.Fatalf("got bob balance %d, want 50", balance)
+	}
+	mustInvoke(t, stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		supply, err := contract.TotalSupply(ctx)
+		if supply != 150 {
+			t.Fatalf("got total supply %d, want 150", supply)
+		}
+		return err
+	})
+
+	err = invoke(stub, newGovernor("governor3", "Org3MSP"), func(ctx contractapi.TransactionContextInterface) error {
+		return contract.ApproveOperation(ctx, "op1")
+	})
+	assertCode(t, err, invalidArgumentCode)
+
+	// An operation cannot be approved once it expired
+	if err := proposeMint(governor1, "op2"); err != nil {
+		t.Fatalf("ProposeMint failed: %v", err)
+	}
+	stub.timestamp = stub.timestamp.Add(2 * time.Hour)
+	err = invoke(stub, governor2, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.ApproveOperation(ctx, "op2")
+	})
+	assertCode(t, err, invalidArgumentCode)
+	mustInvoke(t, stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		operations, err := contract.GetPendingOperations(ctx)
+		if len(operations) != 0 {
+			t.Fatalf("got %d pending operations, want none", len(operations))
+		}
+		return err
+	})
+}
+
+func TestRejectOperation(t *testing.T) {
+	contract := &SmartContract{}
+	admin := newIdentity("admin", "Org1MSP")
+	governor1 := newGovernor("governor1", "Org1MSP")
+	governor2 := newGovernor("governor2", "Org2MSP")
+	governor3 := newGovernor("governor3", "Org3MSP")
+	bob := newIdentity("bob", "Org2MSP")
+	stub := newGovernedToken(t, admin, 100)
+	expiration := stub.timestamp.Add(time.Hour).Unix()
+
+	mustInvoke(t, stub, governor1, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.ProposeRoleChange(ctx, "op1", burnerRole, bob.id, true, expiration)
+	})
+	mustInvoke(t, stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		operations, err := contract.GetPendingOperations(ctx)
+		if len(operations) != 1 || operations[0].ID != "op1" {
+			t.Fatalf("got pending operations %v, want op1", operations)
+		}
+		return err
+	})
+
+	// One rejection leaves two organizations, still enough to reach the threshold; two do not
+	mustInvoke(t, stub, governor2, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.RejectOperation(ctx, "op1")
+	})
+	mustInvoke(t, stub, governor3, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.RejectOperation(ctx, "op1")
+	})
+	mustInvoke(t, stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		operation, err := contract.ReadOperation(ctx, "op1")
+		if operation.Status != operationRejected {
+			t.Fatalf("got status %s, want %s", operation.Status, operationRejected)
+		}
+		granted, _ := contract.HasRole(ctx, burnerRole, bob.id)
+		if granted {
+			t.Fatalf("rejected role change was applied")
+		}
+		return err
+	})
+}
+
+func TestRoleChange(t *testing.T) {
+	contract := &SmartContract{}
+	admin := newIdentity("admin", "Org1MSP")
+	governor1 := newGovernor("governor1", "Org1MSP")
+	governor2 := newGovernor("governor2", "Org2MSP")
+	stub := newGovernedToken(t, admin, 100)
+	expiration := stub.timestamp.Add(time.Hour).Unix()
+
+	err := invoke(stub, governor1, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.ProposeRoleChange(ctx, "op1", "superuser", admin.id, true, expiration)
+	})
+	assertCode(t, err, invalidArgumentCode)
+
+	// Once governed, burning requires the burner role
+	burn := func() error {
+		return invoke(stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+			return contract.Burn(ctx, 10)
+		})
+	}
+	assertCode(t, burn(), unauthorizedCode)
+
+	mustInvoke(t, stub, governor1, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.ProposeRoleChange(ctx, "op1", burnerRole, admin.id, true, expiration)
+	})
+	mustInvoke(t, stub, governor2, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.ApproveOperation(ctx, "op1")
+	})
+	if err := burn(); err != nil {
+		t.Fatalf("Burn failed: %v", err)
+	}
+	if balance := balanceOf(t, stub, admin.id); balance != 90 {
+		t.Fatalf("got admin balance %d, want 90", balance)
+	}
+}
@@ -0,0 +1,337 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+const (
+	holdPrefix = "hold"
+	heldPrefix = "held"
+)
+
+// Statuses of a hold
+const (
+	holdOrdered  = "ordered"
+	holdExecuted = "executed"
+	holdReleased = "released"
+)
+
+// Hold reserves Amount tokens of Owner for Recipient until Notary executes or releases it.
+// The tokens stay in the balance of Owner but cannot be spent until the hold is released.
+// Expiration is in seconds since the Unix epoch.
+type Hold struct {
+	ID         string `json:"id"`
+	Owner      string `json:"owner"`
+	Recipient  string `json:"recipient"`
+	Notary     string `json:"notary"`
+	Amount     int    `json:"amount"`
+	Expiration int64  `json:"expiration"`
+	Status     string `json:"status"`
+}
+
+// BalanceDetails splits the balance of an account into the tokens reserved by holds and the available ones
+type BalanceDetails struct {
+	Balance   int `json:"balance"`
+	Held      int `json:"held"`
+	Available int `json:"available"`
+}
+
+// heldKey builds the key of the total amount of tokens of account reserved by holds
+func heldKey(ctx contractapi.TransactionContextInterface, account string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(heldPrefix, []string{account})
+	if err != nil {
+		return "", fmt.Errorf("failed to create held key: %v", err)
+	}
+
+	return key, nil
+}
+
+// readHeld returns the total amount of tokens of account reserved by holds
+func readHeld(ctx contractapi.TransactionContextInterface, account string) (int, error) {
+	key, err := heldKey(ctx, account)
+	if err != nil {
+		return 0, err
+	}
+
+	return readInt(ctx, key)
+}
+
+// writeHeld sets the total amount of tokens of account reserved by holds
+func writeHeld(ctx contractapi.TransactionContextInterface, account string, held int) error {
+	key, err := heldKey(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	return writeInt(ctx, key, held)
+}
+
+// holdKey builds the key of the hold with the given ID
+func holdKey(ctx contractapi.TransactionContextInterface, id string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(holdPrefix, []string{id})
+	if err != nil {
+		return "", fmt.Errorf("failed to create hold key: %v", err)
+	}
+
+	return key, nil
+}
+
+// readHold returns the hold with the given ID
+func readHold(ctx contractapi.TransactionContextInterface, id string) (*Hold, error) {
+	key, err := holdKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	holdJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if holdJSON == nil {
+		return nil, codedError(notFoundCode, "hold %s does not exist", id)
+	}
+
+	var hold Hold
+	err = json.Unmarshal(holdJSON, &hold)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal hold %s: %v", id, err)
+	}
+
+	return &hold, nil
+}
+
+// putHold writes hold to the world state
+func putHold(ctx contractapi.TransactionContextInterface, hold *Hold) error {
+	key, err := holdKey(ctx, hold.ID)
+	if err != nil {
+		return err
+	}
+
+	holdJSON, err := json.Marshal(hold)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	err = ctx.GetStub().PutState(key, holdJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put hold %s into world state: %v", hold.ID, err)
+	}
+
+	return nil
+}
+
+// readOrderedHold returns the hold with the given ID, failing unless it is still ordered
+func readOrderedHold(ctx contractapi.TransactionContextInterface, id string) (*Hold, error) {
+	hold, err := readHold(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if hold.Status != holdOrdered {
+		return nil, codedError(invalidArgumentCode, "hold %s is already %s", id, hold.Status)
+	}
+
+	return hold, nil
+}
+
+// PlaceHold reserves amount tokens of the client for recipient until notary executes the hold,
+// or releases it, or it expires at expiration, in seconds since the Unix epoch
+func (s *SmartContract) PlaceHold(ctx contractapi.TransactionContextInterface, id string, recipient string, notary string, amount int, expiration int64) error {
+	if err := checkInitialized(ctx); err != nil {
+		return err
+	}
+	if id == "" || recipient == "" || notary == "" {
+		return codedError(invalidArgumentCode, "hold ID, recipient and notary must not be empty")
+	}
+	if err := checkAmount(amount); err != nil {
+		return err
+	}
+
+	owner, err := getClientAccountID(ctx)
+	if err != nil {
+		return err
+	}
+	if recipient == owner {
+		return codedError(invalidArgumentCode, "cannot hold tokens for their own owner")
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+	if expiration <= now {
+		return codedError(invalidArgumentCode, "hold expiration must be in the future")
+	}
+
+	key, err := holdKey(ctx, id)
+	if err != nil {
+		return err
+	}
+	existing, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if existing != nil {
+		return codedError(invalidArgumentCode, "hold %s already exists", id)
+	}
+
+	balance, err := readBalance(ctx, owner)
+	if err != nil {
+		return err
+	}
+	held, err := readHeld(ctx, owner)
+	if err != nil {
+		return err
+	}
+	if balance-held < amount {
+		return codedError(insufficientFundsCode, "account %s has insufficient funds", owner)
+	}
+
+	err = writeHeld(ctx, owner, held+amount)
+	if err != nil {
+		return err
+	}
+
+	hold := &Hold{
+		ID:         id,
+		Owner:      owner,
+		Recipient:  recipient,
+		Notary:     notary,
+		Amount:     amount,
+		Expiration: expiration,
+		Status:     holdOrdered,
+	}
+	err = putHold(ctx, hold)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, "HoldPlaced", hold)
+}
+
+// ExecuteHold transfers the tokens of a hold to its recipient.
+// Only the notary of the hold may execute it, and only before it expires.
+func (s *SmartContract) ExecuteHold(ctx contractapi.TransactionContextInterface, id string) error {
+	if err := checkInitialized(ctx); err != nil {
+		return err
+	}
+
+	hold, err := readOrderedHold(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	clientID, err := getClientAccountID(ctx)
+	if err != nil {
+		return err
+	}
+	if clientID != hold.Notary {
+		return codedError(unauthorizedCode, "client is not the notary of hold %s", id)
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+	if now >= hold.Expiration {
+		return codedError(invalidArgumentCode, "hold %s has expired", id)
+	}
+
+	// The held tokens are part of the balance of the owner but not of its available balance,
+	// so spend them without going through debit
+	held, err := readHeld(ctx, hold.Owner)
+	if err != nil {
+		return err
+	}
+	err = withdraw(ctx, hold.Owner, hold.Amount)
+	if err != nil {
+		return err
+	}
+	err = writeHeld(ctx, hold.Owner, held-hold.Amount)
+	if err != nil {
+		return err
+	}
+	err = credit(ctx, hold.Recipient, hold.Amount)
+	if err != nil {
+		return err
+	}
+
+	hold.Status = holdExecuted
+	err = putHold(ctx, hold)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, "HoldExecuted", hold)
+}
+
+// ReleaseHold returns the tokens of a hold to the available balance of its owner.
+// The notary may release a hold at any time, any client once it has expired.
+func (s *SmartContract) ReleaseHold(ctx contractapi.TransactionContextInterface, id string) error {
+	if err := checkInitialized(ctx); err != nil {
+		return err
+	}
+
+	hold, err := readOrderedHold(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	clientID, err := getClientAccountID(ctx)
+	if err != nil {
+		return err
+	}
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+	if clientID != hold.Notary && now < hold.Expiration {
+		return codedError(unauthorizedCode, "client is not the notary of hold %s, which has not expired", id)
+	}
+
+	held, err := readHeld(ctx, hold.Owner)
+	if err != nil {
+		return err
+	}
+	err = writeHeld(ctx, hold.Owner, held-hold.Amount)
+	if err != nil {
+		return err
+	}
+
+	hold.Status = holdReleased
+	err = putHold(ctx, hold)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, "HoldReleased", hold)
+}
+
+// ReadHold returns the hold with the given ID
+func (s *SmartContract) ReadHold(ctx contractapi.TransactionContextInterface, id string) (*Hold, error) {
+	if err := checkInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	return readHold(ctx, id)
+}
+
+// BalanceDetailsOf returns the balance of account split into held and available tokens
+func (s *SmartContract) BalanceDetailsOf(ctx contractapi.TransactionContextInterface, account string) (*BalanceDetails, error) {
+	if err := checkInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	balance, err := readBalance(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	held, err := readHeld(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BalanceDetails{Balance: balance, Held: held, Available: balance - held}, nil
+}
@@ -0,0 +1,110 @@
+package chaincode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+func TestHoldLifecycle(t *testing.T) {
+	contract := &SmartContract{}
+	admin := newIdentity("admin", "Org1MSP")
+	bob := newIdentity("bob", "Org2MSP")
+	notary := newIdentity("notary", "Org2MSP")
+	stub := newToken(t, admin, 100)
+	expiration := stub.timestamp.Add(time.Hour).Unix()
+
+	err := invoke(stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.PlaceHold(ctx, "h1", bob.id, notary.id, 101, expiration)
+	})
+	assertCode(t, err, insufficientFundsCode)
+
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.PlaceHold(ctx, "h1", bob.id, notary.id, 60, expiration)
+	})
+
+	// Held tokens cannot be spent, nor held twice
+	err = invoke(stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.Transfer(ctx, bob.id, 41)
+		return err
+	})
+	assertCode(t, err, insufficientFundsCode)
+	err = invoke(stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.PlaceHold(ctx, "h2", bob.id, notary.id, 41, expiration)
+	})
+	assertCode(t, err, insufficientFundsCode)
+
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		details, err := contract.BalanceDetailsOf(ctx, admin.id)
+		if *details != (BalanceDetails{Balance: 100, Held: 60, Available: 40}) {
+			t.Fatalf("got balance details %+v", details)
+		}
+		return err
+	})
+
+	err = invoke(stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.ExecuteHold(ctx, "h1")
+	})
+	assertCode(t, err, unauthorizedCode)
+
+	mustInvoke(t, stub, notary, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.ExecuteHold(ctx, "h1")
+	})
+	if balance := balanceOf(t, stub, bob.id); balance != 60 {
+		t.Fatalf("got bob balance %d, want 60", balance)
+	}
+
+	err = invoke(stub, notary, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.ReleaseHold(ctx, "h1")
+	})
+	assertCode(t, err, invalidArgumentCode)
+
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		details, err := contract.BalanceDetailsOf(ctx, admin.id)
+		if *details != (BalanceDetails{Balance: 40, Held: 0, Available: 40}) {
+			t.Fatalf("got balance details %+v", details)
+		}
+		return err
+	})
+}
+
+func TestReleaseExpiredHold(t *testing.T) {
+	contract := &SmartContract{}
+	admin := newIdentity("admin", "Org1MSP")
+	bob := newIdentity("bob", "Org2MSP")
+	notary := newIdentity("notary", "Org2MSP")
+	stub := newToken(t, admin, 100)
+	expiration := stub.timestamp.Add(time.Hour).Unix()
+
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.PlaceHold(ctx, "h1", bob.id, notary.id, 60, expiration)
+	})
+
+	err := invoke(stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.ReleaseHold(ctx, "h1")
+	})
+	assertCode(t, err, unauthorizedCode)
+
+	stub.timestamp = stub.timestamp.Add(time.Hour)
+	err = invoke(stub, notary, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.ExecuteHold(ctx, "h1")
+	})
+	assertCode(t, err, invalidArgumentCode)
+
+	mustInvoke(t, stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.ReleaseHold(ctx, "h1")
+	})
+
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.Transfer(ctx, bob.id, 100)
+		return err
+	})
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		hold, err := contract.ReadHold(ctx, "h1")
+		if hold.Status != holdReleased {
+			t.Fatalf("got hold status %s, want %s", hold.Status, holdReleased)
+		}
+		return err
+	})
+}
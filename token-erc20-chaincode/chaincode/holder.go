@@ -0,0 +1,195 @@
+package chaincode
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// holderPrefix is the object type of the ("holder", account) index of the accounts holding tokens
+const holderPrefix = "holder"
+
+// maxHolderPageSize caps the number of holders returned by one ListHolders call
+const maxHolderPageSize = 1000
+
+// Holder is an account holding tokens and its balance
+type Holder struct {
+	Account string `json:"account"`
+	Balance int    `json:"balance"`
+}
+
+// HolderPage is a page of holders. Bookmark is passed to ListHolders to fetch the next page
+// and is empty once the last page is returned.
+type HolderPage struct {
+	Holders  []*Holder `json:"holders"`
+	Bookmark string    `json:"bookmark"`
+}
+
+// holderKey builds the key of account in the holder index
+func holderKey(ctx contractapi.TransactionContextInterface, account string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(holderPrefix, []string{account})
+	if err != nil {
+		return "", fmt.Errorf("failed to create holder key: %v", err)
+	}
+
+	return key, nil
+}
+
+// indexHolder adds account to the holder index if it holds tokens and removes it otherwise.
+// The write is blind, so that credits in the UTXO model still read nothing.
+func indexHolder(ctx contractapi.TransactionContextInterface, account string, holds bool) error {
+	key, err := holderKey(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	if holds {
+		err = ctx.GetStub().PutState(key, []byte{0x00})
+	} else {
+		err = ctx.GetStub().DelState(key)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update holder index of %s: %v", account, err)
+	}
+
+	return nil
+}
+
+// ListHolders returns a page of at most pageSize accounts holding tokens, with their balances,
+// starting at bookmark, empty for the first page
+func (s *SmartContract) ListHolders(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*HolderPage, error) {
+	if err := checkInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if pageSize <= 0 {
+		return nil, codedError(invalidArgumentCode, "page size must be a positive integer")
+	}
+	if pageSize > maxHolderPageSize {
+		pageSize = maxHolderPageSize
+	}
+
+	resultsIterator, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(holderPrefix, []string{}, pageSize, bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read holder index: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	page := &HolderPage{Holders: []*Holder{}, Bookmark: metadata.GetBookmark()}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split holder index key: %v", err)
+		}
+
+		balance, err := readBalance(ctx, attributes[0])
+		if err != nil {
+			return nil, err
+		}
+		page.Holders = append(page.Holders, &Holder{Account: attributes[0], Balance: balance})
+	}
+
+	return page, nil
+}
+
+// HolderCount returns the number of accounts holding tokens
+func (s *SmartContract) HolderCount(ctx contractapi.TransactionContextInterface) (int, error) {
+	if err := checkInitialized(ctx); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	bookmark := ""
+	for {
+		resultsIterator, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(holderPrefix, []string{}, maxHolderPageSize, bookmark)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read holder index: %v", err)
+		}
+
+		for resultsIterator.HasNext() {
+			_, err := resultsIterator.Next()
+			if err != nil {
+				resultsIterator.Close()
+				return 0, err
+			}
+			count++
+		}
+		resultsIterator.Close()
+
+		bookmark = metadata.GetBookmark()
+		if bookmark == "" || metadata.GetFetchedRecordsCount() < maxHolderPageSize {
+			return count, nil
+		}
+	}
+}
+
+// RebuildHolderIndex indexes the accounts that received tokens before the holder index existed.
+// Only the admin organization may rebuild the index.
+func (s *SmartContract) RebuildHolderIndex(ctx contractapi.TransactionContextInterface) (int, error) {
+	if err := checkInitialized(ctx); err != nil {
+		return 0, err
+	}
+	if err := checkAdmin(ctx); err != nil {
+		return 0, err
+	}
+
+	utxo, err := isUTXOModel(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	holders := map[string]bool{}
+	if utxo {
+		resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(outputPrefix, []string{})
+		if err != nil {
+			return 0, fmt.Errorf("failed to get outputs: %v", err)
+		}
+		defer resultsIterator.Close()
+
+		for resultsIterator.HasNext() {
+			queryResponse, err := resultsIterator.Next()
+			if err != nil {
+				return 0, err
+			}
+
+			_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+			if err != nil {
+				return 0, fmt.Errorf("failed to split output key: %v", err)
+			}
+			holders[attributes[0]] = true
+		}
+	} else {
+		// An empty range covers the simple keys only, which are the metadata and the balances
+		resultsIterator, err := ctx.GetStub().GetStateByRange("", "")
+		if err != nil {
+			return 0, fmt.Errorf("failed to get balances: %v", err)
+		}
+		defer resultsIterator.Close()
+
+		metadata := map[string]bool{nameKey: true, symbolKey: true, decimalsKey: true, totalSupplyKey: true}
+		for resultsIterator.HasNext() {
+			queryResponse, err := resultsIterator.Next()
+			if err != nil {
+				return 0, err
+			}
+			if metadata[queryResponse.Key] || strings.HasPrefix(queryResponse.Key, "\x00") {
+				continue
+			}
+			holders[queryResponse.Key] = true
+		}
+	}
+
+	for account := range holders {
+		err = indexHolder(ctx, account, true)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(holders), nil
+}
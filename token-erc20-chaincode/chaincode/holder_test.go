@@ -0,0 +1,124 @@
+package chaincode
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// listHolders pages through the holder index with pages of pageSize and returns every holder
+func listHolders(t *testing.T, stub *mockStub, pageSize int32) map[string]int {
+	t.Helper()
+
+	contract := &SmartContract{}
+	holders := map[string]int{}
+	bookmark := ""
+	for {
+		var page *HolderPage
+		mustInvoke(t, stub, newIdentity("reader", "Org2MSP"), func(ctx contractapi.TransactionContextInterface) error {
+			var err error
+			page, err = contract.ListHolders(ctx, pageSize, bookmark)
+			return err
+		})
+		if len(page.Holders) > int(pageSize) {
+			t.Fatalf("got %d holders in a page of %d", len(page.Holders), pageSize)
+		}
+		for _, holder := range page.Holders {
+			holders[holder.Account] = holder.Balance
+		}
+
+		bookmark = page.Bookmark
+		if bookmark == "" {
+			return holders
+		}
+	}
+}
+
+// holderCount returns the number of holders counted by HolderCount
+func holderCount(t *testing.T, stub *mockStub) int {
+	t.Helper()
+
+	contract := &SmartContract{}
+	var count int
+	mustInvoke(t, stub, newIdentity("reader", "Org2MSP"), func(ctx contractapi.TransactionContextInterface) error {
+		var err error
+		count, err = contract.HolderCount(ctx)
+		return err
+	})
+	return count
+}
+
+func TestHolderIndex(t *testing.T) {
+	for _, model := range []string{accountModel, utxoModel} {
+		t.Run(model, func(t *testing.T) {
+			contract := &SmartContract{}
+			admin := newIdentity("admin", "Org1MSP")
+			payers := newPayers(4)
+			stub := newModelToken(t, model, admin, payers)
+
+			if count := holderCount(t, stub); count != 4 {
+				t.Fatalf("got %d holders, want 4", count)
+			}
+
+			// Spending the whole balance removes the account from the index
+			mustInvoke(t, stub, payers[0], func(ctx contractapi.TransactionContextInterface) error {
+				_, err := contract.Transfer(ctx, payers[1].id, 100)
+				return err
+			})
+			mustInvoke(t, stub, payers[2], func(ctx contractapi.TransactionContextInterface) error {
+				_, err := contract.Transfer(ctx, admin.id, 30)
+				return err
+			})
+
+			holders := listHolders(t, stub, 2)
+			want := map[string]int{payers[1].id: 200, payers[2].id: 70, payers[3].id: 100, admin.id: 30}
+			if len(holders) != len(want) {
+				t.Fatalf("got holders %v, want %v", holders, want)
+			}
+			for account, balance := range want {
+				if holders[account] != balance {
+					t.Fatalf("got balance %d for %s, want %d", holders[account], account, balance)
+				}
+			}
+			if count := holderCount(t, stub); count != 4 {
+				t.Fatalf("got %d holders, want 4", count)
+			}
+
+			err := invoke(stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+				_, err := contract.ListHolders(ctx, 0, "")
+				return err
+			})
+			assertCode(t, err, invalidArgumentCode)
+		})
+	}
+}
+
+func TestRebuildHolderIndex(t *testing.T) {
+	contract := &SmartContract{}
+	admin := newIdentity("admin", "Org1MSP")
+	alice := newIdentity("alice", "Org2MSP")
+	stub := newToken(t, admin, 100)
+
+	// A balance written before the holder index existed
+	stub.state[alice.id] = []byte("50")
+	if count := holderCount(t, stub); count != 1 {
+		t.Fatalf("got %d holders before rebuilding, want 1", count)
+	}
+
+	err := invoke(stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.RebuildHolderIndex(ctx)
+		return err
+	})
+	assertCode(t, err, unauthorizedCode)
+
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		indexed, err := contract.RebuildHolderIndex(ctx)
+		if indexed != 2 {
+			t.Fatalf("got %d holders indexed, want 2", indexed)
+		}
+		return err
+	})
+	if holders := listHolders(t, stub, 10); holders[alice.id] != 50 || holders[admin.id] != 100 {
+		t.Fatalf("got holders %v after rebuilding", holders)
+	}
+}
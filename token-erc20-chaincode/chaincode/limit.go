@@ -0,0 +1,242 @@
+package chaincode
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+const (
+	limitPrefix        = "limit"
+	defaultLimitPrefix = "defaultlimit"
+	spentPrefix        = "spent"
+)
+
+// Amounts sent are tracked in buckets of one hour, and the limit applies to the buckets of the last day
+const (
+	bucketSeconds = 3600
+	limitBuckets  = 24
+)
+
+// TransferLimit is the most an account may send over a rolling day and how much of it is used.
+// A Limit of zero means the account is not limited.
+type TransferLimit struct {
+	Account   string `json:"account"`
+	Limit     int    `json:"limit"`
+	Spent     int    `json:"spent"`
+	Remaining int    `json:"remaining"`
+}
+
+// LimitChange is emitted when the limit of an account, or the default limit when Account is empty, changes
+type LimitChange struct {
+	Account string `json:"account,omitempty"`
+	Limit   int    `json:"limit"`
+}
+
+// limitKey builds the key of the limit of account, or of the default limit when account is empty
+func limitKey(ctx contractapi.TransactionContextInterface, account string) (string, error) {
+	var key string
+	var err error
+	if account == "" {
+		key, err = ctx.GetStub().CreateCompositeKey(defaultLimitPrefix, []string{})
+	} else {
+		key, err = ctx.GetStub().CreateCompositeKey(limitPrefix, []string{account})
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to create limit key: %v", err)
+	}
+
+	return key, nil
+}
+
+// readLimit returns the limit of account: its own limit if one is set, else the default limit
+func readLimit(ctx contractapi.TransactionContextInterface, account string) (int, error) {
+	key, err := limitKey(ctx, account)
+	if err != nil {
+		return 0, err
+	}
+	limit, err := readInt(ctx, key)
+	if err != nil || limit != 0 {
+		return limit, err
+	}
+
+	key, err = limitKey(ctx, "")
+	if err != nil {
+		return 0, err
+	}
+
+	return readInt(ctx, key)
+}
+
+// currentBucket returns the number of the hour bucket of the transaction
+func currentBucket(ctx contractapi.TransactionContextInterface) (int64, error) {
+	now, err := txTime(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return now / bucketSeconds, nil
+}
+
+// spentKey builds the key of the amount account sent during the hour bucket
+func spentKey(ctx contractapi.TransactionContextInterface, account string, bucket int64) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(spentPrefix, []string{account, strconv.FormatInt(bucket, 10)})
+	if err != nil {
+		return "", fmt.Errorf("failed to create spent key: %v", err)
+	}
+
+	return key, nil
+}
+
+// readSpent returns the amount account sent during the last day, with the keys of the buckets older than that
+func readSpent(ctx contractapi.TransactionContextInterface, account string, bucket int64) (int, []string, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(spentPrefix, []string{account})
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to get spent amounts of %s: %v", account, err)
+	}
+	defer resultsIterator.Close()
+
+	spent := 0
+	var expired []string
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to split spent key: %v", err)
+		}
+		spentBucket, err := strconv.ParseInt(attributes[1], 10, 64)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to parse bucket of %s: %v", queryResponse.Key, err)
+		}
+		if spentBucket <= bucket-limitBuckets {
+			expired = append(expired, queryResponse.Key)
+			continue
+		}
+
+		amount, err := strconv.Atoi(string(queryResponse.Value))
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to parse spent amount %s: %v", queryResponse.Key, err)
+		}
+		spent, err = add(spent, amount)
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+
+	return spent, expired, nil
+}
+
+// spend records that account sends amount tokens, failing if that exceeds its limit over the last day.
+// Buckets older than a day are removed as they no longer count.
+func spend(ctx contractapi.TransactionContextInterface, account string, amount int) error {
+	limit, err := readLimit(ctx, account)
+	if err != nil || limit == 0 {
+		return err
+	}
+
+	bucket, err := currentBucket(ctx)
+	if err != nil {
+		return err
+	}
+	spent, expired, err := readSpent(ctx, account, bucket)
+	if err != nil {
+		return err
+	}
+	total, err := add(spent, amount)
+	if err != nil {
+		return err
+	}
+	if total > limit {
+		return codedError(transferLimitExceededCode, "sending %d tokens exceeds the daily limit of %d of account %s, %d remaining", amount, limit, account, limit-spent)
+	}
+
+	for _, key := range expired {
+		err = ctx.GetStub().DelState(key)
+		if err != nil {
+			return fmt.Errorf("failed to delete spent amount %s: %v", key, err)
+		}
+	}
+
+	key, err := spentKey(ctx, account, bucket)
+	if err != nil {
+		return err
+	}
+	bucketSpent, err := readInt(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	return writeInt(ctx, key, bucketSpent+amount)
+}
+
+// setLimit sets the limit of account, or the default limit when account is empty
+func setLimit(ctx contractapi.TransactionContextInterface, account string, limit int) error {
+	if err := checkInitialized(ctx); err != nil {
+		return err
+	}
+	if err := checkAdmin(ctx); err != nil {
+		return err
+	}
+	if limit < 0 {
+		return codedError(invalidArgumentCode, "limit must not be negative")
+	}
+
+	key, err := limitKey(ctx, account)
+	if err != nil {
+		return err
+	}
+	err = writeInt(ctx, key, limit)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, "LimitChanged", LimitChange{Account: account, Limit: limit})
+}
+
+// SetDefaultTransferLimit sets the most an account without its own limit may send over a rolling day.
+// Zero lifts the default limit. Only the admin organization may set limits.
+func (s *SmartContract) SetDefaultTransferLimit(ctx contractapi.TransactionContextInterface, limit int) error {
+	return setLimit(ctx, "", limit)
+}
+
+// SetTransferLimit sets the most account may send over a rolling day, overriding the default limit.
+// Zero removes the limit of the account, which falls back to the default. Only the admin organization may set limits.
+func (s *SmartContract) SetTransferLimit(ctx contractapi.TransactionContextInterface, account string, limit int) error {
+	if account == "" {
+		return codedError(invalidArgumentCode, "account must not be empty")
+	}
+
+	return setLimit(ctx, account, limit)
+}
+
+// GetTransferLimit returns the limit of account and how much of it was used over the last day
+func (s *SmartContract) GetTransferLimit(ctx contractapi.TransactionContextInterface, account string) (*TransferLimit, error) {
+	if err := checkInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	limit, err := readLimit(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	bucket, err := currentBucket(ctx)
+	if err != nil {
+		return nil, err
+	}
+	spent, _, err := readSpent(ctx, account, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := 0
+	if limit > spent {
+		remaining = limit - spent
+	}
+
+	return &TransferLimit{Account: account, Limit: limit, Spent: spent, Remaining: remaining}, nil
+}
@@ -0,0 +1,82 @@
+package chaincode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+func TestTransferLimit(t *testing.T) {
+	contract := &SmartContract{}
+	admin := newIdentity("admin", "Org1MSP")
+	alice := newIdentity("alice", "Org2MSP")
+	bob := newIdentity("bob", "Org2MSP")
+	stub := newToken(t, admin, 1000)
+
+	transfer := func(from *mockIdentity, to *mockIdentity, amount int) error {
+		return invoke(stub, from, func(ctx contractapi.TransactionContextInterface) error {
+			_, err := contract.Transfer(ctx, to.id, amount)
+			return err
+		})
+	}
+	usage := func(account string) *TransferLimit {
+		var limit *TransferLimit
+		mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+			var err error
+			limit, err = contract.GetTransferLimit(ctx, account)
+			return err
+		})
+		return limit
+	}
+
+	err := invoke(stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.SetDefaultTransferLimit(ctx, 100)
+	})
+	assertCode(t, err, unauthorizedCode)
+
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.SetDefaultTransferLimit(ctx, 100)
+	})
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.SetTransferLimit(ctx, admin.id, 500)
+	})
+
+	if err := transfer(admin, alice, 400); err != nil {
+		t.Fatalf("Transfer failed: %v", err)
+	}
+	assertCode(t, transfer(admin, alice, 101), transferLimitExceededCode)
+
+	// Alice falls back to the default limit, spread over several hours of the same day
+	if err := transfer(alice, bob, 60); err != nil {
+		t.Fatalf("Transfer failed: %v", err)
+	}
+	stub.timestamp = stub.timestamp.Add(5 * time.Hour)
+	assertCode(t, transfer(alice, bob, 41), transferLimitExceededCode)
+	if err := transfer(alice, bob, 40); err != nil {
+		t.Fatalf("Transfer failed: %v", err)
+	}
+	if limit := usage(alice.id); limit.Limit != 100 || limit.Spent != 100 || limit.Remaining != 0 {
+		t.Fatalf("got limit %+v, want 100 spent of 100", limit)
+	}
+
+	// A day after the first transfer its amount no longer counts
+	stub.timestamp = stub.timestamp.Add(19 * time.Hour)
+	if limit := usage(alice.id); limit.Spent != 40 {
+		t.Fatalf("got %d spent a day later, want 40", limit.Spent)
+	}
+	if err := transfer(alice, bob, 60); err != nil {
+		t.Fatalf("Transfer failed: %v", err)
+	}
+
+	// Lifting the default limit frees the accounts without a limit of their own
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.SetDefaultTransferLimit(ctx, 0)
+	})
+	if err := transfer(alice, bob, 200); err != nil {
+		t.Fatalf("Transfer failed: %v", err)
+	}
+	if limit := usage(alice.id); limit.Limit != 0 {
+		t.Fatalf("got limit %d, want none", limit.Limit)
+	}
+}
@@ -0,0 +1,237 @@
+package chaincode
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+const (
+	publicKeyPrefix = "publickey"
+	noncePrefix     = "nonce"
+)
+
+// PermitPayload is the message an owner signs to let spender transfer up to Value of its tokens.
+// Its JSON encoding, with the fields in this order, is what is signed. Nonce is the current nonce
+// of the owner, and Channel and Chaincode bind the permit to one deployment of the token.
+type PermitPayload struct {
+	Owner     string `json:"owner"`
+	Spender   string `json:"spender"`
+	Value     int    `json:"value"`
+	Nonce     int    `json:"nonce"`
+	Deadline  int64  `json:"deadline"`
+	Channel   string `json:"channel"`
+	Chaincode string `json:"chaincode"`
+}
+
+// publicKeyKey builds the key of the public key registered by account
+func publicKeyKey(ctx contractapi.TransactionContextInterface, account string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(publicKeyPrefix, []string{account})
+	if err != nil {
+		return "", fmt.Errorf("failed to create public key key: %v", err)
+	}
+
+	return key, nil
+}
+
+// nonceKey builds the key of the number of permits of owner used so far
+func nonceKey(ctx contractapi.TransactionContextInterface, owner string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(noncePrefix, []string{owner})
+	if err != nil {
+		return "", fmt.Errorf("failed to create nonce key: %v", err)
+	}
+
+	return key, nil
+}
+
+// parsePublicKey parses a PEM encoded ECDSA P-256 public key
+func parsePublicKey(publicKeyPEM string) (*ecdsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicKeyPEM))
+	if block == nil {
+		return nil, codedError(invalidArgumentCode, "public key is not PEM encoded")
+	}
+
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, codedError(invalidArgumentCode, "failed to parse public key: %v", err)
+	}
+
+	ecdsaKey, ok := publicKey.(*ecdsa.PublicKey)
+	if !ok || ecdsaKey.Curve != elliptic.P256() {
+		return nil, codedError(invalidArgumentCode, "public key must be an ECDSA P-256 key")
+	}
+
+	return ecdsaKey, nil
+}
+
+// readPublicKey returns the public key registered by account
+func readPublicKey(ctx contractapi.TransactionContextInterface, account string) (*ecdsa.PublicKey, error) {
+	key, err := publicKeyKey(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKeyPEM, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if publicKeyPEM == nil {
+		return nil, codedError(notFoundCode, "account %s has not registered a public key", account)
+	}
+
+	return parsePublicKey(string(publicKeyPEM))
+}
+
+// permitPayload builds the payload of a permit with the current nonce of owner
+func permitPayload(ctx contractapi.TransactionContextInterface, owner string, spender string, value int, deadline int64) (*PermitPayload, error) {
+	key, err := nonceKey(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := readInt(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	chaincodeName, err := getChaincodeName(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PermitPayload{
+		Owner:     owner,
+		Spender:   spender,
+		Value:     value,
+		Nonce:     nonce,
+		Deadline:  deadline,
+		Channel:   ctx.GetStub().GetChannelID(),
+		Chaincode: chaincodeName,
+	}, nil
+}
+
+// RegisterPublicKey registers the PEM encoded ECDSA P-256 public key that verifies the permits of the client,
+// replacing any key registered before
+func (s *SmartContract) RegisterPublicKey(ctx contractapi.TransactionContextInterface, publicKeyPEM string) error {
+	if err := checkInitialized(ctx); err != nil {
+		return err
+	}
+	if _, err := parsePublicKey(publicKeyPEM); err != nil {
+		return err
+	}
+
+	owner, err := getClientAccountID(ctx)
+	if err != nil {
+		return err
+	}
+
+	key, err := publicKeyKey(ctx, owner)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(key, []byte(publicKeyPEM))
+	if err != nil {
+		return fmt.Errorf("failed to put public key into world state: %v", err)
+	}
+
+	return nil
+}
+
+// Nonces returns the nonce the next permit of owner must be signed with
+func (s *SmartContract) Nonces(ctx contractapi.TransactionContextInterface, owner string) (int, error) {
+	if err := checkInitialized(ctx); err != nil {
+		return 0, err
+	}
+
+	key, err := nonceKey(ctx, owner)
+	if err != nil {
+		return 0, err
+	}
+
+	return readInt(ctx, key)
+}
+
+// PermitPayload returns the JSON payload owner must sign to permit spender to transfer up to value of its tokens
+// until deadline, in seconds since the Unix epoch
+func (s *SmartContract) PermitPayload(ctx contractapi.TransactionContextInterface, owner string, spender string, value int, deadline int64) (string, error) {
+	if err := checkInitialized(ctx); err != nil {
+		return "", err
+	}
+
+	payload, err := permitPayload(ctx, owner, spender, value, deadline)
+	if err != nil {
+		return "", err
+	}
+
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	return string(payloadJSON), nil
+}
+
+// Permit sets the allowance of spender over the tokens of owner to value, authorized by the base64 encoded
+// ASN.1 ECDSA signature of owner over the SHA-256 hash of the payload returned by PermitPayload.
+// Any client may relay a permit, each of which can be used once and only until its deadline.
+func (s *SmartContract) Permit(ctx contractapi.TransactionContextInterface, owner string, spender string, value int, deadline int64, signature string) error {
+	if err := checkInitialized(ctx); err != nil {
+		return err
+	}
+	if value < 0 {
+		return codedError(invalidArgumentCode, "allowance must not be negative")
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+	if now > deadline {
+		return codedError(invalidArgumentCode, "permit expired at %d", deadline)
+	}
+
+	publicKey, err := readPublicKey(ctx, owner)
+	if err != nil {
+		return err
+	}
+
+	payload, err := permitPayload(ctx, owner, spender, value, deadline)
+	if err != nil {
+		return err
+	}
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	signatureBytes, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return codedError(invalidArgumentCode, "failed to base64 decode signature: %v", err)
+	}
+	digest := sha256.Sum256(payloadJSON)
+	if !ecdsa.VerifyASN1(publicKey, digest[:], signatureBytes) {
+		return codedError(unauthorizedCode, "permit is not signed by owner %s", owner)
+	}
+
+	key, err := nonceKey(ctx, owner)
+	if err != nil {
+		return err
+	}
+	err = writeInt(ctx, key, payload.Nonce+1)
+	if err != nil {
+		return err
+	}
+
+	err = writeAllowance(ctx, owner, spender, value)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, "Approval", Approval{Owner: owner, Spender: spender, Value: value})
+}
@@ -0,0 +1,120 @@
+package chaincode
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// newPermitKey returns a P-256 private key and the PEM encoding of its public key
+func newPermitKey(t *testing.T) (*ecdsa.PrivateKey, string) {
+	t.Helper()
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to marshal public key: %v", err)
+	}
+
+	return privateKey, string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+// signPermit signs payload with privateKey the way a wallet would
+func signPermit(t *testing.T, privateKey *ecdsa.PrivateKey, payload PermitPayload) string {
+	t.Helper()
+
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("failed to marshal payload: %v", err)
+	}
+	digest := sha256.Sum256(payloadJSON)
+	signature, err := ecdsa.SignASN1(rand.Reader, privateKey, digest[:])
+	if err != nil {
+		t.Fatalf("failed to sign payload: %v", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(signature)
+}
+
+func TestPermit(t *testing.T) {
+	contract := &SmartContract{}
+	admin := newIdentity("admin", "Org1MSP")
+	spender := newIdentity("spender", "Org2MSP")
+	relayer := newIdentity("relayer", "Org2MSP")
+	stub := newToken(t, admin, 100)
+	deadline := stub.timestamp.Add(time.Hour).Unix()
+
+	privateKey, publicKeyPEM := newPermitKey(t)
+	payload := PermitPayload{
+		Owner:     admin.id,
+		Spender:   spender.id,
+		Value:     30,
+		Nonce:     0,
+		Deadline:  deadline,
+		Channel:   "mychannel",
+		Chaincode: "token_erc20",
+	}
+	signature := signPermit(t, privateKey, payload)
+
+	permit := func(value int, signature string) error {
+		return invoke(stub, relayer, func(ctx contractapi.TransactionContextInterface) error {
+			return contract.Permit(ctx, admin.id, spender.id, value, deadline, signature)
+		})
+	}
+
+	assertCode(t, permit(30, signature), notFoundCode)
+
+	err := invoke(stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.RegisterPublicKey(ctx, "not a key")
+	})
+	assertCode(t, err, invalidArgumentCode)
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.RegisterPublicKey(ctx, publicKeyPEM)
+	})
+
+	mustInvoke(t, stub, relayer, func(ctx contractapi.TransactionContextInterface) error {
+		payloadJSON, err := contract.PermitPayload(ctx, admin.id, spender.id, 30, deadline)
+		if want, _ := json.Marshal(payload); payloadJSON != string(want) {
+			t.Fatalf("got payload %s, want %s", payloadJSON, want)
+		}
+		return err
+	})
+
+	// The signature only covers the value it was made for
+	assertCode(t, permit(31, signature), unauthorizedCode)
+
+	if err := permit(30, signature); err != nil {
+		t.Fatalf("Permit failed: %v", err)
+	}
+	mustInvoke(t, stub, spender, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.TransferFrom(ctx, admin.id, spender.id, 30)
+		return err
+	})
+
+	// A permit cannot be replayed once the nonce moved on
+	assertCode(t, permit(30, signature), unauthorizedCode)
+
+	payload.Nonce = 1
+	stub.timestamp = stub.timestamp.Add(2 * time.Hour)
+	assertCode(t, permit(30, signPermit(t, privateKey, payload)), invalidArgumentCode)
+
+	mustInvoke(t, stub, relayer, func(ctx contractapi.TransactionContextInterface) error {
+		nonce, err := contract.Nonces(ctx, admin.id)
+		if nonce != 1 {
+			t.Fatalf("got nonce %d, want 1", nonce)
+		}
+		return err
+	})
+}
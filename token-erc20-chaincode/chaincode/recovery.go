@@ -0,0 +1,146 @@
+package chaincode
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// forcedTransferPrefix is the object type of the ("forcedtransfer", id) records of forced transfers
+const forcedTransferPrefix = "forcedtransfer"
+
+// ForcedTransfer records tokens moved by a recovery officer without the consent of their owner,
+// e.g. to execute a court order. DocumentHash is the hex SHA-256 hash of the document ordering it,
+// kept off chain; Timestamp is in seconds since the Unix epoch.
+type ForcedTransfer struct {
+	ID           string `json:"id"`
+	From         string `json:"from"`
+	To           string `json:"to"`
+	Amount       int    `json:"amount"`
+	Reason       string `json:"reason"`
+	DocumentHash string `json:"document_hash"`
+	Officer      string `json:"officer"`
+	Timestamp    int64  `json:"timestamp"`
+}
+
+// checkJustification returns an error unless a reason and the SHA-256 hash of a document justify a forced transfer
+func checkJustification(reason string, documentHash string) error {
+	if reason == "" {
+		return codedError(invalidArgumentCode, "a reason is required to force a transfer")
+	}
+
+	hash, err := hex.DecodeString(documentHash)
+	if err != nil || len(hash) != 32 {
+		return codedError(invalidArgumentCode, "document hash must be a hex encoded SHA-256 hash")
+	}
+
+	return nil
+}
+
+// forceTransfer moves amount available tokens of from to to, bypassing fees, limits and compliance review,
+// and records why. Only holders of the recovery role, the admin organization until governance
+// is initialized, may force transfers.
+func forceTransfer(ctx contractapi.TransactionContextInterface, from string, to string, amount int, reason string, documentHash string) error {
+	if err := checkInitialized(ctx); err != nil {
+		return err
+	}
+	if err := checkRoleOrAdmin(ctx, recoveryRole); err != nil {
+		return err
+	}
+	if from == "" || to == "" {
+		return codedError(invalidArgumentCode, "accounts must not be empty")
+	}
+	if err := checkJustification(reason, documentHash); err != nil {
+		return err
+	}
+
+	officer, err := getClientAccountID(ctx)
+	if err != nil {
+		return err
+	}
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Tokens reserved by holds and pending transfers are left alone, they must be released first
+	err = move(ctx, from, to, amount)
+	if err != nil {
+		return err
+	}
+
+	forced := ForcedTransfer{
+		ID:           ctx.GetStub().GetTxID(),
+		From:         from,
+		To:           to,
+		Amount:       amount,
+		Reason:       reason,
+		DocumentHash: documentHash,
+		Officer:      officer,
+		Timestamp:    now,
+	}
+	forcedJSON, err := json.Marshal(forced)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(forcedTransferPrefix, []string{forced.ID})
+	if err != nil {
+		return fmt.Errorf("failed to create forced transfer key: %v", err)
+	}
+	err = ctx.GetStub().PutState(key, forcedJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put forced transfer into world state: %v", err)
+	}
+
+	return emitEvent(ctx, "ForcedTransfer", forced)
+}
+
+// ForceTransfer moves amount tokens from one account to another without the consent of their owner.
+// reason and documentHash, the hex SHA-256 hash of the document ordering it, are required and recorded on chain.
+func (s *SmartContract) ForceTransfer(ctx contractapi.TransactionContextInterface, from string, to string, amount int, reason string, documentHash string) error {
+	return forceTransfer(ctx, from, to, amount, reason, documentHash)
+}
+
+// Clawback recovers amount tokens of account into the account of the recovery officer.
+// reason and documentHash, the hex SHA-256 hash of the document ordering it, are required and recorded on chain.
+func (s *SmartContract) Clawback(ctx contractapi.TransactionContextInterface, account string, amount int, reason string, documentHash string) error {
+	officer, err := getClientAccountID(ctx)
+	if err != nil {
+		return err
+	}
+
+	return forceTransfer(ctx, account, officer, amount, reason, documentHash)
+}
+
+// GetForcedTransfers returns the audit trail of every forced transfer and clawback
+func (s *SmartContract) GetForcedTransfers(ctx contractapi.TransactionContextInterface) ([]*ForcedTransfer, error) {
+	if err := checkInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(forcedTransferPrefix, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get forced transfers: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	transfers := []*ForcedTransfer{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var transfer ForcedTransfer
+		err = json.Unmarshal(queryResponse.Value, &transfer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal forced transfer: %v", err)
+		}
+		transfers = append(transfers, &transfer)
+	}
+
+	return transfers, nil
+}
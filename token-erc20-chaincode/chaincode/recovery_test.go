@@ -0,0 +1,80 @@
+package chaincode
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+func TestForceTransfer(t *testing.T) {
+	contract := &SmartContract{}
+	admin := newIdentity("admin", "Org1MSP")
+	officer := newIdentity("officer", "Org1MSP")
+	governor1 := newGovernor("governor1", "Org1MSP")
+	governor2 := newGovernor("governor2", "Org2MSP")
+	alice := newIdentity("alice", "Org2MSP")
+	bob := newIdentity("bob", "Org2MSP")
+	stub := newGovernedToken(t, admin, 100)
+	expiration := stub.timestamp.Add(time.Hour).Unix()
+
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.Transfer(ctx, alice.id, 100)
+		return err
+	})
+
+	documentHash := sha256.Sum256([]byte("court order 42"))
+	hash := hex.EncodeToString(documentHash[:])
+	forceTransfer := func(identity *mockIdentity, reason string, hash string) error {
+		return invoke(stub, identity, func(ctx contractapi.TransactionContextInterface) error {
+			return contract.ForceTransfer(ctx, alice.id, bob.id, 30, reason, hash)
+		})
+	}
+
+	// Once governed, forcing transfers requires the recovery role, even for the admin organization
+	assertCode(t, forceTransfer(admin, "court order", hash), unauthorizedCode)
+
+	mustInvoke(t, stub, governor1, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.ProposeRoleChange(ctx, "op1", recoveryRole, officer.id, true, expiration)
+	})
+	mustInvoke(t, stub, governor2, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.ApproveOperation(ctx, "op1")
+	})
+
+	assertCode(t, forceTransfer(officer, "", hash), invalidArgumentCode)
+	assertCode(t, forceTransfer(officer, "court order", "not a hash"), invalidArgumentCode)
+	if err := forceTransfer(officer, "court order", hash); err != nil {
+		t.Fatalf("ForceTransfer failed: %v", err)
+	}
+	if stub.event.name != "ForcedTransfer" {
+		t.Fatalf("got event %s, want ForcedTransfer", stub.event.name)
+	}
+
+	mustInvoke(t, stub, officer, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.Clawback(ctx, alice.id, 20, "fraud recovery", hash)
+	})
+	if balance := balanceOf(t, stub, alice.id); balance != 50 {
+		t.Fatalf("got balance %d, want 50", balance)
+	}
+	if balance := balanceOf(t, stub, bob.id); balance != 30 {
+		t.Fatalf("got balance %d, want 30", balance)
+	}
+	if balance := balanceOf(t, stub, officer.id); balance != 20 {
+		t.Fatalf("got officer balance %d, want 20", balance)
+	}
+
+	mustInvoke(t, stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		transfers, err := contract.GetForcedTransfers(ctx)
+		if len(transfers) != 2 {
+			t.Fatalf("got %d forced transfers, want 2", len(transfers))
+		}
+		for _, transfer := range transfers {
+			if transfer.Officer != officer.id || transfer.DocumentHash != hash || transfer.Reason == "" {
+				t.Fatalf("got forced transfer %+v without its justification", transfer)
+			}
+		}
+		return err
+	})
+}
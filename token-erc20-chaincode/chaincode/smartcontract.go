@@ -0,0 +1,347 @@
+package chaincode
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// SmartContract provides functions for transferring tokens between accounts
+type SmartContract struct {
+	contractapi.Contract
+}
+
+// Transfer is emitted when tokens move between accounts. From is empty for mints and To for burns.
+// Value is the amount To receives and Fee the amount From paid on top of it to the treasury.
+type Transfer struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Value int    `json:"value"`
+	Fee   int    `json:"fee,omitempty"`
+}
+
+// Approval is emitted when an owner sets the allowance of a spender
+type Approval struct {
+	Owner   string `json:"owner"`
+	Spender string `json:"spender"`
+	Value   int    `json:"value"`
+}
+
+// Initialize sets the name, symbol and decimals of the token. It can only be called once.
+// Only the admin organization may initialize the token.
+func (s *SmartContract) Initialize(ctx contractapi.TransactionContextInterface, name string, symbol string, decimals string) (bool, error) {
+	if err := checkAdmin(ctx); err != nil {
+		return false, err
+	}
+
+	nameBytes, err := ctx.GetStub().GetState(nameKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if nameBytes != nil {
+		return false, codedError(invalidArgumentCode, "contract options are already set, client is not authorized to change them")
+	}
+	if _, err := strconv.Atoi(decimals); err != nil {
+		return false, codedError(invalidArgumentCode, "decimals must be an integer: %v", err)
+	}
+
+	for key, value := range map[string]string{nameKey: name, symbolKey: symbol, decimalsKey: decimals} {
+		err = ctx.GetStub().PutState(key, []byte(value))
+		if err != nil {
+			return false, fmt.Errorf("failed to set token %s: %v", key, err)
+		}
+	}
+
+	return true, nil
+}
+
+// checkInitialized returns an error unless Initialize has been called
+func checkInitialized(ctx contractapi.TransactionContextInterface) error {
+	nameBytes, err := ctx.GetStub().GetState(nameKey)
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if nameBytes == nil {
+		return fmt.Errorf("contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	return nil
+}
+
+// Mint creates amount tokens and assigns them to the client.
+// Only the admin organization may mint tokens, and only until governance is initialized:
+// from then on tokens are minted by approved governance operations.
+func (s *SmartContract) Mint(ctx contractapi.TransactionContextInterface, amount int) error {
+	if err := checkInitialized(ctx); err != nil {
+		return err
+	}
+	if err := checkAdmin(ctx); err != nil {
+		return err
+	}
+	if err := checkAmount(amount); err != nil {
+		return err
+	}
+
+	governed, err := isGoverned(ctx)
+	if err != nil {
+		return err
+	}
+	if governed {
+		return codedError(unauthorizedCode, "tokens can only be minted through a governance operation")
+	}
+
+	minter, err := getClientAccountID(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = mint(ctx, minter, amount)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, "Transfer", Transfer{From: "", To: minter, Value: amount})
+}
+
+// mint creates amount tokens, assigns them to account and adds them to the total supply
+func mint(ctx contractapi.TransactionContextInterface, account string, amount int) error {
+	err := credit(ctx, account, amount)
+	if err != nil {
+		return err
+	}
+
+	totalSupply, err := readInt(ctx, totalSupplyKey)
+	if err != nil {
+		return err
+	}
+	totalSupply, err = add(totalSupply, amount)
+	if err != nil {
+		return err
+	}
+
+	return writeInt(ctx, totalSupplyKey, totalSupply)
+}
+
+// Burn destroys amount tokens of the client.
+// Only the admin organization may burn tokens until governance is initialized, then only holders of the burner role.
+func (s *SmartContract) Burn(ctx contractapi.TransactionContextInterface, amount int) error {
+	if err := checkInitialized(ctx); err != nil {
+		return err
+	}
+	if err := checkAmount(amount); err != nil {
+		return err
+	}
+
+	if err := checkRoleOrAdmin(ctx, burnerRole); err != nil {
+		return err
+	}
+
+	minter, err := getClientAccountID(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = debit(ctx, minter, amount)
+	if err != nil {
+		return err
+	}
+
+	totalSupply, err := readInt(ctx, totalSupplyKey)
+	if err != nil {
+		return err
+	}
+	err = writeInt(ctx, totalSupplyKey, totalSupply-amount)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, "Transfer", Transfer{From: minter, To: "", Value: amount})
+}
+
+// Transfer moves amount tokens from the client to recipient, less the transfer fee routed to the treasury
+func (s *SmartContract) Transfer(ctx contractapi.TransactionContextInterface, recipient string, amount int) (*TransferResult, error) {
+	if err := checkInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if recipient == "" {
+		return nil, codedError(invalidArgumentCode, "transfer to an empty account")
+	}
+
+	clientID, err := getClientAccountID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := transferWithFee(ctx, clientID, recipient, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	// A transfer awaiting compliance review emitted TransferPending instead
+	if result.Pending != "" {
+		return result, nil
+	}
+
+	err = emitEvent(ctx, "Transfer", Transfer{From: clientID, To: recipient, Value: result.Net, Fee: result.Fee})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// BalanceOf returns the balance of account
+func (s *SmartContract) BalanceOf(ctx contractapi.TransactionContextInterface, account string) (int, error) {
+	if err := checkInitialized(ctx); err != nil {
+		return 0, err
+	}
+
+	return readBalance(ctx, account)
+}
+
+// ClientAccountBalance returns the balance of the client
+func (s *SmartContract) ClientAccountBalance(ctx contractapi.TransactionContextInterface) (int, error) {
+	if err := checkInitialized(ctx); err != nil {
+		return 0, err
+	}
+
+	clientID, err := getClientAccountID(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return readBalance(ctx, clientID)
+}
+
+// ClientAccountID returns the account ID of the client, the ID other clients send tokens to
+func (s *SmartContract) ClientAccountID(ctx contractapi.TransactionContextInterface) (string, error) {
+	if err := checkInitialized(ctx); err != nil {
+		return "", err
+	}
+
+	return getClientAccountID(ctx)
+}
+
+// TotalSupply returns the number of tokens in existence
+func (s *SmartContract) TotalSupply(ctx contractapi.TransactionContextInterface) (int, error) {
+	if err := checkInitialized(ctx); err != nil {
+		return 0, err
+	}
+
+	return readInt(ctx, totalSupplyKey)
+}
+
+// Approve allows spender to transfer up to value tokens of the client, replacing any previous allowance
+func (s *SmartContract) Approve(ctx contractapi.TransactionContextInterface, spender string, value int) error {
+	if err := checkInitialized(ctx); err != nil {
+		return err
+	}
+	if value < 0 {
+		return codedError(invalidArgumentCode, "allowance must not be negative")
+	}
+
+	owner, err := getClientAccountID(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = writeAllowance(ctx, owner, spender, value)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, "Approval", Approval{Owner: owner, Spender: spender, Value: value})
+}
+
+// Allowance returns the amount spender may still transfer from owner
+func (s *SmartContract) Allowance(ctx contractapi.TransactionContextInterface, owner string, spender string) (int, error) {
+	if err := checkInitialized(ctx); err != nil {
+		return 0, err
+	}
+
+	return readAllowance(ctx, owner, spender)
+}
+
+// TransferFrom moves value tokens from one account to another, less the transfer fee routed to the treasury,
+// spending value of the allowance of the client over from
+func (s *SmartContract) TransferFrom(ctx contractapi.TransactionContextInterface, from string, to string, value int) (*TransferResult, error) {
+	if err := checkInitialized(ctx); err != nil {
+		return nil, err
+	}
+	if to == "" {
+		return nil, codedError(invalidArgumentCode, "transfer to an empty account")
+	}
+
+	spender, err := getClientAccountID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	allowance, err := readAllowance(ctx, from, spender)
+	if err != nil {
+		return nil, err
+	}
+	if allowance < value {
+		return nil, codedError(insufficientFundsCode, "spender does not have enough allowance for transfer")
+	}
+
+	result, err := transferWithFee(ctx, from, to, value)
+	if err != nil {
+		return nil, err
+	}
+
+	err = writeAllowance(ctx, from, spender, allowance-value)
+	if err != nil {
+		return nil, err
+	}
+
+	// A transfer awaiting compliance review emitted TransferPending instead
+	if result.Pending != "" {
+		return result, nil
+	}
+
+	err = emitEvent(ctx, "Transfer", Transfer{From: from, To: to, Value: result.Net, Fee: result.Fee})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Name returns the name of the token
+func (s *SmartContract) Name(ctx contractapi.TransactionContextInterface) (string, error) {
+	if err := checkInitialized(ctx); err != nil {
+		return "", err
+	}
+
+	nameBytes, err := ctx.GetStub().GetState(nameKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to read from world state: %v", err)
+	}
+
+	return string(nameBytes), nil
+}
+
+// Symbol returns the symbol of the token
+func (s *SmartContract) Symbol(ctx contractapi.TransactionContextInterface) (string, error) {
+	if err := checkInitialized(ctx); err != nil {
+		return "", err
+	}
+
+	symbolBytes, err := ctx.GetStub().GetState(symbolKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to read from world state: %v", err)
+	}
+
+	return string(symbolBytes), nil
+}
+
+// Decimals returns the number of decimals used to display token amounts
+func (s *SmartContract) Decimals(ctx contractapi.TransactionContextInterface) (int, error) {
+	if err := checkInitialized(ctx); err != nil {
+		return 0, err
+	}
+
+	return readInt(ctx, decimalsKey)
+}
@@ -0,0 +1,181 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+func TestInitialize(t *testing.T) {
+	contract := &SmartContract{}
+	admin := newIdentity("admin", "Org1MSP")
+	stub := newMockStub()
+
+	err := invoke(stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.Mint(ctx, 10)
+	})
+	if err == nil {
+		t.Fatalf("Mint succeeded before Initialize")
+	}
+
+	err = invoke(stub, newIdentity("bob", "Org2MSP"), func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.Initialize(ctx, "Token", "TOK", "2")
+		return err
+	})
+	assertCode(t, err, unauthorizedCode)
+
+	err = invoke(stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.Initialize(ctx, "Token", "TOK", "two")
+		return err
+	})
+	assertCode(t, err, invalidArgumentCode)
+
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.Initialize(ctx, "Token", "TOK", "2")
+		return err
+	})
+
+	err = invoke(stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.Initialize(ctx, "Other", "OTH", "0")
+		return err
+	})
+	assertCode(t, err, invalidArgumentCode)
+
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		name, err := contract.Name(ctx)
+		if err != nil {
+			return err
+		}
+		decimals, err := contract.Decimals(ctx)
+		if err != nil {
+			return err
+		}
+		if name != "Token" || decimals != 2 {
+			t.Fatalf("got name %q and decimals %d, want Token and 2", name, decimals)
+		}
+		return nil
+	})
+}
+
+func TestMintAndBurn(t *testing.T) {
+	contract := &SmartContract{}
+	admin := newIdentity("admin", "Org1MSP")
+	stub := newToken(t, admin, 100)
+
+	err := invoke(stub, newIdentity("bob", "Org2MSP"), func(ctx contractapi.TransactionContextInterface) error {
+		return contract.Mint(ctx, 10)
+	})
+	assertCode(t, err, unauthorizedCode)
+
+	err = invoke(stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.Mint(ctx, 0)
+	})
+	assertCode(t, err, invalidArgumentCode)
+
+	err = invoke(stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.Mint(ctx, math.MaxInt)
+	})
+	assertCode(t, err, invalidArgumentCode)
+
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.Burn(ctx, 30)
+	})
+
+	err = invoke(stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.Burn(ctx, 71)
+	})
+	assertCode(t, err, insufficientFundsCode)
+
+	if balance := balanceOf(t, stub, admin.id); balance != 70 {
+		t.Fatalf("got balance %d, want 70", balance)
+	}
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		supply, err := contract.TotalSupply(ctx)
+		if supply != 70 {
+			t.Fatalf("got total supply %d, want 70", supply)
+		}
+		return err
+	})
+}
+
+func TestTransfer(t *testing.T) {
+	contract := &SmartContract{}
+	admin := newIdentity("admin", "Org1MSP")
+	bob := newIdentity("bob", "Org2MSP")
+	stub := newToken(t, admin, 100)
+
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.Transfer(ctx, bob.id, 40)
+		return err
+	})
+
+	var transfer Transfer
+	if err := json.Unmarshal(stub.event.payload, &transfer); err != nil || stub.event.name != "Transfer" {
+		t.Fatalf("got event %v, want a Transfer event", stub.event)
+	}
+	if transfer != (Transfer{From: admin.id, To: bob.id, Value: 40}) {
+		t.Fatalf("got transfer %+v", transfer)
+	}
+
+	tests := []struct {
+		name      string
+		recipient string
+		amount    int
+		code      string
+	}{
+		{name: "insufficient funds", recipient: admin.id, amount: 41, code: insufficientFundsCode},
+		{name: "zero amount", recipient: admin.id, amount: 0, code: invalidArgumentCode},
+		{name: "self transfer", recipient: bob.id, amount: 1, code: invalidArgumentCode},
+		{name: "empty recipient", recipient: "", amount: 1, code: invalidArgumentCode},
+	}
+	for _, test := range tests {
+		err := invoke(stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+			_, err := contract.Transfer(ctx, test.recipient, test.amount)
+			return err
+		})
+		assertCode(t, err, test.code)
+	}
+
+	if balance := balanceOf(t, stub, admin.id); balance != 60 {
+		t.Fatalf("got admin balance %d, want 60", balance)
+	}
+	if balance := balanceOf(t, stub, bob.id); balance != 40 {
+		t.Fatalf("got bob balance %d, want 40", balance)
+	}
+}
+
+func TestTransferFrom(t *testing.T) {
+	contract := &SmartContract{}
+	admin := newIdentity("admin", "Org1MSP")
+	bob := newIdentity("bob", "Org2MSP")
+	carol := newIdentity("carol", "Org2MSP")
+	stub := newToken(t, admin, 100)
+
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.Approve(ctx, bob.id, 50)
+	})
+
+	err := invoke(stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.TransferFrom(ctx, admin.id, carol.id, 51)
+		return err
+	})
+	assertCode(t, err, insufficientFundsCode)
+
+	mustInvoke(t, stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.TransferFrom(ctx, admin.id, carol.id, 20)
+		return err
+	})
+
+	mustInvoke(t, stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		allowance, err := contract.Allowance(ctx, admin.id, bob.id)
+		if allowance != 30 {
+			t.Fatalf("got allowance %d, want 30", allowance)
+		}
+		return err
+	})
+	if balance := balanceOf(t, stub, carol.id); balance != 20 {
+		t.Fatalf("got carol balance %d, want 20", balance)
+	}
+}
@@ -0,0 +1,131 @@
+package chaincode
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/protobuf/proto"
+)
+
+// adminMSPID is the only organization allowed to initialize the token, mint and administer it
+const adminMSPID = "Org1MSP"
+
+// Error codes prefix the message of every error a client can act on, e.g. "INSUFFICIENT_FUNDS: ...",
+// so that clients such as the REST API can tell them apart from other failures
+const (
+	unauthorizedCode      = "UNAUTHORIZED"
+	invalidArgumentCode   = "INVALID_ARGUMENT"
+	notFoundCode          = "NOT_FOUND"
+	insufficientFundsCode = "INSUFFICIENT_FUNDS"
+	// transferLimitExceededCode rejects a transfer over the daily limit of the sender
+	transferLimitExceededCode = "TRANSFER_LIMIT_EXCEEDED"
+)
+
+// codedError returns an error whose message starts with code, e.g. "NOT_FOUND: vesting schedule v1 does not exist"
+func codedError(code string, format string, args ...interface{}) error {
+	return fmt.Errorf(code+": "+format, args...)
+}
+
+// getClientAccountID returns the decoded x509 identity of the invoking client,
+// e.g. "x509::CN=user1,OU=client,...::CN=ca.org1.example.com,..."
+func getClientAccountID(ctx contractapi.TransactionContextInterface) (string, error) {
+	b64ID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return "", fmt.Errorf("failed to get client id: %v", err)
+	}
+
+	decodedID, err := base64.StdEncoding.DecodeString(b64ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to base64 decode client id: %v", err)
+	}
+
+	return string(decodedID), nil
+}
+
+// getClientMSPID returns the MSP ID of the organization of the invoking client
+func getClientMSPID(ctx contractapi.TransactionContextInterface) (string, error) {
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return "", fmt.Errorf("failed to get MSPID: %v", err)
+	}
+
+	return clientMSPID, nil
+}
+
+// checkAdmin returns an error unless the client belongs to the admin organization
+func checkAdmin(ctx contractapi.TransactionContextInterface) error {
+	clientMSPID, err := getClientMSPID(ctx)
+	if err != nil {
+		return err
+	}
+	if clientMSPID != adminMSPID {
+		return codedError(unauthorizedCode, "client is not authorized to perform this operation")
+	}
+
+	return nil
+}
+
+// txTime returns the timestamp of the transaction in seconds since the Unix epoch, the same on every endorsing peer
+func txTime(ctx contractapi.TransactionContextInterface) (int64, error) {
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+
+	return timestamp.GetSeconds(), nil
+}
+
+// getChaincodeName returns the name the token chaincode is deployed under, read from the
+// chaincode header extension of the signed proposal
+func getChaincodeName(ctx contractapi.TransactionContextInterface) (string, error) {
+	signedProposal, err := ctx.GetStub().GetSignedProposal()
+	if err != nil {
+		return "", fmt.Errorf("failed to get signed proposal: %v", err)
+	}
+
+	proposal := &peer.Proposal{}
+	err = proto.Unmarshal(signedProposal.ProposalBytes, proposal)
+	if err != nil {
+		return "", fmt.Errorf("failed to unmarshal proposal: %v", err)
+	}
+
+	header := &common.Header{}
+	err = proto.Unmarshal(proposal.Header, header)
+	if err != nil {
+		return "", fmt.Errorf("failed to unmarshal header: %v", err)
+	}
+
+	channelHeader := &common.ChannelHeader{}
+	err = proto.Unmarshal(header.ChannelHeader, channelHeader)
+	if err != nil {
+		return "", fmt.Errorf("failed to unmarshal channel header: %v", err)
+	}
+
+	chaincodeHeaderExtension := &peer.ChaincodeHeaderExtension{}
+	err = proto.Unmarshal(channelHeader.Extension, chaincodeHeaderExtension)
+	if err != nil {
+		return "", fmt.Errorf("failed to unmarshal chaincode header extension: %v", err)
+	}
+
+	return chaincodeHeaderExtension.GetChaincodeId().GetName(), nil
+}
+
+// emitEvent marshals payload and sets it as the chaincode event of the transaction.
+// Fabric keeps one event per transaction, so every function emits at most one.
+func emitEvent(ctx contractapi.TransactionContextInterface, name string, payload interface{}) error {
+	eventJSON, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	err = ctx.GetStub().SetEvent(name, eventJSON)
+	if err != nil {
+		return fmt.Errorf("failed to set event: %v", err)
+	}
+
+	return nil
+}
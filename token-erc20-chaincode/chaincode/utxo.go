@@ -0,0 +1,228 @@
+package chaincode
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+const (
+	balanceModelPrefix = "balancemodel"
+	outputPrefix       = "utxo"
+)
+
+// Balance models. In the account model the balance of an account is one key, so concurrent transfers
+// to a busy account read and write the same key and all but one fail with MVCC_READ_CONFLICT.
+// In the UTXO model a credit writes a new unspent output ("utxo", owner, txID, n) without reading
+// anything, so any number of transfers to an account commit together; its balance is the sum of its
+// outputs and spending consumes them all, leaving the change in a new output.
+const (
+	accountModel = "account"
+	utxoModel    = "utxo"
+)
+
+// TransactionContext is the transaction context of SmartContract. It numbers the outputs and
+// voting power checkpoints a transaction creates, as a transaction cannot read its own writes.
+type TransactionContext struct {
+	contractapi.TransactionContext
+	outputs     int
+	checkpoints int
+}
+
+// GetTransactionContextHandler returns the context numbering the keys created by each transaction
+func (s *SmartContract) GetTransactionContextHandler() contractapi.SettableTransactionContextInterface {
+	return new(TransactionContext)
+}
+
+// balanceModelKey builds the key of the balance model
+func balanceModelKey(ctx contractapi.TransactionContextInterface) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(balanceModelPrefix, []string{})
+	if err != nil {
+		return "", fmt.Errorf("failed to create balance model key: %v", err)
+	}
+
+	return key, nil
+}
+
+// isUTXOModel reports whether balances are kept as unspent outputs rather than one key per account
+func isUTXOModel(ctx contractapi.TransactionContextInterface) (bool, error) {
+	key, err := balanceModelKey(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	modelBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return false, fmt.Errorf("failed to read from world state: %v", err)
+	}
+
+	return string(modelBytes) == utxoModel, nil
+}
+
+// writeOutput creates a new unspent output of amount tokens owned by owner
+func writeOutput(ctx contractapi.TransactionContextInterface, owner string, amount int) error {
+	txCtx, ok := ctx.(*TransactionContext)
+	if !ok {
+		return fmt.Errorf("the UTXO model requires the transaction context of the token chaincode")
+	}
+
+	n := strconv.Itoa(txCtx.outputs)
+	txCtx.outputs++
+
+	key, err := ctx.GetStub().CreateCompositeKey(outputPrefix, []string{owner, ctx.GetStub().GetTxID(), n})
+	if err != nil {
+		return fmt.Errorf("failed to create output key: %v", err)
+	}
+
+	return writeInt(ctx, key, amount)
+}
+
+// readOutputs returns the keys of the unspent outputs of owner and the sum of their amounts
+func readOutputs(ctx contractapi.TransactionContextInterface, owner string) ([]string, int, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(outputPrefix, []string{owner})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get outputs of %s: %v", owner, err)
+	}
+	defer resultsIterator.Close()
+
+	var keys []string
+	balance := 0
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, 0, err
+		}
+
+		amount, err := strconv.Atoi(string(queryResponse.Value))
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to parse output %s: %v", queryResponse.Key, err)
+		}
+		balance, err = add(balance, amount)
+		if err != nil {
+			return nil, 0, err
+		}
+		keys = append(keys, queryResponse.Key)
+	}
+
+	return keys, balance, nil
+}
+
+// spendOutputs removes amount tokens from the outputs of owner: every output is consumed and
+// the change, if any, is left in a new output
+func spendOutputs(ctx contractapi.TransactionContextInterface, owner string, amount int) error {
+	keys, balance, err := readOutputs(ctx, owner)
+	if err != nil {
+		return err
+	}
+	if balance < amount {
+		return codedError(insufficientFundsCode, "account %s has insufficient funds", owner)
+	}
+
+	for _, key := range keys {
+		err = ctx.GetStub().DelState(key)
+		if err != nil {
+			return fmt.Errorf("failed to delete output %s: %v", key, err)
+		}
+	}
+
+	if balance == amount {
+		return indexHolder(ctx, owner, false)
+	}
+
+	return writeOutput(ctx, owner, balance-amount)
+}
+
+// SetBalanceModel switches between the account model, one balance key per account, and the UTXO model,
+// unspent outputs per owner that busy recipients can receive concurrently. The model can only change
+// while no tokens exist. Only the admin organization may set it.
+func (s *SmartContract) SetBalanceModel(ctx contractapi.TransactionContextInterface, model string) error {
+	if err := checkInitialized(ctx); err != nil {
+		return err
+	}
+	if err := checkAdmin(ctx); err != nil {
+		return err
+	}
+	if model != accountModel && model != utxoModel {
+		return codedError(invalidArgumentCode, "balance model must be %s or %s", accountModel, utxoModel)
+	}
+
+	totalSupply, err := readInt(ctx, totalSupplyKey)
+	if err != nil {
+		return err
+	}
+	if totalSupply != 0 {
+		return codedError(invalidArgumentCode, "the balance model can only change while the total supply is zero")
+	}
+
+	key, err := balanceModelKey(ctx)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(key, []byte(model))
+	if err != nil {
+		return fmt.Errorf("failed to put balance model into world state: %v", err)
+	}
+
+	return nil
+}
+
+// GetBalanceModel returns the balance model, account or utxo
+func (s *SmartContract) GetBalanceModel(ctx contractapi.TransactionContextInterface) (string, error) {
+	if err := checkInitialized(ctx); err != nil {
+		return "", err
+	}
+
+	utxo, err := isUTXOModel(ctx)
+	if err != nil {
+		return "", err
+	}
+	if utxo {
+		return utxoModel, nil
+	}
+
+	return accountModel, nil
+}
+
+// Consolidate merges the unspent outputs of the client into one, so that reading its balance
+// stays cheap however many transfers it received. It returns the number of outputs merged.
+func (s *SmartContract) Consolidate(ctx contractapi.TransactionContextInterface) (int, error) {
+	if err := checkInitialized(ctx); err != nil {
+		return 0, err
+	}
+
+	utxo, err := isUTXOModel(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if !utxo {
+		return 0, codedError(invalidArgumentCode, "only balances of the UTXO model can be consolidated")
+	}
+
+	owner, err := getClientAccountID(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	keys, balance, err := readOutputs(ctx, owner)
+	if err != nil {
+		return 0, err
+	}
+	if len(keys) <= 1 {
+		return len(keys), nil
+	}
+
+	for _, key := range keys {
+		err = ctx.GetStub().DelState(key)
+		if err != nil {
+			return 0, fmt.Errorf("failed to delete output %s: %v", key, err)
+		}
+	}
+
+	err = writeOutput(ctx, owner, balance)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(keys), nil
+}
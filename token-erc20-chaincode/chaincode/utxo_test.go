@@ -0,0 +1,240 @@
+package chaincode
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// endorsement is the read and write set of a transaction endorsed but not yet committed
+type endorsement struct {
+	reads  map[string]bool
+	ranges []string
+	writes map[string][]byte
+}
+
+// conflicts reports whether the endorsement read a key, or a range holding a key, in written
+func (e *endorsement) conflicts(written map[string]bool) bool {
+	for key := range written {
+		if e.reads[key] {
+			return true
+		}
+		for _, prefix := range e.ranges {
+			if strings.HasPrefix(key, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// endorseBlock endorses every transaction against the same committed state, as peers do for the
+// transactions of one block, then validates them in order the way the committing peer does:
+// a transaction that read a key written by an earlier valid transaction of the block fails with
+// MVCC_READ_CONFLICT. It returns the number of such conflicts.
+func endorseBlock(t testing.TB, stub *mockStub, identities []*mockIdentity, fn func(ctx contractapi.TransactionContextInterface) error) int {
+	t.Helper()
+
+	var endorsements []*endorsement
+	for _, identity := range identities {
+		stub.begin()
+
+		ctx := &TransactionContext{}
+		ctx.SetStub(stub)
+		ctx.SetClientIdentity(identity)
+		if err := fn(ctx); err != nil {
+			t.Fatalf("endorsement failed: %v", err)
+		}
+		endorsements = append(endorsements, &endorsement{reads: stub.reads, ranges: stub.ranges, writes: stub.writes})
+	}
+
+	conflicts := 0
+	written := map[string]bool{}
+	for _, e := range endorsements {
+		if e.conflicts(written) {
+			conflicts++
+			continue
+		}
+		stub.writes = e.writes
+		stub.commit()
+		for key := range e.writes {
+			written[key] = true
+		}
+	}
+	return conflicts
+}
+
+// newModelToken returns a stub holding a token in the given balance model, with 100 tokens for each payer
+func newModelToken(t testing.TB, model string, admin *mockIdentity, payers []*mockIdentity) *mockStub {
+	contract := &SmartContract{}
+	stub := newMockStub()
+	mustInvokeTB(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.Initialize(ctx, "Token", "TOK", "2")
+		return err
+	})
+	mustInvokeTB(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.SetBalanceModel(ctx, model)
+	})
+	mustInvokeTB(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.Mint(ctx, 100*len(payers))
+	})
+	for _, payer := range payers {
+		mustInvokeTB(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+			_, err := contract.Transfer(ctx, payer.id, 100)
+			return err
+		})
+	}
+	return stub
+}
+
+// mustInvokeTB is mustInvoke for tests and benchmarks alike
+func mustInvokeTB(t testing.TB, stub *mockStub, identity *mockIdentity, fn func(ctx contractapi.TransactionContextInterface) error) {
+	t.Helper()
+
+	if err := invoke(stub, identity, fn); err != nil {
+		t.Fatalf("transaction failed: %v", err)
+	}
+}
+
+// newPayers returns n identities paying a merchant
+func newPayers(n int) []*mockIdentity {
+	payers := make([]*mockIdentity, n)
+	for i := range payers {
+		payers[i] = newIdentity(fmt.Sprintf("payer%d", i), "Org2MSP")
+	}
+	return payers
+}
+
+func TestSetBalanceModel(t *testing.T) {
+	contract := &SmartContract{}
+	admin := newIdentity("admin", "Org1MSP")
+	stub := newToken(t, admin, 0)
+
+	setModel := func(identity *mockIdentity, model string) error {
+		return invoke(stub, identity, func(ctx contractapi.TransactionContextInterface) error {
+			return contract.SetBalanceModel(ctx, model)
+		})
+	}
+
+	assertCode(t, setModel(newIdentity("bob", "Org2MSP"), utxoModel), unauthorizedCode)
+	assertCode(t, setModel(admin, "ledger"), invalidArgumentCode)
+	if err := setModel(admin, utxoModel); err != nil {
+		t.Fatalf("SetBalanceModel failed: %v", err)
+	}
+
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.Mint(ctx, 10)
+	})
+	assertCode(t, setModel(admin, accountModel), invalidArgumentCode)
+}
+
+func TestUTXOTransfers(t *testing.T) {
+	contract := &SmartContract{}
+	admin := newIdentity("admin", "Org1MSP")
+	merchant := newIdentity("merchant", "Org2MSP")
+	payers := newPayers(3)
+	stub := newModelToken(t, utxoModel, admin, payers)
+
+	for _, payer := range payers {
+		mustInvoke(t, stub, payer, func(ctx contractapi.TransactionContextInterface) error {
+			_, err := contract.Transfer(ctx, merchant.id, 30)
+			return err
+		})
+	}
+	if balance := balanceOf(t, stub, merchant.id); balance != 90 {
+		t.Fatalf("got merchant balance %d, want 90", balance)
+	}
+	if balance := balanceOf(t, stub, payers[0].id); balance != 70 {
+		t.Fatalf("got payer balance %d, want 70", balance)
+	}
+
+	// Spending consumes every output and leaves the change in one
+	err := invoke(stub, merchant, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.Transfer(ctx, admin.id, 91)
+		return err
+	})
+	assertCode(t, err, insufficientFundsCode)
+	mustInvoke(t, stub, merchant, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.Transfer(ctx, admin.id, 50)
+		return err
+	})
+	mustInvoke(t, stub, merchant, func(ctx contractapi.TransactionContextInterface) error {
+		keys, balance, err := readOutputs(ctx, merchant.id)
+		if len(keys) != 1 || balance != 40 {
+			t.Fatalf("got %d outputs worth %d, want 1 worth 40", len(keys), balance)
+		}
+		return err
+	})
+
+	mustInvoke(t, stub, payers[0], func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.Transfer(ctx, merchant.id, 10)
+		return err
+	})
+	mustInvoke(t, stub, merchant, func(ctx contractapi.TransactionContextInterface) error {
+		merged, err := contract.Consolidate(ctx)
+		if merged != 2 {
+			t.Fatalf("got %d outputs merged, want 2", merged)
+		}
+		return err
+	})
+	if balance := balanceOf(t, stub, merchant.id); balance != 50 {
+		t.Fatalf("got merchant balance %d after consolidating, want 50", balance)
+	}
+}
+
+func TestConcurrentTransfersToBusyRecipient(t *testing.T) {
+	contract := &SmartContract{}
+	admin := newIdentity("admin", "Org1MSP")
+	merchant := newIdentity("merchant", "Org2MSP")
+
+	pay := func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.Transfer(ctx, merchant.id, 1)
+		return err
+	}
+
+	// Every payment of the block reads the balance of the merchant, so only the first commits
+	payers := newPayers(10)
+	stub := newModelToken(t, accountModel, admin, payers)
+	if conflicts := endorseBlock(t, stub, payers, pay); conflicts != 9 {
+		t.Fatalf("got %d conflicts in the account model, want 9", conflicts)
+	}
+	if balance := balanceOf(t, stub, merchant.id); balance != 1 {
+		t.Fatalf("got merchant balance %d in the account model, want 1", balance)
+	}
+
+	stub = newModelToken(t, utxoModel, admin, payers)
+	if conflicts := endorseBlock(t, stub, payers, pay); conflicts != 0 {
+		t.Fatalf("got %d conflicts in the UTXO model, want none", conflicts)
+	}
+	if balance := balanceOf(t, stub, merchant.id); balance != 10 {
+		t.Fatalf("got merchant balance %d in the UTXO model, want 10", balance)
+	}
+}
+
+// BenchmarkConcurrentTransfers endorses blocks of payments from distinct payers to one merchant
+// and reports how many of them fail with MVCC_READ_CONFLICT in each balance model
+func BenchmarkConcurrentTransfers(b *testing.B) {
+	for _, model := range []string{accountModel, utxoModel} {
+		b.Run(model, func(b *testing.B) {
+			contract := &SmartContract{}
+			admin := newIdentity("admin", "Org1MSP")
+			merchant := newIdentity("merchant", "Org2MSP")
+			payers := newPayers(50)
+			stub := newModelToken(b, model, admin, payers)
+
+			pay := func(ctx contractapi.TransactionContextInterface) error {
+				_, err := contract.Transfer(ctx, merchant.id, 1)
+				return err
+			}
+
+			conflicts := 0
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				conflicts += endorseBlock(b, stub, payers[i%2*25:i%2*25+25], pay)
+			}
+			b.ReportMetric(float64(conflicts)/float64(b.N*25), "conflicts/tx")
+		})
+	}
+}
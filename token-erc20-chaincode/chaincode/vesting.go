@@ -0,0 +1,337 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+const (
+	vestingPrefix           = "vesting"
+	beneficiaryVestingIndex = "beneficiary~vesting"
+)
+
+// vestingEscrowAccount holds the tokens of every vesting schedule until they are released or refunded.
+// It is not an x509 identity, so no client can spend from it directly.
+const vestingEscrowAccount = "escrow::vesting"
+
+// VestingSchedule locks Total tokens of Funder and releases them to Beneficiary linearly over Duration
+// seconds from Start, nothing being released before Start+Cliff. Times are seconds since the Unix epoch.
+type VestingSchedule struct {
+	ID          string `json:"id"`
+	Beneficiary string `json:"beneficiary"`
+	Funder      string `json:"funder"`
+	Total       int    `json:"total"`
+	Released    int    `json:"released"`
+	Start       int64  `json:"start"`
+	Cliff       int64  `json:"cliff"`
+	Duration    int64  `json:"duration"`
+	Revocable   bool   `json:"revocable"`
+	Revoked     bool   `json:"revoked"`
+}
+
+// TokensReleased is emitted when vested tokens are paid to the beneficiary of a schedule
+type TokensReleased struct {
+	ScheduleID  string `json:"scheduleID"`
+	Beneficiary string `json:"beneficiary"`
+	Value       int    `json:"value"`
+}
+
+// VestingScheduleRevoked is emitted when a schedule is revoked and its unvested tokens refunded
+type VestingScheduleRevoked struct {
+	ScheduleID string `json:"scheduleID"`
+	Funder     string `json:"funder"`
+	Refunded   int    `json:"refunded"`
+}
+
+// vestedAmount returns how many tokens of schedule have vested at time now.
+// A revoked schedule keeps what had vested when it was revoked.
+func vestedAmount(schedule *VestingSchedule, now int64) int {
+	switch {
+	case schedule.Revoked:
+		return schedule.Total
+	case now < schedule.Start+schedule.Cliff:
+		return 0
+	case now >= schedule.Start+schedule.Duration:
+		return schedule.Total
+	}
+
+	// Total*elapsed may not fit in an int, so compute it with big integers
+	vested := new(big.Int).Mul(big.NewInt(int64(schedule.Total)), big.NewInt(now-schedule.Start))
+	vested.Quo(vested, big.NewInt(schedule.Duration))
+	return int(vested.Int64())
+}
+
+// vestingKey builds the key of the vesting schedule with the given ID
+func vestingKey(ctx contractapi.TransactionContextInterface, id string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(vestingPrefix, []string{id})
+	if err != nil {
+		return "", fmt.Errorf("failed to create vesting key: %v", err)
+	}
+
+	return key, nil
+}
+
+// readVestingSchedule returns the vesting schedule with the given ID
+func readVestingSchedule(ctx contractapi.TransactionContextInterface, id string) (*VestingSchedule, error) {
+	key, err := vestingKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduleJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if scheduleJSON == nil {
+		return nil, codedError(notFoundCode, "vesting schedule %s does not exist", id)
+	}
+
+	var schedule VestingSchedule
+	err = json.Unmarshal(scheduleJSON, &schedule)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal vesting schedule %s: %v", id, err)
+	}
+
+	return &schedule, nil
+}
+
+// putVestingSchedule writes schedule to the world state
+func putVestingSchedule(ctx contractapi.TransactionContextInterface, schedule *VestingSchedule) error {
+	key, err := vestingKey(ctx, schedule.ID)
+	if err != nil {
+		return err
+	}
+
+	scheduleJSON, err := json.Marshal(schedule)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	err = ctx.GetStub().PutState(key, scheduleJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put vesting schedule %s into world state: %v", schedule.ID, err)
+	}
+
+	return nil
+}
+
+// CreateVestingSchedule moves total tokens of the client into escrow and vests them to beneficiary
+// linearly over duration seconds from start, nothing vesting before start+cliff.
+// Only the admin organization may create vesting schedules.
+func (s *SmartContract) CreateVestingSchedule(ctx contractapi.TransactionContextInterface, id string, beneficiary string, total int, start int64, cliff int64, duration int64, revocable bool) error {
+	if err := checkInitialized(ctx); err != nil {
+		return err
+	}
+	if err := checkAdmin(ctx); err != nil {
+		return err
+	}
+	if id == "" || beneficiary == "" {
+		return codedError(invalidArgumentCode, "vesting schedule ID and beneficiary must not be empty")
+	}
+	if err := checkAmount(total); err != nil {
+		return err
+	}
+	if start < 0 || duration <= 0 || cliff < 0 || cliff > duration {
+		return codedError(invalidArgumentCode, "vesting schedule needs a non-negative start, a positive duration and a cliff within the duration")
+	}
+
+	key, err := vestingKey(ctx, id)
+	if err != nil {
+		return err
+	}
+	existing, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if existing != nil {
+		return codedError(invalidArgumentCode, "vesting schedule %s already exists", id)
+	}
+
+	funder, err := getClientAccountID(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = move(ctx, funder, vestingEscrowAccount, total)
+	if err != nil {
+		return err
+	}
+
+	schedule := &VestingSchedule{
+		ID:          id,
+		Beneficiary: beneficiary,
+		Funder:      funder,
+		Total:       total,
+		Start:       start,
+		Cliff:       cliff,
+		Duration:    duration,
+		Revocable:   revocable,
+	}
+	err = putVestingSchedule(ctx, schedule)
+	if err != nil {
+		return err
+	}
+
+	indexKey, err := ctx.GetStub().CreateCompositeKey(beneficiaryVestingIndex, []string{beneficiary, id})
+	if err != nil {
+		return fmt.Errorf("failed to create beneficiary index key: %v", err)
+	}
+	err = ctx.GetStub().PutState(indexKey, []byte{0x00})
+	if err != nil {
+		return fmt.Errorf("failed to put beneficiary index into world state: %v", err)
+	}
+
+	return emitEvent(ctx, "VestingScheduleCreated", schedule)
+}
+
+// ReadVestingSchedule returns the vesting schedule with the given ID
+func (s *SmartContract) ReadVestingSchedule(ctx contractapi.TransactionContextInterface, id string) (*VestingSchedule, error) {
+	if err := checkInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	return readVestingSchedule(ctx, id)
+}
+
+// GetVestingSchedules returns the vesting schedules of beneficiary
+func (s *SmartContract) GetVestingSchedules(ctx contractapi.TransactionContextInterface, beneficiary string) ([]*VestingSchedule, error) {
+	if err := checkInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(beneficiaryVestingIndex, []string{beneficiary})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read beneficiary index: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	schedules := []*VestingSchedule{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split beneficiary index key: %v", err)
+		}
+
+		schedule, err := readVestingSchedule(ctx, attributes[1])
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	return schedules, nil
+}
+
+// ReleasableAmount returns how many vested tokens of a schedule have not been released yet
+func (s *SmartContract) ReleasableAmount(ctx contractapi.TransactionContextInterface, id string) (int, error) {
+	if err := checkInitialized(ctx); err != nil {
+		return 0, err
+	}
+
+	schedule, err := readVestingSchedule(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return vestedAmount(schedule, now) - schedule.Released, nil
+}
+
+// Release pays the vested and not yet released tokens of a schedule to its beneficiary.
+// Tokens only ever go to the beneficiary, so any client may trigger the release.
+func (s *SmartContract) Release(ctx contractapi.TransactionContextInterface, id string) (int, error) {
+	if err := checkInitialized(ctx); err != nil {
+		return 0, err
+	}
+
+	schedule, err := readVestingSchedule(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	releasable := vestedAmount(schedule, now) - schedule.Released
+	if releasable <= 0 {
+		return 0, codedError(invalidArgumentCode, "vesting schedule %s has no tokens due for release", id)
+	}
+
+	err = move(ctx, vestingEscrowAccount, schedule.Beneficiary, releasable)
+	if err != nil {
+		return 0, err
+	}
+
+	schedule.Released += releasable
+	err = putVestingSchedule(ctx, schedule)
+	if err != nil {
+		return 0, err
+	}
+
+	err = emitEvent(ctx, "TokensReleased", TokensReleased{ScheduleID: id, Beneficiary: schedule.Beneficiary, Value: releasable})
+	if err != nil {
+		return 0, err
+	}
+
+	return releasable, nil
+}
+
+// RevokeVestingSchedule stops a revocable schedule, refunding its unvested tokens to the funder.
+// Tokens vested so far stay releasable to the beneficiary.
+// Only the admin organization may revoke vesting schedules.
+func (s *SmartContract) RevokeVestingSchedule(ctx contractapi.TransactionContextInterface, id string) error {
+	if err := checkInitialized(ctx); err != nil {
+		return err
+	}
+	if err := checkAdmin(ctx); err != nil {
+		return err
+	}
+
+	schedule, err := readVestingSchedule(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !schedule.Revocable {
+		return codedError(invalidArgumentCode, "vesting schedule %s is not revocable", id)
+	}
+	if schedule.Revoked {
+		return codedError(invalidArgumentCode, "vesting schedule %s is already revoked", id)
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	vested := vestedAmount(schedule, now)
+	refunded := schedule.Total - vested
+	if refunded > 0 {
+		err = move(ctx, vestingEscrowAccount, schedule.Funder, refunded)
+		if err != nil {
+			return err
+		}
+	}
+
+	schedule.Total = vested
+	schedule.Revoked = true
+	err = putVestingSchedule(ctx, schedule)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, "VestingScheduleRevoked", VestingScheduleRevoked{ScheduleID: id, Funder: schedule.Funder, Refunded: refunded})
+}
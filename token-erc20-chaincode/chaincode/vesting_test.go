@@ -0,0 +1,111 @@
+package chaincode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+func TestVestedAmount(t *testing.T) {
+	schedule := &VestingSchedule{Total: 1000, Start: 100, Cliff: 25, Duration: 100}
+
+	tests := []struct {
+		now    int64
+		vested int
+	}{
+		{now: 50, vested: 0},
+		{now: 124, vested: 0},
+		{now: 125, vested: 250},
+		{now: 150, vested: 500},
+		{now: 200, vested: 1000},
+		{now: 1000, vested: 1000},
+	}
+	for _, test := range tests {
+		if vested := vestedAmount(schedule, test.now); vested != test.vested {
+			t.Fatalf("at %d got %d vested, want %d", test.now, vested, test.vested)
+		}
+	}
+}
+
+func TestVesting(t *testing.T) {
+	contract := &SmartContract{}
+	admin := newIdentity("admin", "Org1MSP")
+	bob := newIdentity("bob", "Org2MSP")
+	stub := newToken(t, admin, 1000)
+	start := stub.timestamp.Unix()
+
+	err := invoke(stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.CreateVestingSchedule(ctx, "v1", bob.id, 100, start, 0, 100, true)
+	})
+	assertCode(t, err, unauthorizedCode)
+
+	err = invoke(stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.CreateVestingSchedule(ctx, "v1", bob.id, 2000, start, 0, 100, true)
+	})
+	assertCode(t, err, insufficientFundsCode)
+
+	err = invoke(stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.CreateVestingSchedule(ctx, "v1", bob.id, 400, start, 200, 100, true)
+	})
+	assertCode(t, err, invalidArgumentCode)
+
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.CreateVestingSchedule(ctx, "v1", bob.id, 400, start, 10, 100, true)
+	})
+	if balance := balanceOf(t, stub, vestingEscrowAccount); balance != 400 {
+		t.Fatalf("got escrow balance %d, want 400", balance)
+	}
+
+	err = invoke(stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.Release(ctx, "v1")
+		return err
+	})
+	assertCode(t, err, invalidArgumentCode)
+
+	stub.timestamp = stub.timestamp.Add(25 * time.Second)
+	mustInvoke(t, stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		released, err := contract.Release(ctx, "v1")
+		if released != 100 {
+			t.Fatalf("released %d tokens, want 100", released)
+		}
+		return err
+	})
+
+	stub.timestamp = stub.timestamp.Add(25 * time.Second)
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.RevokeVestingSchedule(ctx, "v1")
+	})
+	if balance := balanceOf(t, stub, admin.id); balance != 800 {
+		t.Fatalf("got admin balance %d after the refund, want 800", balance)
+	}
+
+	// What vested before the revocation stays releasable, and nothing more vests after it
+	stub.timestamp = stub.timestamp.Add(time.Hour)
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		released, err := contract.Release(ctx, "v1")
+		if released != 100 {
+			t.Fatalf("released %d tokens, want 100", released)
+		}
+		return err
+	})
+	if balance := balanceOf(t, stub, bob.id); balance != 200 {
+		t.Fatalf("got bob balance %d, want 200", balance)
+	}
+	if balance := balanceOf(t, stub, vestingEscrowAccount); balance != 0 {
+		t.Fatalf("got escrow balance %d, want 0", balance)
+	}
+
+	err = invoke(stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.RevokeVestingSchedule(ctx, "v1")
+	})
+	assertCode(t, err, invalidArgumentCode)
+
+	mustInvoke(t, stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		schedules, err := contract.GetVestingSchedules(ctx, bob.id)
+		if len(schedules) != 1 || schedules[0].Released != 200 || !schedules[0].Revoked {
+			t.Fatalf("got schedules %+v, want v1 revoked with 200 released", schedules)
+		}
+		return err
+	})
+}
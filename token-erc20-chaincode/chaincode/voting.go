@@ -0,0 +1,647 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+const (
+	votingConfigPrefix = "votingconfig"
+	powerPrefix        = "power"
+	delegatePrefix     = "delegate"
+	proposalPrefix     = "proposal"
+	votePrefix         = "vote"
+)
+
+// maxBasisPoints is 100%, in basis points
+const maxBasisPoints = 10000
+
+// Statuses of a proposal
+const (
+	proposalActive   = "active"
+	proposalPassed   = "passed"
+	proposalDefeated = "defeated"
+)
+
+// Vote choices
+const (
+	voteFor     = "for"
+	voteAgainst = "against"
+	voteAbstain = "abstain"
+)
+
+// VotingConfig rules token holder proposals: a proposal is open for Period seconds and passes when
+// the weight of its votes reaches Quorum basis points of the supply and more than Majority basis
+// points of the for and against votes are for it.
+type VotingConfig struct {
+	Quorum   int   `json:"quorum"`
+	Majority int   `json:"majority"`
+	Period   int64 `json:"period"`
+}
+
+// Proposal is put to the vote of token holders from its creation, at Snapshot, until End, in seconds
+// since the Unix epoch. Votes weigh the voting power of the voter at Snapshot: the balances of the
+// accounts delegating to it, its own included unless it delegated it.
+type Proposal struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	Proposer    string `json:"proposer"`
+	Snapshot    int64  `json:"snapshot"`
+	End         int64  `json:"end"`
+	Supply      int    `json:"supply"`
+	Quorum      int    `json:"quorum"`
+	Majority    int    `json:"majority"`
+	For         int    `json:"for"`
+	Against     int    `json:"against"`
+	Abstain     int    `json:"abstain"`
+	Status      string `json:"status"`
+}
+
+// Vote is the vote of Voter on a proposal, weighing its voting power at the snapshot of the proposal
+type Vote struct {
+	Proposal string `json:"proposal"`
+	Voter    string `json:"voter"`
+	Support  string `json:"support"`
+	Weight   int    `json:"weight"`
+}
+
+// Delegation is emitted when an account delegates its voting power
+type Delegation struct {
+	Delegator string `json:"delegator"`
+	Delegatee string `json:"delegatee"`
+}
+
+// votingConfigKey builds the key of the voting configuration
+func votingConfigKey(ctx contractapi.TransactionContextInterface) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(votingConfigPrefix, []string{})
+	if err != nil {
+		return "", fmt.Errorf("failed to create voting config key: %v", err)
+	}
+
+	return key, nil
+}
+
+// readVotingConfig returns the voting configuration, nil until voting is enabled
+func readVotingConfig(ctx contractapi.TransactionContextInterface) (*VotingConfig, error) {
+	key, err := votingConfigKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	configJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if configJSON == nil {
+		return nil, nil
+	}
+
+	var config VotingConfig
+	err = json.Unmarshal(configJSON, &config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal voting config: %v", err)
+	}
+
+	return &config, nil
+}
+
+// delegateOf returns the account account delegates its voting power to, itself unless it delegated it
+func delegateOf(ctx contractapi.TransactionContextInterface, account string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(delegatePrefix, []string{account})
+	if err != nil {
+		return "", fmt.Errorf("failed to create delegate key: %v", err)
+	}
+
+	delegateBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if delegateBytes == nil {
+		return account, nil
+	}
+
+	return string(delegateBytes), nil
+}
+
+// writePower records a change of delta of the voting power of delegate at the time of the transaction.
+// Voting power is kept as checkpoints ("power", delegate, time, txID, n) that are written without being read,
+// like the outputs of the UTXO model, so that credits to busy accounts still do not conflict.
+func writePower(ctx contractapi.TransactionContextInterface, delegate string, delta int) error {
+	txCtx, ok := ctx.(*TransactionContext)
+	if !ok {
+		return fmt.Errorf("voting requires the transaction context of the token chaincode")
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	n := strconv.Itoa(txCtx.checkpoints)
+	txCtx.checkpoints++
+
+	// Times are zero padded so that the checkpoints of a delegate are in chronological order
+	key, err := ctx.GetStub().CreateCompositeKey(powerPrefix, []string{delegate, fmt.Sprintf("%020d", now), ctx.GetStub().GetTxID(), n})
+	if err != nil {
+		return fmt.Errorf("failed to create power key: %v", err)
+	}
+
+	err = ctx.GetStub().PutState(key, []byte(strconv.Itoa(delta)))
+	if err != nil {
+		return fmt.Errorf("failed to put voting power into world state: %v", err)
+	}
+
+	return nil
+}
+
+// trackPower moves the voting power of a change of delta of the balance of account to its delegate,
+// once voting is enabled
+func trackPower(ctx contractapi.TransactionContextInterface, account string, delta int) error {
+	config, err := readVotingConfig(ctx)
+	if err != nil || config == nil {
+		return err
+	}
+
+	delegate, err := delegateOf(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	return writePower(ctx, delegate, delta)
+}
+
+// powerAt returns the voting power of delegate before time, in seconds since the Unix epoch
+func powerAt(ctx contractapi.TransactionContextInterface, delegate string, time int64) (int, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(powerPrefix, []string{delegate})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get voting power of %s: %v", delegate, err)
+	}
+	defer resultsIterator.Close()
+
+	power := 0
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return 0, err
+		}
+
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return 0, fmt.Errorf("failed to split power key: %v", err)
+		}
+		checkpoint, err := strconv.ParseInt(attributes[1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse time of %s: %v", queryResponse.Key, err)
+		}
+		if checkpoint >= time {
+			break
+		}
+
+		delta, err := strconv.Atoi(string(queryResponse.Value))
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse voting power %s: %v", queryResponse.Key, err)
+		}
+		power += delta
+	}
+
+	return power, nil
+}
+
+// proposalKey builds the key of the proposal with the given ID
+func proposalKey(ctx contractapi.TransactionContextInterface, id string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(proposalPrefix, []string{id})
+	if err != nil {
+		return "", fmt.Errorf("failed to create proposal key: %v", err)
+	}
+
+	return key, nil
+}
+
+// readProposal returns the proposal with the given ID
+func readProposal(ctx contractapi.TransactionContextInterface, id string) (*Proposal, error) {
+	key, err := proposalKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	proposalJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if proposalJSON == nil {
+		return nil, codedError(notFoundCode, "proposal %s does not exist", id)
+	}
+
+	var proposal Proposal
+	err = json.Unmarshal(proposalJSON, &proposal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal proposal %s: %v", id, err)
+	}
+
+	return &proposal, nil
+}
+
+// putProposal writes proposal to the world state
+func putProposal(ctx contractapi.TransactionContextInterface, proposal *Proposal) error {
+	key, err := proposalKey(ctx, proposal.ID)
+	if err != nil {
+		return err
+	}
+
+	proposalJSON, err := json.Marshal(proposal)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	err = ctx.GetStub().PutState(key, proposalJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put proposal %s into world state: %v", proposal.ID, err)
+	}
+
+	return nil
+}
+
+// EnableVoting sets the rules of token holder proposals: quorum and majority in basis points and the voting
+// period in seconds. The first call takes the balances of the holders as their initial voting power, and
+// from then on every balance change is checkpointed. Only the admin organization may set the rules.
+func (s *SmartContract) EnableVoting(ctx contractapi.TransactionContextInterface, quorum int, majority int, period int64) error {
+	if err := checkInitialized(ctx); err != nil {
+		return err
+	}
+	if err := checkAdmin(ctx); err != nil {
+		return err
+	}
+	if quorum < 0 || quorum > maxBasisPoints || majority < 0 || majority >= maxBasisPoints {
+		return codedError(invalidArgumentCode, "quorum must be between 0 and %d basis points and majority below %d", maxBasisPoints, maxBasisPoints)
+	}
+	if period <= 0 {
+		return codedError(invalidArgumentCode, "voting period must be a positive number of seconds")
+	}
+
+	existing, err := readVotingConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		// Tokens received before voting was enabled count from now on
+		resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(holderPrefix, []string{})
+		if err != nil {
+			return fmt.Errorf("failed to read holder index: %v", err)
+		}
+		defer resultsIterator.Close()
+
+		for resultsIterator.HasNext() {
+			queryResponse, err := resultsIterator.Next()
+			if err != nil {
+				return err
+			}
+
+			_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+			if err != nil {
+				return fmt.Errorf("failed to split holder index key: %v", err)
+			}
+			balance, err := readBalance(ctx, attributes[0])
+			if err != nil {
+				return err
+			}
+			delegate, err := delegateOf(ctx, attributes[0])
+			if err != nil {
+				return err
+			}
+			err = writePower(ctx, delegate, balance)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	config := VotingConfig{Quorum: quorum, Majority: majority, Period: period}
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	key, err := votingConfigKey(ctx)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(key, configJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put voting config into world state: %v", err)
+	}
+
+	return emitEvent(ctx, "VotingConfigChanged", config)
+}
+
+// Delegate gives the voting power of the balance of the client to delegatee, the client itself to take it back.
+// Proposals created before keep the voting power of their snapshot.
+func (s *SmartContract) Delegate(ctx contractapi.TransactionContextInterface, delegatee string) error {
+	if err := checkInitialized(ctx); err != nil {
+		return err
+	}
+	if delegatee == "" {
+		return codedError(invalidArgumentCode, "delegatee must not be empty")
+	}
+
+	delegator, err := getClientAccountID(ctx)
+	if err != nil {
+		return err
+	}
+	current, err := delegateOf(ctx, delegator)
+	if err != nil {
+		return err
+	}
+	if current == delegatee {
+		return codedError(invalidArgumentCode, "voting power is already delegated to %s", delegatee)
+	}
+
+	config, err := readVotingConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if config != nil {
+		balance, err := readBalance(ctx, delegator)
+		if err != nil {
+			return err
+		}
+		if balance > 0 {
+			err = writePower(ctx, current, -balance)
+			if err != nil {
+				return err
+			}
+			err = writePower(ctx, delegatee, balance)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(delegatePrefix, []string{delegator})
+	if err != nil {
+		return fmt.Errorf("failed to create delegate key: %v", err)
+	}
+	if delegatee == delegator {
+		err = ctx.GetStub().DelState(key)
+	} else {
+		err = ctx.GetStub().PutState(key, []byte(delegatee))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update delegate of %s: %v", delegator, err)
+	}
+
+	return emitEvent(ctx, "DelegateChanged", Delegation{Delegator: delegator, Delegatee: delegatee})
+}
+
+// CreateProposal puts a proposal to the vote of token holders for the voting period, weighing votes with
+// the voting power at the time of the transaction. Only holders of tokens may create proposals.
+func (s *SmartContract) CreateProposal(ctx contractapi.TransactionContextInterface, id string, description string) error {
+	if err := checkInitialized(ctx); err != nil {
+		return err
+	}
+	if id == "" || description == "" {
+		return codedError(invalidArgumentCode, "proposal ID and description must not be empty")
+	}
+
+	config, err := readVotingConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if config == nil {
+		return codedError(invalidArgumentCode, "voting is not enabled")
+	}
+
+	proposer, err := getClientAccountID(ctx)
+	if err != nil {
+		return err
+	}
+	balance, err := readBalance(ctx, proposer)
+	if err != nil {
+		return err
+	}
+	if balance == 0 {
+		return codedError(unauthorizedCode, "only token holders may create proposals")
+	}
+
+	key, err := proposalKey(ctx, id)
+	if err != nil {
+		return err
+	}
+	existing, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if existing != nil {
+		return codedError(invalidArgumentCode, "proposal %s already exists", id)
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+	supply, err := readInt(ctx, totalSupplyKey)
+	if err != nil {
+		return err
+	}
+
+	proposal := &Proposal{
+		ID:          id,
+		Description: description,
+		Proposer:    proposer,
+		Snapshot:    now,
+		End:         now + config.Period,
+		Supply:      supply,
+		Quorum:      config.Quorum,
+		Majority:    config.Majority,
+		Status:      proposalActive,
+	}
+	err = putProposal(ctx, proposal)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, "ProposalCreated", proposal)
+}
+
+// CastVote votes for, against or abstain on a proposal with the voting power of the client at its snapshot.
+// Each account votes once, before the end of the proposal.
+func (s *SmartContract) CastVote(ctx contractapi.TransactionContextInterface, id string, support string) error {
+	if err := checkInitialized(ctx); err != nil {
+		return err
+	}
+	if support != voteFor && support != voteAgainst && support != voteAbstain {
+		return codedError(invalidArgumentCode, "vote must be %s, %s or %s", voteFor, voteAgainst, voteAbstain)
+	}
+
+	proposal, err := readProposal(ctx, id)
+	if err != nil {
+		return err
+	}
+	now, err := txTime(ctx)
+	if err != nil {
+		return err
+	}
+	if now >= proposal.End {
+		return codedError(invalidArgumentCode, "voting on proposal %s has ended", id)
+	}
+
+	voter, err := getClientAccountID(ctx)
+	if err != nil {
+		return err
+	}
+	key, err := ctx.GetStub().CreateCompositeKey(votePrefix, []string{id, voter})
+	if err != nil {
+		return fmt.Errorf("failed to create vote key: %v", err)
+	}
+	existing, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if existing != nil {
+		return codedError(invalidArgumentCode, "client already voted on proposal %s", id)
+	}
+
+	weight, err := powerAt(ctx, voter, proposal.Snapshot)
+	if err != nil {
+		return err
+	}
+	if weight <= 0 {
+		return codedError(unauthorizedCode, "client had no voting power at the snapshot of proposal %s", id)
+	}
+
+	// Votes are separate keys tallied at the end, so that voters do not conflict on the proposal
+	vote := Vote{Proposal: id, Voter: voter, Support: support, Weight: weight}
+	voteJSON, err := json.Marshal(vote)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+	err = ctx.GetStub().PutState(key, voteJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put vote into world state: %v", err)
+	}
+
+	return emitEvent(ctx, "VoteCast", vote)
+}
+
+// tally counts the votes of proposal and decides it
+func tally(ctx contractapi.TransactionContextInterface, proposal *Proposal) error {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(votePrefix, []string{proposal.ID})
+	if err != nil {
+		return fmt.Errorf("failed to get votes of proposal %s: %v", proposal.ID, err)
+	}
+	defer resultsIterator.Close()
+
+	proposal.For, proposal.Against, proposal.Abstain = 0, 0, 0
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return err
+		}
+
+		var vote Vote
+		err = json.Unmarshal(queryResponse.Value, &vote)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal vote: %v", err)
+		}
+		switch vote.Support {
+		case voteFor:
+			proposal.For += vote.Weight
+		case voteAgainst:
+			proposal.Against += vote.Weight
+		case voteAbstain:
+			proposal.Abstain += vote.Weight
+		}
+	}
+
+	// Divide rather than multiply by basis points, which could overflow
+	votes := proposal.For + proposal.Against + proposal.Abstain
+	quorum := float64(votes)*maxBasisPoints >= float64(proposal.Supply)*float64(proposal.Quorum)
+	majority := float64(proposal.For)*maxBasisPoints > float64(proposal.For+proposal.Against)*float64(proposal.Majority)
+	if quorum && majority {
+		proposal.Status = proposalPassed
+	} else {
+		proposal.Status = proposalDefeated
+	}
+
+	return nil
+}
+
+// TallyProposal counts the votes of a proposal once its voting period has ended and records whether it passed:
+// it needs votes of quorum basis points of the supply and more than majority basis points of the for and
+// against votes for it
+func (s *SmartContract) TallyProposal(ctx contractapi.TransactionContextInterface, id string) (*Proposal, error) {
+	if err := checkInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	proposal, err := readProposal(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if proposal.Status != proposalActive {
+		return nil, codedError(invalidArgumentCode, "proposal %s is already %s", id, proposal.Status)
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if now < proposal.End {
+		return nil, codedError(invalidArgumentCode, "voting on proposal %s has not ended", id)
+	}
+
+	err = tally(ctx, proposal)
+	if err != nil {
+		return nil, err
+	}
+	err = putProposal(ctx, proposal)
+	if err != nil {
+		return nil, err
+	}
+
+	err = emitEvent(ctx, "ProposalTallied", proposal)
+	if err != nil {
+		return nil, err
+	}
+
+	return proposal, nil
+}
+
+// ReadProposal returns a proposal, with the votes counted so far while it is active
+func (s *SmartContract) ReadProposal(ctx contractapi.TransactionContextInterface, id string) (*Proposal, error) {
+	if err := checkInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	proposal, err := readProposal(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if proposal.Status != proposalActive {
+		return proposal, nil
+	}
+
+	err = tally(ctx, proposal)
+	if err != nil {
+		return nil, err
+	}
+	proposal.Status = proposalActive
+
+	return proposal, nil
+}
+
+// GetVotingPower returns the current voting power of account
+func (s *SmartContract) GetVotingPower(ctx contractapi.TransactionContextInterface, account string) (int, error) {
+	if err := checkInitialized(ctx); err != nil {
+		return 0, err
+	}
+
+	now, err := txTime(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return powerAt(ctx, account, now+1)
+}
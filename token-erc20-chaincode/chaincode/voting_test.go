@@ -0,0 +1,153 @@
+package chaincode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// votingPower returns the current voting power of account
+func votingPower(t *testing.T, stub *mockStub, account string) int {
+	t.Helper()
+
+	contract := &SmartContract{}
+	var power int
+	mustInvoke(t, stub, newIdentity("reader", "Org2MSP"), func(ctx contractapi.TransactionContextInterface) error {
+		var err error
+		power, err = contract.GetVotingPower(ctx, account)
+		return err
+	})
+	return power
+}
+
+func TestVoting(t *testing.T) {
+	contract := &SmartContract{}
+	admin := newIdentity("admin", "Org1MSP")
+	alice := newIdentity("alice", "Org2MSP")
+	bob := newIdentity("bob", "Org2MSP")
+	carol := newIdentity("carol", "Org2MSP")
+	stub := newToken(t, admin, 1000)
+
+	// Balances received before voting is enabled count as initial voting power
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.Transfer(ctx, alice.id, 300)
+		return err
+	})
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.EnableVoting(ctx, 4000, 5000, 3600)
+	})
+	if power := votingPower(t, stub, alice.id); power != 300 {
+		t.Fatalf("got voting power %d, want 300", power)
+	}
+
+	stub.timestamp = stub.timestamp.Add(time.Minute)
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.Transfer(ctx, bob.id, 200)
+		return err
+	})
+	mustInvoke(t, stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.Delegate(ctx, carol.id)
+	})
+	if power := votingPower(t, stub, carol.id); power != 200 {
+		t.Fatalf("got delegated voting power %d, want 200", power)
+	}
+
+	err := invoke(stub, carol, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.CreateProposal(ctx, "p1", "Raise the fee")
+	})
+	assertCode(t, err, unauthorizedCode)
+
+	stub.timestamp = stub.timestamp.Add(time.Minute)
+	mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.CreateProposal(ctx, "p1", "Raise the fee")
+	})
+
+	// Tokens moved after the snapshot do not change the weight of votes
+	stub.timestamp = stub.timestamp.Add(time.Minute)
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.Transfer(ctx, carol.id, 500)
+		return err
+	})
+
+	vote := func(identity *mockIdentity, support string) error {
+		return invoke(stub, identity, func(ctx contractapi.TransactionContextInterface) error {
+			return contract.CastVote(ctx, "p1", support)
+		})
+	}
+	assertCode(t, vote(bob, voteFor), unauthorizedCode)
+	assertCode(t, vote(alice, "maybe"), invalidArgumentCode)
+	if err := vote(alice, voteAgainst); err != nil {
+		t.Fatalf("CastVote failed: %v", err)
+	}
+	assertCode(t, vote(alice, voteFor), invalidArgumentCode)
+	if err := vote(carol, voteFor); err != nil {
+		t.Fatalf("CastVote failed: %v", err)
+	}
+
+	tallyProposal := func() (*Proposal, error) {
+		var proposal *Proposal
+		err := invoke(stub, bob, func(ctx contractapi.TransactionContextInterface) error {
+			var err error
+			proposal, err = contract.TallyProposal(ctx, "p1")
+			return err
+		})
+		return proposal, err
+	}
+	_, err = tallyProposal()
+	assertCode(t, err, invalidArgumentCode)
+
+	stub.timestamp = stub.timestamp.Add(time.Hour)
+	assertCode(t, vote(admin, voteFor), invalidArgumentCode)
+
+	// 500 of the 1000 tokens voted, over the 40% quorum, but only 200 of them for the proposal
+	proposal, err := tallyProposal()
+	if err != nil {
+		t.Fatalf("TallyProposal failed: %v", err)
+	}
+	if proposal.For != 200 || proposal.Against != 300 || proposal.Status != proposalDefeated {
+		t.Fatalf("got proposal %+v, want 200 for, 300 against and defeated", proposal)
+	}
+	_, err = tallyProposal()
+	assertCode(t, err, invalidArgumentCode)
+}
+
+func TestVotingQuorum(t *testing.T) {
+	contract := &SmartContract{}
+	admin := newIdentity("admin", "Org1MSP")
+	alice := newIdentity("alice", "Org2MSP")
+	stub := newToken(t, admin, 1000)
+
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.EnableVoting(ctx, 4000, 5000, 3600)
+	})
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := contract.Transfer(ctx, alice.id, 300)
+		return err
+	})
+
+	// Only the 700 tokens of the admin reach the 40% quorum, not the 300 of alice
+	stub.timestamp = stub.timestamp.Add(time.Minute)
+	for _, id := range []string{"p1", "p2"} {
+		mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+			return contract.CreateProposal(ctx, id, "Proposal "+id)
+		})
+	}
+	mustInvoke(t, stub, admin, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.CastVote(ctx, "p1", voteFor)
+	})
+	mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+		return contract.CastVote(ctx, "p2", voteFor)
+	})
+
+	stub.timestamp = stub.timestamp.Add(time.Hour)
+	for id, status := range map[string]string{"p1": proposalPassed, "p2": proposalDefeated} {
+		mustInvoke(t, stub, alice, func(ctx contractapi.TransactionContextInterface) error {
+			proposal, err := contract.TallyProposal(ctx, id)
+			if err == nil && proposal.Status != status {
+				t.Fatalf("got proposal %s %s, want %s", id, proposal.Status, status)
+			}
+			return err
+		})
+	}
+}
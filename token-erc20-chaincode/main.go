@@ -0,0 +1,23 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"log"
+
+	"chilley.net/chaincode"
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+func main() {
+	tokenChaincode, err := contractapi.NewChaincode(&chaincode.SmartContract{})
+	if err != nil {
+		log.Panicf("Error creating token-erc-20 chaincode: %v", err)
+	}
+
+	if err := tokenChaincode.Start(); err != nil {
+		log.Panicf("Error starting token-erc-20 chaincode: %v", err)
+	}
+}